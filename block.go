@@ -6,14 +6,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sync"
 
 	"github.com/thedadams/gotion/notion"
 )
 
+const defaultTreeWorkers = 3
+
 // GetBlock gets a block with the given id from the Notion API.
 func (c *Client) GetBlock(ctx context.Context, id string) (*notion.Block, error) {
 	block := &notion.Block{}
-	err := c.makeRequest(ctx, http.MethodGet, fmt.Sprintf("%s/v1/blocks/%s", apiBaseURL, id), nil, block)
+	err := c.makeRequest(ctx, http.MethodGet, fmt.Sprintf("%s/v1/blocks/%s", c.baseURL, id), nil, block)
 	if err != nil {
 		block = nil
 	}
@@ -23,21 +26,21 @@ func (c *Client) GetBlock(ctx context.Context, id string) (*notion.Block, error)
 
 // DeleteBlock deletes a block with the given id from the Notion API.
 func (c *Client) DeleteBlock(ctx context.Context, id string) error {
-	return c.makeRequest(ctx, http.MethodDelete, fmt.Sprintf("%s/v1/blocks/%s", apiBaseURL, id), nil, nil)
+	return c.makeRequest(ctx, http.MethodDelete, fmt.Sprintf("%s/v1/blocks/%s", c.baseURL, id), nil, nil)
 }
 
 // UpdateBlock updates a block in the Notion API.
 // On success, the block will be the complete block from the Notion API.
 // On error, the block will not be changed.
 func (c *Client) UpdateBlock(ctx context.Context, block *notion.Block) error {
-	return c.updateObject(ctx, fmt.Sprintf("%s/v1/blocks/%s", apiBaseURL, block.ID), block, block)
+	return c.updateObject(ctx, fmt.Sprintf("%s/v1/blocks/%s", c.baseURL, block.ID.String()), block, block)
 }
 
 // GetBlockChildren gets the children of the block with the given id from the Notion API.
 // If `maxResults < 0`, then this will get all the children of the block.
 func (c *Client) GetBlockChildren(ctx context.Context, id string, cursor *string, maxResults int) ([]*notion.Block, error) {
 	var results notion.Blocks
-	if err := c.getList(ctx, fmt.Sprintf("%s/v1/blocks/%s/children", apiBaseURL, id), cursor, maxResults, &results); err != nil {
+	if err := c.getList(ctx, fmt.Sprintf("%s/v1/blocks/%s/children", c.baseURL, id), cursor, maxResults, &results); err != nil {
 		return nil, err
 	}
 
@@ -55,7 +58,7 @@ func (c *Client) AppendBlockChildren(ctx context.Context, block *notion.Block) (
 		return block, err
 	}
 
-	err = c.makeRequest(ctx, http.MethodPatch, fmt.Sprintf("%s/v1/blocks/%s/children", apiBaseURL, block.ID), bytes.NewReader(bodyBytes), nil)
+	err = c.makeRequest(ctx, http.MethodPatch, fmt.Sprintf("%s/v1/blocks/%s/children", c.baseURL, block.ID.String()), bytes.NewReader(bodyBytes), nil)
 	if err != nil {
 		return block, err
 	}
@@ -63,3 +66,82 @@ func (c *Client) AppendBlockChildren(ctx context.Context, block *notion.Block) (
 	block.Children, err = c.GetBlockChildren(ctx, block.ID.String(), nil, -1)
 	return block, err
 }
+
+// GetBlockTree gets the children of the block with the given id from the Notion API,
+// and then recursively gets the children of any of those blocks that have children.
+// If `maxDepth < 0`, then the whole tree is retrieved. Otherwise, only `maxDepth` levels of children are retrieved.
+// At most `workers` requests are made concurrently; if `workers <= 0`, then a default is used.
+// All requests share the client's rate limiter.
+// Child pages are not descended into, since they are pages in their own right.
+// The first error encountered cancels any outstanding requests and is returned.
+func (c *Client) GetBlockTree(ctx context.Context, id string, maxDepth, workers int) ([]*notion.Block, error) {
+	if maxDepth == 0 {
+		return nil, nil
+	}
+	if workers <= 0 {
+		workers = defaultTreeWorkers
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	tw := &treeWalker{client: c, maxDepth: maxDepth, sem: make(chan struct{}, workers), cancel: cancel}
+	root := &notion.Block{HasChildren: true}
+	tw.walk(ctx, id, root, 1)
+	tw.wg.Wait()
+
+	if tw.err != nil {
+		return nil, tw.err
+	}
+	return root.Children, nil
+}
+
+// treeWalker gets the children of blocks concurrently, limiting the number of requests in flight.
+type treeWalker struct {
+	client   *Client
+	maxDepth int
+	sem      chan struct{}
+	wg       sync.WaitGroup
+	errOnce  sync.Once
+	err      error
+	cancel   context.CancelFunc
+}
+
+// walk gets the children of the block with the given id, stores them on parent,
+// and then walks the children that have children of their own.
+func (tw *treeWalker) walk(ctx context.Context, id string, parent *notion.Block, depth int) {
+	tw.wg.Add(1)
+	go func() {
+		defer tw.wg.Done()
+
+		select {
+		case tw.sem <- struct{}{}:
+		case <-ctx.Done():
+			tw.fail(ctx.Err())
+			return
+		}
+		children, err := tw.client.GetBlockChildren(ctx, id, nil, -1)
+		<-tw.sem
+		if err != nil {
+			tw.fail(err)
+			return
+		}
+
+		parent.Children = children
+		if tw.maxDepth > 0 && depth >= tw.maxDepth {
+			return
+		}
+		for _, child := range children {
+			if child.HasChildren && child.Type != notion.BlockTypeEnumChildPage {
+				tw.walk(ctx, child.ID.String(), child, depth+1)
+			}
+		}
+	}()
+}
+
+func (tw *treeWalker) fail(err error) {
+	tw.errOnce.Do(func() {
+		tw.err = err
+		tw.cancel()
+	})
+}
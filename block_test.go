@@ -0,0 +1,156 @@
+package gotion_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/thedadams/gotion"
+	"github.com/thedadams/gotion/gotiontest"
+	"github.com/thedadams/gotion/notion"
+	"github.com/thedadams/gotion/richtext"
+)
+
+func paragraph(text string) *notion.Block {
+	return &notion.Block{Type: notion.BlockTypeEnumParagraph, Text: richtext.FromPlainText(text)}
+}
+
+func toggle(text string, children ...*notion.Block) *notion.Block {
+	return &notion.Block{Type: notion.BlockTypeEnumToggle, Text: richtext.FromPlainText(text), Children: children}
+}
+
+// addTree adds a page with three levels of toggles, and a child page with blocks of its own, to the server.
+func addTree(t *testing.T, s *gotiontest.Server) string {
+	t.Helper()
+	page, err := s.AddPage(&notion.Page{Parent: notion.Parent{Type: notion.ParentTypeEnumWorkspace}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	title := "Child"
+	err = s.AddBlocks(page.ID.String(),
+		toggle("1", toggle("1.1", paragraph("1.1.1")), paragraph("1.2")),
+		toggle("2", toggle("2.1", toggle("2.1.1", paragraph("2.1.1.1")))),
+		&notion.Block{Type: notion.BlockTypeEnumChildPage, Title: &title, Children: []*notion.Block{paragraph("inside the child page")}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return page.ID.String()
+}
+
+// outline returns the text of the blocks, with the children of each block in parentheses after it.
+func outline(blocks []*notion.Block) string {
+	parts := make([]string, 0, len(blocks))
+	for _, b := range blocks {
+		part := richtext.PlainText(b.Text)
+		if b.Type == notion.BlockTypeEnumChildPage {
+			part = "page"
+		}
+		if len(b.Children) != 0 {
+			part += "(" + outline(b.Children) + ")"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestGetBlockTree(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxDepth int
+		workers  int
+		want     string
+	}{
+		{name: "no levels", maxDepth: 0, want: ""},
+		{name: "one level", maxDepth: 1, want: "1 2 page"},
+		{name: "two levels", maxDepth: 2, want: "1(1.1 1.2) 2(2.1) page"},
+		{name: "whole tree", maxDepth: -1, want: "1(1.1(1.1.1) 1.2) 2(2.1(2.1.1(2.1.1.1))) page"},
+		{name: "one worker", maxDepth: -1, workers: 1, want: "1(1.1(1.1.1) 1.2) 2(2.1(2.1.1(2.1.1.1))) page"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := gotiontest.NewServer()
+			defer s.Close()
+			id := addTree(t, s)
+
+			blocks, err := s.NewClient().GetBlockTree(context.Background(), id, tt.maxDepth, tt.workers)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := outline(blocks); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// failingTransport responds to requests for the children of one block with an error, and counts the requests.
+type failingTransport struct {
+	mu       sync.Mutex
+	failID   string
+	requests int
+}
+
+func (ft *failingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ft.mu.Lock()
+	ft.requests++
+	ft.mu.Unlock()
+
+	if !strings.Contains(req.URL.Path, ft.failID) {
+		return http.DefaultTransport.RoundTrip(req)
+	}
+	body := fmt.Sprintf(`{"object":"error","status":404,"code":%q,"message":"Could not find block."}`, notion.ErrorCodeObjectNotFound)
+	return &http.Response{
+		StatusCode: http.StatusNotFound,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func TestGetBlockTreeCancelsOnError(t *testing.T) {
+	s := gotiontest.NewServer()
+	defer s.Close()
+	page, err := s.AddPage(&notion.Page{Parent: notion.Parent{Type: notion.ParentTypeEnumWorkspace}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	toggles := make([]*notion.Block, 0, 20)
+	for i := 0; i < 20; i++ {
+		toggles = append(toggles, toggle(fmt.Sprint(i), toggle(fmt.Sprint(i, ".1"), paragraph("leaf"))))
+	}
+	if err := s.AddBlocks(page.ID.String(), toggles...); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	children, err := s.NewClient().GetBlockChildren(ctx, page.ID.String(), nil, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ft := &failingTransport{failID: children[0].ID.String()}
+
+	blocks, err := s.NewClient(gotion.WithTransport(ft)).GetBlockTree(ctx, page.ID.String(), -1, 1)
+	if !notion.IsNotFound(err) {
+		t.Fatalf("got error %v, want the error of the failed request", err)
+	}
+	var apiErr *notion.APIError
+	if !errors.As(err, &apiErr) || !strings.Contains(apiErr.URL, ft.failID) {
+		t.Errorf("got error %v, want the error for the children of %s", err, ft.failID)
+	}
+	if blocks != nil {
+		t.Errorf("got %d blocks with an error, want none", len(blocks))
+	}
+
+	// With one worker, the requests that were waiting are cancelled rather than sent.
+	// The whole tree would take 41 requests.
+	if ft.requests > 5 {
+		t.Errorf("got %d requests after the first failure, want the rest cancelled", ft.requests)
+	}
+}
@@ -4,16 +4,19 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/sethgrid/pester"
 	"github.com/thedadams/gotion/notion"
 	"golang.org/x/time/rate"
 )
 
-const apiBaseURL = "https://api.notion.com"
+const defaultBaseURL = "https://api.notion.com"
 
 type list interface {
 	Len() int
@@ -34,77 +37,148 @@ type Result struct {
 
 // Client is a client used to make calls to the Notion API.
 type Client struct {
-	settings    *notion.Settings
-	httpClient  *pester.Client
-	rateLimiter *rate.Limiter
+	settings   *notion.Settings
+	baseURL    string
+	httpClient *pester.Client
+	limiter    *adaptiveLimiter
+	// retryOnHTTP429 is handled by the client, rather than pester, so that Retry-After is respected.
+	retryOnHTTP429 bool
+	// lenientDecoding keeps enum values that are not known to the notion package instead of returning an error.
+	lenientDecoding bool
+	onUnknownEnum   func(*notion.InvalidEnumError)
 }
 
 // NewClient creates a new gotion client to use with the API.
 // By default:
 // - the client will use the most recent accepted Notion API version.
+// - the client will send requests to https://api.notion.com
 // - Timeout is 30 seconds
 // - Backoff strategy is set to pester.ExponentialJitterBackoff
-// - Rate limiter is set to 3 requests per second
+// - Rate limiter is set to 1 request per second, with bursts of 3, separately for each client
 // - MaxRetries is set to 8
-// - the client will retry on 429 errors.
+// - the client will retry on 429 errors, after waiting for the time given by Retry-After.
+// - the client will return an error for enum values in responses that are not known to the notion package.
 func NewClient(apiKey string, options ...Option) *Client {
-	c := &Client{settings: &notion.Settings{APIKey: apiKey}}
+	c := &Client{settings: &notion.Settings{APIKey: apiKey}, baseURL: defaultBaseURL}
 	WithPesterClient(pester.New())(c)
 	WithBackoffStrategy(pester.ExponentialJitterBackoff)(c)
 	WithTimeout(defaultTimeout)(c)
 	WithMaxRetries(defaultMaxRetries)(c)
 	WithRetryOnHTTP429()(c)
-	WithRateLimiter(defaultRateLimiter)(c)
+	WithRateLimiter(rate.NewLimiter(rate.Every(time.Second), defaultBurst))(c)
 	for _, o := range options {
 		o(c)
 	}
 	return c
 }
 
-func addQueryParams(url string, cursor *string, pageSize int) string {
-	if pageSize <= 0 || pageSize > 100 {
-		pageSize = 100
+func pageSize(n int) int {
+	if n <= 0 || n > 100 {
+		return 100
 	}
+	return n
+}
 
+func addQueryParams(url string, cursor *string, maxResults int) string {
 	if cursor == nil {
-		return fmt.Sprintf("%s?page_size=%d", url, pageSize)
+		return fmt.Sprintf("%s?page_size=%d", url, pageSize(maxResults))
 	}
-	return fmt.Sprintf("%s?start_cursor=%s&page_size=%d", url, *cursor, pageSize)
+	return fmt.Sprintf("%s?start_cursor=%s&page_size=%d", url, *cursor, pageSize(maxResults))
 }
 
 func (c *Client) makeRequest(ctx context.Context, method, url string, body io.Reader, respObject interface{}) error {
-	req, err := http.NewRequestWithContext(ctx, method, url, body)
-	if err != nil {
-		return err
+	// The body is read up front so that it can be sent again when retrying after being rate limited.
+	var bodyBytes []byte
+	if body != nil {
+		var err error
+		if bodyBytes, err = io.ReadAll(body); err != nil {
+			return err
+		}
 	}
 
-	c.settings.ToHeaders(req)
+	for attempt := 1; ; attempt++ {
+		var reqBody io.Reader
+		if body != nil {
+			reqBody = bytes.NewReader(bodyBytes)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+		if err != nil {
+			return err
+		}
 
-	if err := c.rateLimiter.Wait(ctx); err != nil {
-		return err
-	}
+		c.settings.ToHeaders(req)
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
+		if err := c.limiter.wait(ctx); err != nil {
+			return err
+		}
 
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return err
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return err
+		}
+
+		respBody, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			err = parseError(resp, method, url, respBody)
+			if !notion.IsRateLimitError(err) && resp.StatusCode != http.StatusTooManyRequests {
+				return err
+			}
+
+			c.limiter.pause(c.retryDelay(err, attempt))
+			if !c.retryOnHTTP429 || attempt >= c.httpClient.MaxRetries {
+				return err
+			}
+			continue
+		}
+
+		c.limiter.succeed()
+		if respObject == nil {
+			return nil
+		}
+		if err := json.Unmarshal(respBody, &respObject); err != nil {
+			return err
+		}
+		return c.checkEnums(respObject)
 	}
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return parseError(resp.Status, method, url, respBody)
+// checkEnums looks for enum values in the response that are not known to the notion package.
+// In strict mode, the first one is returned as an error. In lenient mode, they are reported to the warning hook, if there is one.
+func (c *Client) checkEnums(respObject interface{}) error {
+	errs := notion.ValidateEnums(respObject)
+	if len(errs) == 0 {
+		return nil
+	}
+	if !c.lenientDecoding {
+		return errs[0]
 	}
 
-	if respObject != nil {
-		return json.Unmarshal(respBody, &respObject)
+	if c.onUnknownEnum != nil {
+		for _, err := range errs {
+			c.onUnknownEnum(err)
+		}
 	}
 	return nil
 }
 
+// retryDelay returns how long to wait after being rate limited. The Retry-After time is used if the Notion API gave one.
+// Otherwise, the backoff strategy of the client is used.
+func (c *Client) retryDelay(err error, attempt int) time.Duration {
+	var apiErr *notion.APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		return apiErr.RetryAfter
+	}
+	if c.httpClient.Backoff != nil {
+		return c.httpClient.Backoff(attempt)
+	}
+	return 0
+}
+
 func (c *Client) createObject(ctx context.Context, url string, body map[string]interface{}, respObject interface{}) error {
 	bodyBytes, err := json.Marshal(body)
 	if err != nil {
@@ -124,53 +198,70 @@ func (c *Client) updateObject(ctx context.Context, url string, body, respObject
 }
 
 func (c *Client) queryForList(ctx context.Context, url string, body paginated, results list) error {
-	bodyBytes, err := json.Marshal(body)
-	if err != nil {
-		return err
-	}
+	ci := newCursorIterator(c, url, body, body.getCursor())
+	return c.collect(ctx, &ci, body.getMaxResults(), results)
+}
 
-	hasMore := true
-	maxResults := body.getMaxResults()
-	r := Result{Results: results}
+func (c *Client) getList(ctx context.Context, url string, cursor *string, maxResults int, results list) error {
+	ci := newCursorIterator(c, url, nil, cursor)
+	return c.collect(ctx, &ci, maxResults, results)
+}
 
-	for hasMore && (maxResults < 0 || results.Len() < maxResults) {
-		err = c.makeRequest(ctx, http.MethodPost, url, bytes.NewReader(bodyBytes), &r)
-		if err != nil {
-			return err
+// collect gets pages of results from the iterator until there are no more or maxResults have been gathered.
+// If `maxResults < 0`, then all results are gathered.
+func (c *Client) collect(ctx context.Context, ci *cursorIterator, maxResults int, results list) error {
+	for maxResults < 0 || results.Len() < maxResults {
+		if maxResults >= 0 {
+			ci.pageSize = maxResults - results.Len()
 		}
-
-		hasMore = r.HasMore
-		maxResults -= results.Len()
-		if bodyBytes, err = body.setPage(r.NextCursor, maxResults); err != nil {
-			return err
+		if !ci.fetch(ctx, results) {
+			break
 		}
 	}
 
-	return nil
+	return ci.Err()
 }
 
-func (c *Client) getList(ctx context.Context, url string, cursor *string, maxResults int, results list) error {
-	hasMore := true
-	r := Result{Results: results}
-
-	for hasMore && (maxResults < 0 || results.Len() < maxResults) {
-		err := c.makeRequest(ctx, http.MethodGet, addQueryParams(url, cursor, maxResults), nil, &r)
-		if err != nil {
-			return err
-		}
+// parseError turns an unsuccessful response from the Notion API into an *notion.APIError.
+func parseError(resp *http.Response, method, url string, body []byte) error {
+	apiError := &notion.APIError{}
+	if err := json.Unmarshal(body, apiError); err != nil || apiError.Code == "" {
+		// The response did not come from the Notion API itself, for example from a proxy.
+		apiError = &notion.APIError{Message: http.StatusText(resp.StatusCode)}
+	}
 
-		hasMore = r.HasMore
-		maxResults -= results.Len()
-		cursor = r.NextCursor
+	apiError.Status = resp.StatusCode
+	apiError.Method = method
+	apiError.URL = url
+	if apiError.RequestID == "" {
+		apiError.RequestID = resp.Header.Get("X-Request-Id")
 	}
+	apiError.RetryAfter = retryAfter(resp.Header.Get("Retry-After"))
+	return apiError
+}
 
-	return nil
+// retryAfter parses the value of a Retry-After header, which is either a number of seconds or an HTTP date.
+func retryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(header); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
 }
 
-func parseError(status, method, url string, body []byte) error {
-	apiError := notion.APIError{}
-	if err := json.Unmarshal(body, &apiError); err != nil {
-		return fmt.Errorf("error parsing error json: %w", err)
+// addIcons adds the icon and cover to the body of a request, if they are set.
+func addIcons(body map[string]interface{}, icons *notion.Icons) {
+	if icons.Icon != nil {
+		body["icon"] = icons.Icon
+	}
+	if icons.Cover != nil {
+		body["cover"] = icons.Cover
 	}
-	return fmt.Errorf("%s request to %s with status %s: %w", method, url, status, apiError)
 }
@@ -0,0 +1,66 @@
+package gotion_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/thedadams/gotion"
+	"github.com/thedadams/gotion/gotiontest"
+	"github.com/thedadams/gotion/notion"
+)
+
+func TestAPIError(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		code     string
+		sentinel error
+		notFound bool
+	}{
+		{name: "not found", status: http.StatusNotFound, code: notion.ErrorCodeObjectNotFound, sentinel: notion.ErrObjectNotFound, notFound: true},
+		{name: "conflict", status: http.StatusConflict, code: notion.ErrorCodeConflict, sentinel: notion.ErrConflict},
+		{name: "validation", status: http.StatusBadRequest, code: notion.ErrorCodeValidationError, sentinel: notion.ErrValidation},
+		{name: "unauthorized", status: http.StatusUnauthorized, code: notion.ErrorCodeUnauthorized, sentinel: notion.ErrUnauthorized},
+		{name: "internal error", status: http.StatusInternalServerError, code: notion.ErrorCodeInternalError, sentinel: notion.ErrInternalError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := gotiontest.NewServer()
+			defer s.Close()
+			page, err := s.AddPage(&notion.Page{Parent: notion.Parent{Type: notion.ParentTypeEnumWorkspace}})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			s.FailNext(tt.status, tt.code, "injected")
+			_, err = s.NewClient(gotion.WithMaxRetries(1)).GetPage(context.Background(), page.ID.String())
+			if err == nil {
+				t.Fatal("got no error")
+			}
+
+			var apiErr *notion.APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("got %T, want an *notion.APIError", err)
+			}
+			if apiErr.Status != tt.status || apiErr.Code != tt.code || apiErr.Message != "injected" {
+				t.Errorf("got status %d, code %q, and message %q, want %d, %q, and %q",
+					apiErr.Status, apiErr.Code, apiErr.Message, tt.status, tt.code, "injected")
+			}
+			if apiErr.Method != http.MethodGet {
+				t.Errorf("got method %q, want %q", apiErr.Method, http.MethodGet)
+			}
+			if !errors.Is(err, tt.sentinel) {
+				t.Errorf("errors.Is(err, %v) is false", tt.sentinel)
+			}
+			if errors.Is(err, notion.ErrRateLimited) {
+				t.Error("errors.Is(err, notion.ErrRateLimited) is true")
+			}
+			if got := notion.IsNotFound(err); got != tt.notFound {
+				t.Errorf("got IsNotFound %v, want %v", got, tt.notFound)
+			}
+		})
+	}
+}
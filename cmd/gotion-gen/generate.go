@@ -0,0 +1,311 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/thedadams/gotion/notion"
+)
+
+// A field is a field of the generated struct, for one property of the database.
+type field struct {
+	Name     string
+	Property string
+	GoType   string
+	Tag      string
+	// OptionType is the named type of the options of a select or multi-select property.
+	OptionType string
+	Options    []option
+	// Filter is the condition of the filter helper, and FilterFunc its name.
+	Filter, FilterFunc string
+}
+
+// An option is a constant for an option of a select or multi-select property.
+type option struct {
+	Name, Value string
+}
+
+type templateData struct {
+	Package, Type, Plural, DatabaseID, Title string
+	Fields                                   []field
+	Skipped                                  []string
+	ImportTime, ImportFilter                 bool
+}
+
+// generate returns the formatted Go source for the type of the pages in the database.
+func generate(db *notion.Database, pkg, typeName string) ([]byte, error) {
+	if typeName == "" {
+		typeName = identifier(notion.PlainText(db.Title), "Page")
+	}
+	ids := identifiers{}
+	for _, name := range []string{"", "DatabaseID", "Query" + plural(typeName), "Get", "Create", "Update", "new"} {
+		ids[name+typeName] = true
+	}
+	data := templateData{
+		Package:    pkg,
+		Type:       typeName,
+		Plural:     plural(typeName),
+		DatabaseID: db.ID.String(),
+		Title:      notion.PlainText(db.Title),
+	}
+
+	props := append(notion.DatabaseProperties{}, db.Properties...)
+	sort.SliceStable(props, func(i, j int) bool {
+		// The title comes first, followed by the other properties by name.
+		if ti, tj := props[i].Type == notion.DatabasePropertyTypeEnumTitle, props[j].Type == notion.DatabasePropertyTypeEnumTitle; ti != tj {
+			return ti
+		}
+		return props[i].Name < props[j].Name
+	})
+
+	// The ID of the page is not a property, but the helpers need it to update pages.
+	fieldNames := identifiers{"ID": true}
+	for _, prop := range props {
+		if prop == nil {
+			continue
+		}
+		f, ok := newField(prop, typeName, fieldNames, ids)
+		if !ok {
+			data.Skipped = append(data.Skipped, fmt.Sprintf("%q of type %s", prop.Name, prop.Type))
+			continue
+		}
+		data.Fields = append(data.Fields, f)
+		data.ImportTime = data.ImportTime || strings.Contains(f.GoType, "time.")
+		data.ImportFilter = data.ImportFilter || f.Filter != ""
+	}
+
+	var buf bytes.Buffer
+	if err := fileTemplate.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w", err)
+	}
+	return src, nil
+}
+
+// newField returns the field for the database property, or false if the property cannot be mapped.
+func newField(prop *notion.DatabaseProperty, typeName string, fieldNames, ids identifiers) (field, bool) {
+	// The struct tag cannot hold names with commas or backticks.
+	if strings.ContainsAny(prop.Name, ",`") {
+		return field{}, false
+	}
+
+	f := field{Property: prop.Name, Name: fieldNames.unique(identifier(prop.Name, "Property"))}
+	options := ""
+	switch prop.Type {
+	case notion.DatabasePropertyTypeEnumTitle, notion.DatabasePropertyTypeEnumRichText:
+		f.GoType = "string"
+	case notion.DatabasePropertyTypeEnumURL, notion.DatabasePropertyTypeEnumEmail, notion.DatabasePropertyTypeEnumPhoneNumber:
+		// Empty strings are not valid values for these properties, so they are omitted.
+		f.GoType, options = "string", ",omitempty"
+	case notion.DatabasePropertyTypeEnumNumber:
+		f.GoType = "*float64"
+	case notion.DatabasePropertyTypeEnumSelect:
+		f.OptionType = ids.unique(typeName + f.Name)
+		f.GoType, options, f.Filter = f.OptionType, ",omitempty", "Select().Equals"
+	case notion.DatabasePropertyTypeEnumMultiSelect:
+		f.OptionType = ids.unique(typeName + f.Name)
+		f.GoType, f.Filter = "[]"+f.OptionType, "MultiSelect().Contains"
+	case notion.DatabasePropertyTypeEnumDate:
+		f.GoType = "*time.Time"
+	case notion.DatabasePropertyTypeEnumPeople, notion.DatabasePropertyTypeEnumRelation:
+		f.GoType = "[]notion.UUID4"
+	case notion.DatabasePropertyTypeEnumFile:
+		f.GoType = "[]string"
+	case notion.DatabasePropertyTypeEnumCheckbox:
+		f.GoType = "bool"
+	case notion.DatabasePropertyTypeEnumFormula:
+		f.GoType = "*notion.Formula"
+	case notion.DatabasePropertyTypeEnumRollup:
+		f.GoType = "*notion.RollupValue"
+	case notion.DatabasePropertyTypeEnumCreatedTime, notion.DatabasePropertyTypeEnumLastEditedTime:
+		f.GoType = "time.Time"
+	case notion.DatabasePropertyTypeEnumCreatedBy, notion.DatabasePropertyTypeEnumLastEditedBy:
+		f.GoType = "*notion.User"
+	default:
+		return field{}, false
+	}
+
+	f.Tag = "`notion:" + strconv.Quote(prop.Name+","+string(prop.Type)+options) + "`"
+	if f.Filter != "" {
+		f.FilterFunc = ids.unique(f.OptionType + "Filter")
+	}
+	for _, o := range prop.SelectOptions {
+		f.Options = append(f.Options, option{Name: ids.unique(f.OptionType + identifier(o.Name, "Option")), Value: o.Name})
+	}
+	return f, true
+}
+
+// identifiers is a set of the identifiers that have been used in the generated code.
+type identifiers map[string]bool
+
+// unique returns the name, with a number added if it has already been used.
+func (ids identifiers) unique(name string) string {
+	unique := name
+	for i := 2; ids[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	ids[unique] = true
+	return unique
+}
+
+// identifier returns an exported Go identifier for the name, or the fallback if the name has no letters or digits.
+func identifier(name, fallback string) string {
+	var sb strings.Builder
+	upper := true
+	for _, r := range name {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if upper {
+				r = unicode.ToUpper(r)
+			}
+			sb.WriteRune(r)
+			upper = false
+		default:
+			upper = true
+		}
+	}
+
+	id := sb.String()
+	if id == "" {
+		return fallback
+	}
+	if r := []rune(id)[0]; !unicode.IsUpper(r) {
+		// Identifiers starting with a digit, or a letter without case, are not valid or not exported.
+		return fallback + id
+	}
+	return id
+}
+
+// plural returns the English plural of the name, used for the query helper.
+func plural(name string) string {
+	switch {
+	case strings.HasSuffix(name, "s"), strings.HasSuffix(name, "x"), strings.HasSuffix(name, "ch"), strings.HasSuffix(name, "sh"):
+		return name + "es"
+	case strings.HasSuffix(name, "y") && len(name) > 1 && !strings.ContainsRune("aeiou", rune(name[len(name)-2])):
+		return name[:len(name)-1] + "ies"
+	}
+	return name + "s"
+}
+
+var fileTemplate = template.Must(template.New("file").Parse(`// Code generated by gotion-gen; DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"context"
+{{- if .ImportTime}}
+	"time"
+{{- end}}
+
+	"github.com/thedadams/gotion"
+{{- if .ImportFilter}}
+	"github.com/thedadams/gotion/filter"
+{{- end}}
+	"github.com/thedadams/gotion/notion"
+)
+
+// {{.Type}}DatabaseID is the id of the {{printf "%q" .Title}} database in the Notion API.
+const {{.Type}}DatabaseID = {{printf "%q" .DatabaseID}}
+
+// A {{.Type}} is a page in the {{printf "%q" .Title}} database in the Notion API.
+{{- range .Skipped}}
+// The property {{.}} is not supported and has no field.
+{{- end}}
+type {{.Type}} struct {
+	// ID is the id of the page. It is the zero UUID for a {{.Type}} that has not been created yet.
+	ID notion.UUID4 ` + "`notion:\"-\"`" + `
+{{- range .Fields}}
+	{{.Name}} {{.GoType}} {{.Tag}}
+{{- end}}
+}
+{{range $f := .Fields}}{{if $f.OptionType}}
+// {{$f.OptionType}} is an option of the {{printf "%q" $f.Property}} property.
+type {{$f.OptionType}} string
+{{if $f.Options}}
+// These are the options of the {{printf "%q" $f.Property}} property.
+const (
+{{- range $f.Options}}
+	{{.Name}} {{$f.OptionType}} = {{printf "%q" .Value}}
+{{- end}}
+)
+{{end}}
+// {{$f.FilterFunc}} returns a filter for the pages with the given option of the {{printf "%q" $f.Property}} property.
+func {{$f.FilterFunc}}(o {{$f.OptionType}}) *notion.Filter {
+	return filter.Prop({{printf "%q" $f.Property}}).{{$f.Filter}}(string(o))
+}
+{{end}}{{end}}
+func new{{.Type}}(page *notion.Page) (*{{.Type}}, error) {
+	v := &{{.Type}}{ID: page.ID}
+	if err := notion.UnmarshalPage(page, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Query{{.Plural}} queries the {{printf "%q" .Title}} database in the Notion API.
+func Query{{.Plural}}(ctx context.Context, c *gotion.Client, query *gotion.DBQuery) ([]*{{.Type}}, error) {
+	if query == nil {
+		query = &gotion.DBQuery{}
+	}
+	pages, err := c.QueryDatabase(ctx, {{.Type}}DatabaseID, query)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*{{.Type}}, 0, len(pages))
+	for _, page := range pages {
+		v, err := new{{.Type}}(page)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, v)
+	}
+	return results, nil
+}
+
+// Get{{.Type}} gets the {{.Type}} with the given page id from the Notion API.
+func Get{{.Type}}(ctx context.Context, c *gotion.Client, id string) (*{{.Type}}, error) {
+	page, err := c.GetPage(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return new{{.Type}}(page)
+}
+
+// Create{{.Type}} creates a page for the {{.Type}} in the database in the Notion API, and sets its ID.
+func Create{{.Type}}(ctx context.Context, c *gotion.Client, v *{{.Type}}) error {
+	props, err := notion.MarshalPageProperties(v)
+	if err != nil {
+		return err
+	}
+	page, err := c.CreatePage(ctx, &notion.Page{
+		Parent:     notion.Parent{Type: notion.ParentTypeEnumDatabase, ID: {{.Type}}DatabaseID},
+		Properties: props,
+	})
+	if err != nil {
+		return err
+	}
+	v.ID = page.ID
+	return nil
+}
+
+// Update{{.Type}} updates the properties of the page of the {{.Type}} in the Notion API.
+func Update{{.Type}}(ctx context.Context, c *gotion.Client, v *{{.Type}}) error {
+	props, err := notion.MarshalPageProperties(v)
+	if err != nil {
+		return err
+	}
+	page := &notion.Page{Properties: props}
+	page.ID = v.ID
+	return c.UpdatePageProperties(ctx, page)
+}
+`))
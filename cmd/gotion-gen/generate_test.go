@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/thedadams/gotion/notion"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+func TestGenerate(t *testing.T) {
+	db := &notion.Database{
+		Object: notion.Object{ID: notion.UUID4(uuid.MustParse("0b9c3b0a-5f5e-4a4c-9d2f-8d3b1f9e2c11"))},
+		Title:  []notion.RichText{{Type: notion.RichTextTypeEnumText, PlainText: "Team tasks"}},
+		Properties: notion.DatabaseProperties{
+			{Name: "Tags", Type: notion.DatabasePropertyTypeEnumMultiSelect, SelectOptions: []notion.SelectOption{{Name: "bug"}, {Name: "needs review"}}},
+			{Name: "Status", Type: notion.DatabasePropertyTypeEnumSelect, SelectOptions: []notion.SelectOption{{Name: "To do"}, {Name: "Done"}}},
+			{Name: "Due date", Type: notion.DatabasePropertyTypeEnumDate},
+			{Name: "Points", Type: notion.DatabasePropertyTypeEnumNumber},
+			{Name: "Done", Type: notion.DatabasePropertyTypeEnumCheckbox},
+			{Name: "Assignee", Type: notion.DatabasePropertyTypeEnumPeople},
+			{Name: "Link", Type: notion.DatabasePropertyTypeEnumURL},
+			{Name: "Score", Type: notion.DatabasePropertyTypeEnumFormula},
+			{Name: "Created", Type: notion.DatabasePropertyTypeEnumCreatedTime},
+			{Name: "1st, 2nd", Type: notion.DatabasePropertyTypeEnumRichText},
+			{Name: "Task", Type: notion.DatabasePropertyTypeEnumTitle},
+		},
+	}
+
+	got, err := generate(db, "tasks", "Task")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden := filepath.Join("testdata", "tasks.go.golden")
+	if *update {
+		if err := ioutil.WriteFile(golden, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("generated code does not match %s; run go test with -update to update it\ngot:\n%s", golden, got)
+	}
+}
+
+func TestIdentifier(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{name: "due date", want: "DueDate"},
+		{name: "1st place", want: "Property1stPlace"},
+		{name: "!!!", want: "Property"},
+		{name: "naïve", want: "Naïve"},
+	}
+
+	for _, tt := range tests {
+		if got := identifier(tt.name, "Property"); got != tt.want {
+			t.Errorf("identifier(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// Command gotion-gen generates a Go type for the pages of a database in the Notion API, from the schema of the database.
+//
+// The generated file has a struct with a field for each property of the database, using the notion struct tags
+// of notion.UnmarshalPage, a named type with constants for the options of each select and multi-select property,
+// filters on those options, and helpers to query, get, create, and update the pages of the database.
+//
+// Usage:
+//
+//	gotion-gen -database <database-id> [-type Task] [-package tasks] [-o task_gen.go]
+//
+// The API key is read from the NOTION_API_KEY environment variable. It is best run with go generate:
+//
+//	//go:generate gotion-gen -database <database-id> -type Task -o task_gen.go
+//
+// When the schema of the database changes, running it again removes the fields and constants of the deleted properties
+// and options, so that code still using them fails to compile instead of failing at runtime.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/thedadams/gotion"
+)
+
+func main() {
+	var (
+		databaseID = flag.String("database", "", "the id of the database in the Notion API (required)")
+		typeName   = flag.String("type", "", "the name of the generated type (defaults to the title of the database)")
+		pkg        = flag.String("package", os.Getenv("GOPACKAGE"), "the package of the generated file (defaults to $GOPACKAGE, or main)")
+		output     = flag.String("o", "", "the file to write to (defaults to standard output)")
+		baseURL    = flag.String("base-url", "", "the base URL of the Notion API, for proxies and mocks")
+		timeout    = flag.Duration("timeout", 30*time.Second, "the timeout for getting the database")
+	)
+	flag.Parse()
+
+	if err := run(*databaseID, *typeName, *pkg, *output, *baseURL, *timeout); err != nil {
+		fmt.Fprintln(os.Stderr, "gotion-gen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(databaseID, typeName, pkg, output, baseURL string, timeout time.Duration) error {
+	if databaseID == "" {
+		return fmt.Errorf("the -database flag is required")
+	}
+	apiKey := os.Getenv("NOTION_API_KEY")
+	if apiKey == "" {
+		return fmt.Errorf("the NOTION_API_KEY environment variable is not set")
+	}
+	if pkg == "" {
+		pkg = "main"
+	}
+
+	var options []gotion.Option
+	if baseURL != "" {
+		options = append(options, gotion.WithBaseURL(baseURL))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	db, err := gotion.NewClient(apiKey, options...).GetDatabase(ctx, databaseID)
+	if err != nil {
+		return err
+	}
+
+	src, err := generate(db, pkg, typeName)
+	if err != nil {
+		return err
+	}
+	if output == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return ioutil.WriteFile(output, src, 0644)
+}
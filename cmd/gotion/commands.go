@@ -0,0 +1,693 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/thedadams/gotion"
+	"github.com/thedadams/gotion/filter"
+	"github.com/thedadams/gotion/markdown"
+	"github.com/thedadams/gotion/notion"
+)
+
+func pageGet(ctx context.Context, a *app, fs *flag.FlagSet, args []string) error {
+	depth := fs.Int("depth", 0, "the levels of children to get, or -1 for all of them (defaults to all for -o markdown)")
+	args, err := a.parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	if a.format == formatMarkdown && !isSet(fs, "depth") {
+		*depth = -1
+	}
+	c, err := a.connect()
+	if err != nil {
+		return err
+	}
+
+	var page *notion.Page
+	switch *depth {
+	case 0:
+		page, err = c.GetPage(ctx, args[0])
+	case 1:
+		page, err = c.GetPageAndChildren(ctx, args[0], -1)
+	default:
+		page, err = c.GetPageTree(ctx, args[0], *depth, 0)
+	}
+	if err != nil {
+		return err
+	}
+	return a.print(pageView(page))
+}
+
+func pageCreate(ctx context.Context, a *app, fs *flag.FlagSet, args []string) error {
+	parent := fs.String("parent", "", "the id of the parent page, or database with -database (required)")
+	inDatabase := fs.Bool("database", false, "the parent is a database")
+	title := fs.String("title", "", "the title of the page")
+	file := fs.String("f", "", "a JSON file with the properties and children of the page, as in the Notion API")
+	md := fs.String("markdown", "", "a Markdown file with the content of the page")
+	if _, err := a.parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+	if *parent == "" {
+		return fmt.Errorf("the -parent flag is required")
+	}
+
+	page := new(notion.Page)
+	if err := a.readJSON(*file, page); err != nil {
+		return err
+	}
+	page.Parent = notion.Parent{Type: notion.ParentTypeEnumPage, ID: *parent}
+	if *inDatabase {
+		page.Parent.Type = notion.ParentTypeEnumDatabase
+	}
+	if *title != "" {
+		if err := page.Properties.SetTitle(*title); err != nil {
+			return err
+		}
+	}
+	if *md != "" {
+		src, err := a.readFile(*md)
+		if err != nil {
+			return err
+		}
+		if page.Children, err = markdown.Parse(src); err != nil {
+			return err
+		}
+	}
+
+	c, err := a.connect()
+	if err != nil {
+		return err
+	}
+	if page, err = c.CreatePage(ctx, page); err != nil {
+		return err
+	}
+	return a.print(pageView(page))
+}
+
+func pageUpdate(ctx context.Context, a *app, fs *flag.FlagSet, args []string) error {
+	title := fs.String("title", "", "the new title of the page")
+	file := fs.String("f", "", "a JSON file with the properties to update, as in the Notion API")
+	args, err := a.parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	if *title == "" && *file == "" {
+		return fmt.Errorf("nothing to update: use -title or -f")
+	}
+
+	page := new(notion.Page)
+	if err := a.readJSON(*file, page); err != nil {
+		return err
+	}
+	if *title != "" {
+		if err := page.Properties.SetTitle(*title); err != nil {
+			return err
+		}
+	}
+
+	c, err := a.connect()
+	if err != nil {
+		return err
+	}
+	if err := parseID(args[0], &page.ID); err != nil {
+		return err
+	}
+	if err := c.UpdatePageProperties(ctx, page); err != nil {
+		return err
+	}
+	return a.print(pageView(page))
+}
+
+func pageArchive(ctx context.Context, a *app, fs *flag.FlagSet, args []string) error {
+	tree := fs.Bool("tree", false, "also archive the child pages of the page, and their child pages")
+	args, err := a.parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	c, err := a.connect()
+	if err != nil {
+		return err
+	}
+
+	if !*tree {
+		page, err := c.ArchivePage(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		return a.print(pageView(page))
+	}
+	ids, err := c.ArchivePageTree(ctx, args[0])
+	if len(ids) != 0 {
+		// The pages that were archived are printed even if archiving the others failed.
+		if err := a.print(idsView(ids)); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+func pageRestore(ctx context.Context, a *app, fs *flag.FlagSet, args []string) error {
+	args, err := a.parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	c, err := a.connect()
+	if err != nil {
+		return err
+	}
+
+	page, err := c.RestorePage(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return a.print(pageView(page))
+}
+
+func dbList(ctx context.Context, a *app, fs *flag.FlagSet, args []string) error {
+	limit := fs.Int("limit", 0, "the maximum number of databases to list, or 0 for all of them")
+	if _, err := a.parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+	c, err := a.connect()
+	if err != nil {
+		return err
+	}
+
+	dbs := make([]*notion.Database, 0)
+	it := c.IterateDatabases(nil)
+	for (*limit <= 0 || len(dbs) < *limit) && it.Next(ctx) {
+		dbs = append(dbs, it.Value())
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+	return a.print(databasesView(dbs))
+}
+
+func dbGet(ctx context.Context, a *app, fs *flag.FlagSet, args []string) error {
+	children := fs.Bool("children", false, "also get the children of the database")
+	args, err := a.parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	c, err := a.connect()
+	if err != nil {
+		return err
+	}
+
+	var db *notion.Database
+	if *children {
+		db, err = c.GetDatabaseAndChildren(ctx, args[0], -1)
+	} else {
+		db, err = c.GetDatabase(ctx, args[0])
+	}
+	if err != nil {
+		return err
+	}
+	return a.print(databaseView(db))
+}
+
+func dbQuery(ctx context.Context, a *app, fs *flag.FlagSet, args []string) error {
+	filterJSON := fs.String("filter", "", "the filter, as JSON in the Notion API, or @file to read it from a file")
+	var sorts sortFlag
+	fs.Var(&sorts, "sort", "a property, created_time, or last_edited_time to sort by, with a leading - for descending (repeatable)")
+	limit := fs.Int("limit", 0, "the maximum number of pages to get, or 0 for all of them")
+	args, err := a.parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	query := new(gotion.DBQuery)
+	if *filterJSON != "" {
+		raw := []byte(*filterJSON)
+		if strings.HasPrefix(*filterJSON, "@") {
+			if raw, err = a.readFile(strings.TrimPrefix(*filterJSON, "@")); err != nil {
+				return err
+			}
+		}
+		if !json.Valid(raw) {
+			return fmt.Errorf("the -filter flag is not valid JSON")
+		}
+		query.Filter = notion.RawFilter(raw)
+	}
+	if query.Sorts, err = sorts.Build(); err != nil {
+		return err
+	}
+
+	c, err := a.connect()
+	if err != nil {
+		return err
+	}
+	var db *notion.Database
+	if a.format != formatJSON {
+		if db, err = c.GetDatabase(ctx, args[0]); err != nil {
+			return err
+		}
+	}
+
+	var pages []*notion.Page
+	if *limit <= 0 {
+		pages, err = c.QueryDatabase(ctx, args[0], query)
+	} else {
+		it := c.IterateDatabase(args[0], query)
+		for len(pages) < *limit && it.Next(ctx) {
+			pages = append(pages, it.Value())
+		}
+		err = it.Err()
+	}
+	if err != nil {
+		return err
+	}
+	if pages == nil {
+		pages = []*notion.Page{}
+	}
+	return a.print(pagesView(db, pages))
+}
+
+func dbCreate(ctx context.Context, a *app, fs *flag.FlagSet, args []string) error {
+	parent := fs.String("parent", "", "the id of the parent page (required)")
+	title := fs.String("title", "", "the title of the database")
+	file := fs.String("f", "", "a JSON file with the title and properties of the database, as in the Notion API (required)")
+	if _, err := a.parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+	if *parent == "" || *file == "" {
+		return fmt.Errorf("the -parent and -f flags are required")
+	}
+
+	db := new(notion.Database)
+	if err := a.readJSON(*file, db); err != nil {
+		return err
+	}
+	db.Parent = notion.Parent{Type: notion.ParentTypeEnumPage, ID: *parent}
+	if *title != "" {
+		db.Title = notion.FromPlainText(*title)
+	}
+
+	c, err := a.connect()
+	if err != nil {
+		return err
+	}
+	if err := c.CreateDatabase(ctx, db); err != nil {
+		return err
+	}
+	return a.print(databaseView(db))
+}
+
+func dbUpdate(ctx context.Context, a *app, fs *flag.FlagSet, args []string) error {
+	title := fs.String("title", "", "the new title of the database")
+	file := fs.String("f", "", "a JSON file with the title and properties to update, as in the Notion API")
+	args, err := a.parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	if *title == "" && *file == "" {
+		return fmt.Errorf("nothing to update: use -title or -f")
+	}
+	c, err := a.connect()
+	if err != nil {
+		return err
+	}
+
+	// The title and properties are both sent, so the ones that are not given are those of the database.
+	db, err := c.GetDatabase(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	if err := a.readJSON(*file, db); err != nil {
+		return err
+	}
+	if *title != "" {
+		db.Title = notion.FromPlainText(*title)
+	}
+	if err := c.UpdateDatabase(ctx, db); err != nil {
+		return err
+	}
+	return a.print(databaseView(db))
+}
+
+func dbMigrate(ctx context.Context, a *app, fs *flag.FlagSet, args []string) error {
+	from := fs.String("from", "", "the id of the database whose properties are copied (required)")
+	dryRun := fs.Bool("dry-run", false, "only print the changes, without making them")
+	allowRemovals := fs.Bool("allow-removals", false, "remove the properties that are not in the database given by -from, with their values")
+	allowRetypes := fs.Bool("allow-retypes", false, "change the types of properties to those in the database given by -from, converting their values")
+	var renames renameFlag
+	fs.Var(&renames, "rename", "a property `old=new` that is named new in the database given by -from (repeatable)")
+	args, err := a.parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	if *from == "" {
+		return fmt.Errorf("the -from flag is required")
+	}
+	c, err := a.connect()
+	if err != nil {
+		return err
+	}
+
+	source, err := c.GetDatabase(ctx, *from)
+	if err != nil {
+		return err
+	}
+	target, err := c.GetDatabase(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	diff := notion.DiffSchemaWithRenames(target.Properties, source.Properties, renames)
+	var options []gotion.ApplyOption
+	if *allowRemovals {
+		options = append(options, gotion.AllowRemovals())
+	}
+	if *allowRetypes {
+		options = append(options, gotion.AllowRetypes())
+	}
+	updates, err := c.ApplySchema(ctx, args[0], diff, *dryRun, options...)
+	if err != nil {
+		if *dryRun {
+			fmt.Fprintln(a.stderr, diff.String())
+		}
+		return err
+	}
+	if updates == nil {
+		updates = []notion.SchemaUpdate{}
+	}
+	return a.print(view{
+		value: updates,
+		text: func(w io.Writer) error {
+			_, err := io.WriteString(w, diff.String())
+			return err
+		},
+	})
+}
+
+func blocksGet(ctx context.Context, a *app, fs *flag.FlagSet, args []string) error {
+	args, err := a.parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	c, err := a.connect()
+	if err != nil {
+		return err
+	}
+
+	block, err := c.GetBlock(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	v := blocksView([]*notion.Block{block})
+	v.value = block
+	return a.print(v)
+}
+
+func blocksChildren(ctx context.Context, a *app, fs *flag.FlagSet, args []string) error {
+	depth := fs.Int("depth", 1, "the levels of children to get, or -1 for all of them")
+	args, err := a.parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	c, err := a.connect()
+	if err != nil {
+		return err
+	}
+
+	var blocks []*notion.Block
+	if *depth == 1 {
+		blocks, err = c.GetBlockChildren(ctx, args[0], nil, -1)
+	} else {
+		blocks, err = c.GetBlockTree(ctx, args[0], *depth, 0)
+	}
+	if err != nil {
+		return err
+	}
+	return a.print(blocksView(blocks))
+}
+
+func blocksAppend(ctx context.Context, a *app, fs *flag.FlagSet, args []string) error {
+	file := fs.String("f", "", "a JSON file with an array of blocks, as in the Notion API")
+	md := fs.String("markdown", "", "a Markdown file with the blocks")
+	args, err := a.parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	if (*file == "") == (*md == "") {
+		return fmt.Errorf("one of the -f and -markdown flags is required")
+	}
+
+	block := new(notion.Block)
+	if err := parseID(args[0], &block.ID); err != nil {
+		return err
+	}
+	if *md != "" {
+		src, err := a.readFile(*md)
+		if err != nil {
+			return err
+		}
+		if block.Children, err = markdown.Parse(src); err != nil {
+			return err
+		}
+	} else if err := a.readJSON(*file, &block.Children); err != nil {
+		return err
+	}
+
+	c, err := a.connect()
+	if err != nil {
+		return err
+	}
+	if block, err = c.AppendBlockChildren(ctx, block); err != nil {
+		return err
+	}
+	return a.print(blocksView(block.Children))
+}
+
+func blocksUpdate(ctx context.Context, a *app, fs *flag.FlagSet, args []string) error {
+	file := fs.String("f", "", "a JSON file with the block, as in the Notion API (required)")
+	args, err := a.parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	if *file == "" {
+		return fmt.Errorf("the -f flag is required")
+	}
+
+	block := new(notion.Block)
+	if err := a.readJSON(*file, block); err != nil {
+		return err
+	}
+	if err := parseID(args[0], &block.ID); err != nil {
+		return err
+	}
+
+	c, err := a.connect()
+	if err != nil {
+		return err
+	}
+	if err := c.UpdateBlock(ctx, block); err != nil {
+		return err
+	}
+	v := blocksView([]*notion.Block{block})
+	v.value = block
+	return a.print(v)
+}
+
+func blocksDelete(ctx context.Context, a *app, fs *flag.FlagSet, args []string) error {
+	args, err := a.parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	c, err := a.connect()
+	if err != nil {
+		return err
+	}
+	return c.DeleteBlock(ctx, args[0])
+}
+
+func search(ctx context.Context, a *app, fs *flag.FlagSet, args []string) error {
+	objectType := fs.String("type", "", "only find pages or databases: page or database")
+	limit := fs.Int("limit", 0, "the maximum number of results, or 0 for all of them")
+	args, err := a.parse(fs, args, 0, 1)
+	if err != nil {
+		return err
+	}
+
+	query := &gotion.SearchQuery{Query: strings.Join(args, " ")}
+	if *objectType != "" {
+		t := notion.FilterObjectConditionEnum(*objectType)
+		if !t.IsValidEnum() {
+			return fmt.Errorf("unknown -type %q: use page or database", *objectType)
+		}
+		query.Filter = filter.Object(t)
+	}
+	c, err := a.connect()
+	if err != nil {
+		return err
+	}
+
+	results := make([]*gotion.SearchResult, 0)
+	it := c.IterateSearch(query)
+	for (*limit <= 0 || len(results) < *limit) && it.Next(ctx) {
+		results = append(results, it.Value())
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+	return a.print(searchView(results))
+}
+
+func usersList(ctx context.Context, a *app, fs *flag.FlagSet, args []string) error {
+	limit := fs.Int("limit", 0, "the maximum number of users to list, or 0 for all of them")
+	if _, err := a.parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+	c, err := a.connect()
+	if err != nil {
+		return err
+	}
+
+	var users []*notion.User
+	if *limit <= 0 {
+		users, err = c.GetUsers(ctx, nil, -1)
+	} else {
+		it := c.IterateUsers(nil)
+		for len(users) < *limit && it.Next(ctx) {
+			users = append(users, it.Value())
+		}
+		err = it.Err()
+	}
+	if err != nil {
+		return err
+	}
+	if users == nil {
+		users = []*notion.User{}
+	}
+	return a.print(usersView(users))
+}
+
+func usersGet(ctx context.Context, a *app, fs *flag.FlagSet, args []string) error {
+	args, err := a.parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	c, err := a.connect()
+	if err != nil {
+		return err
+	}
+
+	user, err := c.GetUser(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	v := usersView([]*notion.User{user})
+	v.value = user
+	return a.print(v)
+}
+
+// renameFlag holds the rename hints of a schema migration, from the old name of a property to its new name.
+type renameFlag map[string]string
+
+func (rf renameFlag) String() string {
+	renames := make([]string, 0, len(rf))
+	for from, to := range rf {
+		renames = append(renames, from+"="+to)
+	}
+	sort.Strings(renames)
+	return strings.Join(renames, ",")
+}
+
+func (rf *renameFlag) Set(s string) error {
+	i := strings.Index(s, "=")
+	if i <= 0 || i == len(s)-1 {
+		return fmt.Errorf("a rename must be old=new, got %q", s)
+	}
+	if *rf == nil {
+		*rf = renameFlag{}
+	}
+	(*rf)[s[:i]] = s[i+1:]
+	return nil
+}
+
+// sortFlag is the value of the repeatable -sort flag.
+type sortFlag []string
+
+func (sf *sortFlag) String() string {
+	return strings.Join(*sf, ",")
+}
+
+func (sf *sortFlag) Set(s string) error {
+	*sf = append(*sf, s)
+	return nil
+}
+
+// Build returns the sorts of the flag, in order.
+func (sf sortFlag) Build() ([]*notion.Sort, error) {
+	if len(sf) == 0 {
+		return nil, nil
+	}
+	sb := notion.NewSortBuilder()
+	for _, s := range sf {
+		direction := notion.SortDirectionEnum(notion.SortDirectionEnumAscending)
+		if strings.HasPrefix(s, "-") {
+			s, direction = s[1:], notion.SortDirectionEnumDescending
+		}
+		switch s {
+		case notion.SortTimestampEnumCreatedTime, notion.SortTimestampEnumLastEditedTime:
+			sb.Timestamp(notion.SortTimestampEnum(s), direction)
+		default:
+			sb.Property(s, direction)
+		}
+	}
+	return sb.Build()
+}
+
+// readFile reads the file at the path, or standard input if the path is "-".
+func (a *app) readFile(path string) ([]byte, error) {
+	if path == "-" {
+		return ioutil.ReadAll(a.stdin)
+	}
+	return ioutil.ReadFile(path)
+}
+
+// readJSON unmarshals the JSON file at the path into v, if the path is not empty.
+func (a *app) readJSON(path string, v interface{}) error {
+	if path == "" {
+		return nil
+	}
+	b, err := a.readFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		name := path
+		if path == "-" {
+			name = "standard input"
+		}
+		return fmt.Errorf("reading %s: %w", name, err)
+	}
+	return nil
+}
+
+// parseID parses the id of an object in the Notion API, with or without dashes.
+func parseID(s string, id *notion.UUID4) error {
+	u, err := uuid.Parse(s)
+	if err != nil {
+		return fmt.Errorf("invalid id %q: %w", s, err)
+	}
+	*id = notion.UUID4(u)
+	return nil
+}
+
+// isSet returns true if the flag with the given name was given on the command line.
+func isSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		set = set || f.Name == name
+	})
+	return set
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/thedadams/gotion/notion"
+)
+
+// config is the configuration of gotion, from the configuration file and the environment.
+type config struct {
+	APIKey    string `json:"api_key"`
+	Version   string `json:"version"`
+	UserAgent string `json:"user_agent"`
+	BaseURL   string `json:"base_url"`
+
+	// path is the configuration file that was read, if any.
+	path string
+}
+
+// loadConfig reads the configuration file at the path, and then the environment. If the path is empty, then the file
+// given by $GOTION_CONFIG, or the default file in the user's configuration directory, is read, if it exists.
+func loadConfig(path string) (*config, error) {
+	cfg := new(config)
+	required := path != ""
+	if path == "" {
+		path = os.Getenv("GOTION_CONFIG")
+		required = path != ""
+	}
+	if path == "" {
+		if dir, err := os.UserConfigDir(); err == nil {
+			path = filepath.Join(dir, "gotion", "config.json")
+		}
+	}
+
+	if path != "" {
+		b, err := ioutil.ReadFile(path)
+		switch {
+		case err == nil:
+			if err := json.Unmarshal(b, cfg); err != nil {
+				return nil, fmt.Errorf("reading the configuration file %s: %w", path, err)
+			}
+			cfg.path = path
+		case required || !os.IsNotExist(err):
+			return nil, fmt.Errorf("reading the configuration file: %w", err)
+		}
+	}
+
+	for env, value := range map[string]*string{
+		"NOTION_API_KEY":  &cfg.APIKey,
+		"NOTION_VERSION":  &cfg.Version,
+		"NOTION_BASE_URL": &cfg.BaseURL,
+	} {
+		if v := os.Getenv(env); v != "" {
+			*value = v
+		}
+	}
+	return cfg, nil
+}
+
+// settings returns the settings for the Notion API, checking that there is an API key and that the version is known.
+func (cfg *config) settings() (*notion.Settings, error) {
+	if cfg.APIKey == "" {
+		where := "the NOTION_API_KEY environment variable"
+		if cfg.path != "" {
+			where += ", or api_key in " + cfg.path
+		} else {
+			where += ", or api_key in a configuration file"
+		}
+		return nil, fmt.Errorf("no API key for the Notion API: set %s", where)
+	}
+
+	s := notion.LatestWithAPIKey(cfg.APIKey)
+	if cfg.Version != "" {
+		known := false
+		for _, v := range notion.Versions {
+			known = known || v == cfg.Version
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown version of the Notion API %q: use one of %s", cfg.Version, strings.Join(notion.Versions, ", "))
+		}
+		s.Version = cfg.Version
+	}
+	if cfg.UserAgent != "" {
+		s.UserAgent = cfg.UserAgent
+	}
+	return s, nil
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/thedadams/gotion/gotiontest"
+)
+
+// writeConfig writes the JSON configuration file in a temporary directory, and returns its path.
+func writeConfig(t *testing.T, dir, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, "config.json")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	isolate(t)
+	dir := t.TempDir()
+	file := writeConfig(t, dir, `{"api_key": "secret_file", "version": "2021-05-13", "user_agent": "script"}`)
+	other := writeConfig(t, filepath.Join(dir, "other"), `{"api_key": "secret_other"}`)
+	// The default file is in the user's configuration directory, which isolate has moved.
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defaultFile := writeConfig(t, filepath.Join(configDir, "gotion"), `{"api_key": "secret_default"}`)
+
+	tests := []struct {
+		name    string
+		path    string
+		env     map[string]string
+		want    config
+		wantErr string
+	}{
+		{name: "default file", want: config{APIKey: "secret_default", path: defaultFile}},
+		{name: "flag", path: file, want: config{APIKey: "secret_file", Version: "2021-05-13", UserAgent: "script", path: file}},
+		{name: "environment file", env: map[string]string{"GOTION_CONFIG": other}, want: config{APIKey: "secret_other", path: other}},
+		{
+			name: "flag over environment file",
+			path: file,
+			env:  map[string]string{"GOTION_CONFIG": other},
+			want: config{APIKey: "secret_file", Version: "2021-05-13", UserAgent: "script", path: file},
+		},
+		{
+			name: "environment over file",
+			path: file,
+			env:  map[string]string{"NOTION_API_KEY": "secret_env", "NOTION_VERSION": "2021-08-16", "NOTION_BASE_URL": "http://localhost"},
+			want: config{APIKey: "secret_env", Version: "2021-08-16", UserAgent: "script", BaseURL: "http://localhost", path: file},
+		},
+		{name: "missing flag file", path: filepath.Join(dir, "missing.json"), wantErr: "missing.json"},
+		{name: "missing environment file", env: map[string]string{"GOTION_CONFIG": filepath.Join(dir, "missing.json")}, wantErr: "missing.json"},
+		{name: "invalid file", path: writeConfig(t, filepath.Join(dir, "invalid"), `{"api_key":`), wantErr: "invalid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				setenv(t, key, value)
+			}
+			cfg, err := loadConfig(tt.path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("got error %v, want an error about %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *cfg != tt.want {
+				t.Errorf("got %+v, want %+v", *cfg, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfigWithoutFile(t *testing.T) {
+	isolate(t)
+	cfg, err := loadConfig("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cfg.settings(); err == nil || !strings.Contains(err.Error(), "NOTION_API_KEY") {
+		t.Errorf("got error %v, want an error about the missing API key", err)
+	}
+
+	cfg.APIKey, cfg.Version = "secret", "2020-01-01"
+	if _, err := cfg.settings(); err == nil || !strings.Contains(err.Error(), "2021-08-16") {
+		t.Errorf("got error %v, want an error that lists the known versions", err)
+	}
+}
+
+// TestConnectPrecedence checks that the flags take precedence over the environment,
+// and the environment over the configuration file, for the settings that are sent to the Notion API.
+func TestConnectPrecedence(t *testing.T) {
+	s := gotiontest.NewServer()
+	defer s.Close()
+
+	tests := []struct {
+		name        string
+		config      string
+		env         map[string]string
+		args        []string
+		wantVersion string
+	}{
+		{
+			name:        "file",
+			config:      `{"api_key": "` + s.Token + `", "version": "2021-05-13", "base_url": "` + s.URL + `"}`,
+			wantVersion: "2021-05-13",
+		},
+		{
+			name:        "environment",
+			config:      `{"api_key": "secret_wrong", "version": "2021-05-13", "base_url": "http://127.0.0.1:1"}`,
+			env:         map[string]string{"NOTION_API_KEY": s.Token, "NOTION_VERSION": "2021-08-16", "NOTION_BASE_URL": s.URL},
+			wantVersion: "2021-08-16",
+		},
+		{
+			name:        "flags",
+			config:      `{"api_key": "` + s.Token + `", "version": "2021-08-16", "base_url": "http://127.0.0.1:1"}`,
+			env:         map[string]string{"NOTION_VERSION": "2021-08-16", "NOTION_BASE_URL": "http://127.0.0.1:1"},
+			args:        []string{"-api-version", "2021-05-13", "-base-url", s.URL},
+			wantVersion: "2021-05-13",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolate(t)
+			for key, value := range tt.env {
+				setenv(t, key, value)
+			}
+			path := writeConfig(t, t.TempDir(), tt.config)
+
+			before := len(s.Requests())
+			if _, _, err := runApp(append([]string{"-config", path, "users", "ls"}, tt.args...)...); err != nil {
+				t.Fatal(err)
+			}
+			requests := s.Requests()[before:]
+			if len(requests) != 1 {
+				t.Fatalf("got %d requests, want 1", len(requests))
+			}
+			if got := requests[0].Header.Get("Notion-Version"); got != tt.wantVersion {
+				t.Errorf("got version %q, want %q", got, tt.wantVersion)
+			}
+		})
+	}
+}
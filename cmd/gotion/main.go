@@ -0,0 +1,251 @@
+// Command gotion is a command-line client for the Notion API, so that it can be scripted without writing Go.
+//
+// Usage:
+//
+//	gotion [flags] <command> [subcommand] [flags] [arguments]
+//
+// The commands are:
+//
+//	page get [-depth n] <page-id>
+//	page create -parent <id> [-database] [-title text] [-f page.json] [-markdown page.md]
+//	page update [-title text] [-f page.json] <page-id>
+//	page archive [-tree] <page-id>
+//	page restore <page-id>
+//	db ls [-limit n]
+//	db get [-children] <database-id>
+//	db query [-filter json] [-sort property]... [-limit n] <database-id>
+//	db create -parent <page-id> [-title text] -f database.json
+//	db update [-title text] [-f database.json] <database-id>
+//	db migrate -from <database-id> [-dry-run] [-rename old=new] [-allow-removals] [-allow-retypes] <database-id>
+//	blocks get <block-id>
+//	blocks children [-depth n] <block-id>
+//	blocks append [-f blocks.json] [-markdown blocks.md] <block-id>
+//	blocks update -f block.json <block-id>
+//	blocks delete <block-id>
+//	search [-type page|database] [-limit n] [query]
+//	users ls [-limit n]
+//	users get <user-id>
+//
+// The output is JSON, as in the Notion API, by default. The -o flag prints a table or Markdown instead:
+// pages and blocks are written as Markdown documents, and everything else as Markdown tables.
+// The JSON that is printed for a page, database, or block can be given back with -f, for example to copy a database.
+// Flags must come before the arguments of a command, and the -f and -markdown flags read standard input for "-".
+//
+// The API key is read from the NOTION_API_KEY environment variable, the version of the Notion API from NOTION_VERSION,
+// and the base URL from NOTION_BASE_URL. Any of them can instead be set in a JSON configuration file:
+//
+//	{"api_key": "secret_...", "version": "2021-08-16", "user_agent": "my-script", "base_url": "https://api.notion.com"}
+//
+// The configuration file is read from the -config flag, the GOTION_CONFIG environment variable,
+// or gotion/config.json in the user's configuration directory, like ~/.config on Linux. The environment takes
+// precedence over the configuration file, and the flags over both.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/thedadams/gotion"
+)
+
+// A command is a subcommand of gotion. Its flags are added to the flag set, along with the common flags, before parsing.
+type command struct {
+	usage string
+	run   func(ctx context.Context, a *app, fs *flag.FlagSet, args []string) error
+}
+
+// commands are the commands of gotion, by name and subcommand. A command without subcommands has the subcommand "".
+var commands = map[string]map[string]command{
+	"page": {
+		"get":     {"[-depth n] <page-id>", pageGet},
+		"create":  {"-parent <id> [-database] [-title text] [-f page.json] [-markdown page.md]", pageCreate},
+		"update":  {"[-title text] [-f page.json] <page-id>", pageUpdate},
+		"archive": {"[-tree] <page-id>", pageArchive},
+		"restore": {"<page-id>", pageRestore},
+	},
+	"db": {
+		"ls":      {"[-limit n]", dbList},
+		"get":     {"[-children] <database-id>", dbGet},
+		"query":   {"[-filter json] [-sort property]... [-limit n] <database-id>", dbQuery},
+		"create":  {"-parent <page-id> [-title text] -f database.json", dbCreate},
+		"update":  {"[-title text] [-f database.json] <database-id>", dbUpdate},
+		"migrate": {"-from <database-id> [-dry-run] [-rename old=new] [-allow-removals] [-allow-retypes] <database-id>", dbMigrate},
+	},
+	"blocks": {
+		"get":      {"<block-id>", blocksGet},
+		"children": {"[-depth n] <block-id>", blocksChildren},
+		"append":   {"[-f blocks.json] [-markdown blocks.md] <block-id>", blocksAppend},
+		"update":   {"-f block.json <block-id>", blocksUpdate},
+		"delete":   {"<block-id>", blocksDelete},
+	},
+	"search": {
+		"": {"[-type page|database] [-limit n] [query]", search},
+	},
+	"users": {
+		"ls":  {"[-limit n]", usersList},
+		"get": {"<user-id>", usersGet},
+	},
+}
+
+// errUsage is returned when gotion is used incorrectly, after the error or the usage has been printed.
+var errUsage = errors.New("usage")
+
+// app holds the common flags, and the client that is created from them.
+type app struct {
+	stdin          io.Reader
+	stdout, stderr io.Writer
+
+	format     string
+	configPath string
+	apiVersion string
+	baseURL    string
+	timeout    time.Duration
+
+	client *gotion.Client
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	a := &app{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr, format: formatJSON, timeout: 30 * time.Second}
+	if err := a.run(ctx, os.Args[1:]); err == errUsage {
+		os.Exit(2)
+	} else if err != nil {
+		fmt.Fprintln(a.stderr, "gotion:", err)
+		os.Exit(1)
+	}
+}
+
+func (a *app) run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("gotion", flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	a.commonFlags(fs)
+	fs.Usage = a.usage
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+
+	args = fs.Args()
+	if len(args) == 0 {
+		a.usage()
+		return errUsage
+	}
+	subcommands, ok := commands[args[0]]
+	if !ok {
+		a.usage()
+		return errUsage
+	}
+	name, args := args[0], args[1:]
+	cmd, ok := subcommands[""]
+	if !ok {
+		if len(args) == 0 {
+			a.usage()
+			return errUsage
+		}
+		if cmd, ok = subcommands[args[0]]; !ok {
+			a.usage()
+			return errUsage
+		}
+		name, args = name+" "+args[0], args[1:]
+	}
+
+	fs = flag.NewFlagSet("gotion "+name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	a.commonFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(a.stderr, "Usage: gotion %s %s\n\nFlags:\n", name, cmd.usage)
+		fs.PrintDefaults()
+	}
+	return cmd.run(ctx, a, fs, args)
+}
+
+// commonFlags adds the flags that are accepted before and after any command. They default to their current values,
+// so that flags given before the command are kept.
+func (a *app) commonFlags(fs *flag.FlagSet) {
+	fs.StringVar(&a.format, "o", a.format, "the output format: json, table, or markdown")
+	fs.StringVar(&a.configPath, "config", a.configPath,
+		"the configuration file (defaults to $GOTION_CONFIG, or gotion/config.json in the user's configuration directory)")
+	fs.StringVar(&a.apiVersion, "api-version", a.apiVersion, "the version of the Notion API (defaults to $NOTION_VERSION, or the latest)")
+	fs.StringVar(&a.baseURL, "base-url", a.baseURL, "the base URL of the Notion API, for proxies and mocks (defaults to $NOTION_BASE_URL)")
+	fs.DurationVar(&a.timeout, "timeout", a.timeout, "the timeout for each request to the Notion API")
+}
+
+func (a *app) usage() {
+	fmt.Fprint(a.stderr, "Usage: gotion [flags] <command> [subcommand] [flags] [arguments]\n\nCommands:\n")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		subcommands := make([]string, 0, len(commands[name]))
+		for sub := range commands[name] {
+			subcommands = append(subcommands, sub)
+		}
+		sort.Strings(subcommands)
+		for _, sub := range subcommands {
+			fmt.Fprintf(a.stderr, "  %s\n", strings.Join(strings.Fields(name+" "+sub+" "+commands[name][sub].usage), " "))
+		}
+	}
+	fmt.Fprint(a.stderr, "\nFlags:\n")
+	fs := flag.NewFlagSet("gotion", flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	a.commonFlags(fs)
+	fs.PrintDefaults()
+}
+
+// parse parses the flags of a command, and checks that the output format is known and that there are
+// between min and max arguments.
+func (a *app) parse(fs *flag.FlagSet, args []string, min, max int) ([]string, error) {
+	if err := fs.Parse(args); err != nil {
+		return nil, errUsage
+	}
+	switch a.format {
+	case formatJSON, formatTable, formatMarkdown:
+	default:
+		return nil, fmt.Errorf("unknown output format %q: use json, table, or markdown", a.format)
+	}
+	if fs.NArg() < min || fs.NArg() > max {
+		fs.Usage()
+		return nil, errUsage
+	}
+	return fs.Args(), nil
+}
+
+// connect returns the client for the settings from the flags, the environment, and the configuration file.
+func (a *app) connect() (*gotion.Client, error) {
+	if a.client != nil {
+		return a.client, nil
+	}
+
+	cfg, err := loadConfig(a.configPath)
+	if err != nil {
+		return nil, err
+	}
+	if a.apiVersion != "" {
+		cfg.Version = a.apiVersion
+	}
+	if a.baseURL != "" {
+		cfg.BaseURL = a.baseURL
+	}
+	settings, err := cfg.settings()
+	if err != nil {
+		return nil, err
+	}
+
+	options := []gotion.Option{gotion.WithSettings(settings), gotion.WithTimeout(a.timeout)}
+	if cfg.BaseURL != "" {
+		options = append(options, gotion.WithBaseURL(cfg.BaseURL))
+	}
+	a.client = gotion.NewClient(settings.APIKey, options...)
+	return a.client, nil
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/thedadams/gotion/gotiontest"
+	"github.com/thedadams/gotion/notion"
+)
+
+// setenv sets the environment variable for the test, and restores it after. An empty value unsets it.
+func setenv(t *testing.T, key, value string) {
+	t.Helper()
+	old, ok := os.LookupEnv(key)
+	t.Cleanup(func() {
+		if ok {
+			os.Setenv(key, old)
+		} else {
+			os.Unsetenv(key)
+		}
+	})
+	if value == "" {
+		os.Unsetenv(key)
+	} else {
+		os.Setenv(key, value)
+	}
+}
+
+// isolate clears the environment of gotion, and moves the user's configuration directory to an empty one.
+func isolate(t *testing.T) {
+	t.Helper()
+	for _, key := range []string{"NOTION_API_KEY", "NOTION_VERSION", "NOTION_BASE_URL", "GOTION_CONFIG"} {
+		setenv(t, key, "")
+	}
+	dir := t.TempDir()
+	setenv(t, "HOME", dir)
+	setenv(t, "XDG_CONFIG_HOME", dir)
+}
+
+// runApp runs gotion with the arguments, and returns what it printed.
+func runApp(args ...string) (stdout, stderr string, err error) {
+	var out, errOut bytes.Buffer
+	a := &app{stdin: strings.NewReader(""), stdout: &out, stderr: &errOut, format: formatJSON}
+	err = a.run(context.Background(), args)
+	return out.String(), errOut.String(), err
+}
+
+func TestRunUsage(t *testing.T) {
+	isolate(t)
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "no command"},
+		{name: "unknown command", args: []string{"pages"}},
+		{name: "no subcommand", args: []string{"page"}},
+		{name: "unknown subcommand", args: []string{"page", "delete"}},
+		{name: "unknown flag", args: []string{"-verbose", "search"}},
+		{name: "unknown flag of a command", args: []string{"users", "ls", "-depth", "1"}},
+		{name: "too few arguments", args: []string{"users", "get"}},
+		{name: "too many arguments", args: []string{"users", "get", "a", "b"}},
+		{name: "flag after the arguments", args: []string{"users", "get", "a", "-o", "table"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr, err := runApp(tt.args...)
+			if err != errUsage {
+				t.Errorf("got error %v, want a usage error", err)
+			}
+			if stdout != "" || !strings.Contains(stderr, "Usage: gotion") {
+				t.Errorf("got output %q and %q, want only the usage", stdout, stderr)
+			}
+		})
+	}
+
+	if _, _, err := runApp("-o", "yaml", "users", "ls"); err == nil || err == errUsage || !strings.Contains(err.Error(), `"yaml"`) {
+		t.Errorf("got error %v, want an error for the unknown output format", err)
+	}
+}
+
+func TestRunFlags(t *testing.T) {
+	isolate(t)
+	s := gotiontest.NewServer()
+	defer s.Close()
+	if _, err := s.AddUser(&notion.User{Name: "Ada", Type: notion.UserTypeEnumPerson}); err != nil {
+		t.Fatal(err)
+	}
+	setenv(t, "NOTION_API_KEY", s.Token)
+	setenv(t, "NOTION_BASE_URL", s.URL)
+
+	// The common flags are accepted before and after the command.
+	for _, args := range [][]string{
+		{"-o", "table", "users", "ls"},
+		{"users", "ls", "-o", "table"},
+		{"-o", "json", "users", "ls", "-o", "table"},
+	} {
+		stdout, _, err := runApp(args...)
+		if err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+		if !strings.HasPrefix(stdout, "ID ") || !strings.Contains(stdout, "Ada") {
+			t.Errorf("%v: got %q, want a table of the users", args, stdout)
+		}
+	}
+
+	stdout, _, err := runApp("users", "ls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(stdout, "[") {
+		t.Errorf("got %q, want JSON by default", stdout)
+	}
+}
+
+func TestSortFlag(t *testing.T) {
+	var sorts sortFlag
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(&sorts, "sort", "")
+	if err := fs.Parse([]string{"-sort", "Due", "-sort", "-last_edited_time", "-sort", "-Points"}); err != nil {
+		t.Fatal(err)
+	}
+
+	built, err := sorts.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := notion.NewSortBuilder().
+		Property("Due", notion.SortDirectionEnumAscending).
+		Timestamp(notion.SortTimestampEnumLastEditedTime, notion.SortDirectionEnumDescending).
+		Property("Points", notion.SortDirectionEnumDescending).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(built, want) {
+		t.Errorf("got sorts %+v, want %+v", built, want)
+	}
+
+	if built, err := (sortFlag{}).Build(); err != nil || built != nil {
+		t.Errorf("got %v and error %v, want no sorts without the flag", built, err)
+	}
+}
+
+func TestRenameFlag(t *testing.T) {
+	var renames renameFlag
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(new(bytes.Buffer))
+	fs.Var(&renames, "rename", "")
+	if err := fs.Parse([]string{"-rename", "Owner=Assignee", "-rename", "Due=Due date=later"}); err != nil {
+		t.Fatal(err)
+	}
+	want := renameFlag{"Owner": "Assignee", "Due": "Due date=later"}
+	if !reflect.DeepEqual(renames, want) {
+		t.Errorf("got %v, want %v", renames, want)
+	}
+
+	for _, arg := range []string{"Owner", "=Assignee", "Owner="} {
+		if err := fs.Parse([]string{"-rename", arg}); err == nil {
+			t.Errorf("got no error for -rename %s", arg)
+		}
+	}
+}
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/thedadams/gotion"
+	"github.com/thedadams/gotion/export"
+	"github.com/thedadams/gotion/markdown"
+	"github.com/thedadams/gotion/notion"
+	"github.com/thedadams/gotion/richtext"
+)
+
+// These are the output formats of gotion.
+const (
+	formatJSON     = "json"
+	formatTable    = "table"
+	formatMarkdown = "markdown"
+)
+
+// A view is the result of a command, which can be printed in each of the output formats.
+type view struct {
+	// value is printed as JSON.
+	value interface{}
+	// table is printed as an aligned table, or as a Markdown table if there is no markdown function.
+	table func() *table
+	// markdown writes the result as a Markdown document.
+	markdown func(io.Writer) error
+	// text, if it is set, writes the result for both the table and Markdown formats.
+	text func(io.Writer) error
+}
+
+// A table is the result of a command as rows of cells.
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+func (t *table) add(cells ...string) {
+	t.rows = append(t.rows, cells)
+}
+
+// print writes the view in the output format.
+func (a *app) print(v view) error {
+	switch {
+	case a.format == formatJSON:
+		enc := json.NewEncoder(a.stdout)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v.value)
+	case v.text != nil:
+		return v.text(a.stdout)
+	case a.format == formatMarkdown && v.markdown != nil:
+		return v.markdown(a.stdout)
+	case a.format == formatMarkdown:
+		return writeMarkdownTable(a.stdout, v.table())
+	default:
+		return writeTable(a.stdout, v.table())
+	}
+}
+
+func writeTable(w io.Writer, t *table) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, row := range append([][]string{t.header}, t.rows...) {
+		cells := make([]string, 0, len(row))
+		for _, cell := range row {
+			cells = append(cells, strings.Join(strings.Fields(cell), " "))
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+func writeMarkdownTable(w io.Writer, t *table) error {
+	var sb strings.Builder
+	line := func(cells []string) {
+		sb.WriteString("|")
+		for _, cell := range cells {
+			cell = strings.Join(strings.Fields(cell), " ")
+			sb.WriteString(" " + strings.ReplaceAll(cell, "|", `\|`) + " |")
+		}
+		sb.WriteString("\n")
+	}
+
+	line(t.header)
+	sb.WriteString("|" + strings.Repeat(" --- |", len(t.header)) + "\n")
+	for _, row := range t.rows {
+		line(row)
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// pageView shows a page as its properties, or as a Markdown document with the title and the children of the page.
+func pageView(page *notion.Page) view {
+	return view{
+		value: page,
+		table: func() *table {
+			t := &table{header: []string{"PROPERTY", "TYPE", "VALUE"}}
+			props := make(notion.PageProperties, 0, len(page.Properties))
+			for _, prop := range page.Properties {
+				if prop != nil {
+					props = append(props, prop)
+				}
+			}
+			sortProperties(props)
+			e := export.New(nil)
+			for _, prop := range props {
+				t.add(prop.Name, string(prop.Type), cell(e.Value(prop)))
+			}
+			return t
+		},
+		markdown: func(w io.Writer) error {
+			return markdown.RenderPage(w, page)
+		},
+	}
+}
+
+// pagesView shows the pages of a database with a column for each property of the database, in the order of an export.
+func pagesView(db *notion.Database, pages []*notion.Page) view {
+	return view{
+		value: pages,
+		table: func() *table {
+			columns := export.Columns(db)
+			t := &table{header: []string{"ID"}}
+			for _, col := range columns {
+				t.header = append(t.header, col.Name)
+			}
+			e := export.New(nil)
+			for _, page := range pages {
+				row := []string{page.ID.String()}
+				for _, col := range columns {
+					prop := page.Get(col.Name)
+					if prop == nil && col.Type == notion.DatabasePropertyTypeEnumTitle {
+						// The title is also looked up by the name "title", which the Notion API accepts for the title of any page.
+						prop = page.Get(notion.DatabasePropertyTypeEnumTitle)
+					}
+					row = append(row, cell(e.Value(prop)))
+				}
+				t.add(row...)
+			}
+			return t
+		},
+	}
+}
+
+func databaseView(db *notion.Database) view {
+	return view{
+		value: db,
+		table: func() *table {
+			t := &table{header: []string{"PROPERTY", "TYPE", "ID"}}
+			for _, prop := range export.Columns(db) {
+				t.add(prop.Name, string(prop.Type), prop.ID)
+			}
+			return t
+		},
+	}
+}
+
+func databasesView(dbs []*notion.Database) view {
+	return view{
+		value: dbs,
+		table: func() *table {
+			t := &table{header: []string{"ID", "TITLE", "LAST EDITED", "URL"}}
+			for _, db := range dbs {
+				t.add(db.ID.String(), notion.PlainText(db.Title), timestamp(db.LastEditedTime), urlString((*url.URL)(db.URL)))
+			}
+			return t
+		},
+	}
+}
+
+// blocksView shows blocks as their types and text, or as a Markdown document.
+func blocksView(blocks []*notion.Block) view {
+	return view{
+		value: blocks,
+		table: func() *table {
+			t := &table{header: []string{"ID", "TYPE", "CHILDREN", "TEXT"}}
+			var add func(blocks []*notion.Block, indent string)
+			add = func(blocks []*notion.Block, indent string) {
+				for _, b := range blocks {
+					text := richtext.PlainText(b.Text)
+					if text == "" {
+						text = b.GetTitle()
+					}
+					t.add(b.ID.String(), indent+string(b.Type), strconv.FormatBool(b.HasChildren), text)
+					add(b.Children, indent+"  ")
+				}
+			}
+			add(blocks, "")
+			return t
+		},
+		markdown: func(w io.Writer) error {
+			return markdown.RenderBlocks(w, blocks)
+		},
+	}
+}
+
+func searchView(results []*gotion.SearchResult) view {
+	return view{
+		value: results,
+		table: func() *table {
+			t := &table{header: []string{"OBJECT", "ID", "TITLE", "LAST EDITED", "URL"}}
+			for _, r := range results {
+				if r.Page != nil {
+					title, _ := r.Page.Title()
+					t.add("page", r.Page.ID.String(), title, timestamp(r.Page.LastEditedTime), urlString((*url.URL)(r.Page.URL)))
+				} else if r.Database != nil {
+					t.add("database", r.Database.ID.String(), notion.PlainText(r.Database.Title), timestamp(r.Database.LastEditedTime),
+						urlString((*url.URL)(r.Database.URL)))
+				}
+			}
+			return t
+		},
+	}
+}
+
+func usersView(users []*notion.User) view {
+	return view{
+		value: users,
+		table: func() *table {
+			t := &table{header: []string{"ID", "NAME", "TYPE", "EMAIL"}}
+			for _, u := range users {
+				t.add(u.ID.String(), u.Name, string(u.Type), u.Email)
+			}
+			return t
+		},
+	}
+}
+
+func idsView(ids []string) view {
+	return view{
+		value: ids,
+		table: func() *table {
+			t := &table{header: []string{"ID"}}
+			for _, id := range ids {
+				t.add(id)
+			}
+			return t
+		},
+	}
+}
+
+// cell returns a value from export.Value as the text of a cell.
+func cell(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case []string:
+		return export.JoinList(v, export.DefaultListSeparator)
+	case []interface{}:
+		cells := make([]string, 0, len(v))
+		for _, value := range v {
+			cells = append(cells, cell(value))
+		}
+		return export.JoinList(cells, export.DefaultListSeparator)
+	}
+	return ""
+}
+
+// sortProperties sorts the properties as the columns of an export: the title first, followed by the other properties by name.
+func sortProperties(props notion.PageProperties) {
+	sort.SliceStable(props, func(i, j int) bool {
+		if ti, tj := props[i].Type == notion.DatabasePropertyTypeEnumTitle, props[j].Type == notion.DatabasePropertyTypeEnumTitle; ti != tj {
+			return ti
+		}
+		return props[i].Name < props[j].Name
+	})
+}
+
+func timestamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func urlString(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	return u.String()
+}
@@ -5,41 +5,70 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/thedadams/gotion/notion"
 )
 
 // DBQuery represents the parameters needed to query a database in the Notion API.
+// The Filter can be a single *notion.Filter or a, possibly nested, *notion.CompoundFilter.
+// The Sorts are applied in order, so earlier sorts take precedence. A notion.SortBuilder can be used to build them.
 type DBQuery struct {
-	Filter     *notion.Filter `json:"filter,omitempty"`
-	Sort       *notion.Sort   `json:"sort,omitempty"`
-	Cursor     *string        `json:"cursor,omitempty"`
-	MaxResults *int           `json:"page_size,omitempty"`
+	Filter     notion.QueryFilter `json:"filter,omitempty"`
+	Sorts      []*notion.Sort     `json:"sorts,omitempty"`
+	Cursor     *string            `json:"start_cursor,omitempty"`
+	MaxResults *int               `json:"page_size,omitempty"`
 }
 
 func (db *DBQuery) setPage(cursor *string, maxResults int) ([]byte, error) {
-	oldCursor, oldPageSize := db.Cursor, db.MaxResults
+	oldCursor, oldPageSize, oldFilter := db.Cursor, db.MaxResults, db.Filter
 	defer func() {
 		db.Cursor = oldCursor
 		db.MaxResults = oldPageSize
+		db.Filter = oldFilter
 	}()
 
 	db.Cursor, db.MaxResults = cursor, &maxResults
+	if isNilFilter(db.Filter) {
+		db.Filter = nil
+	}
 	return json.Marshal(db)
 }
 
+// isNilFilter returns true if the filter is nil, or holds a nil pointer, which omitempty doesn't leave out.
+func isNilFilter(f notion.QueryFilter) bool {
+	switch f := f.(type) {
+	case nil:
+		return true
+	case *notion.Filter:
+		return f == nil
+	case *notion.CompoundFilter:
+		return f == nil
+	case notion.RawFilter:
+		return f == nil
+	}
+	return false
+}
+
 func (db *DBQuery) getCursor() *string {
 	return db.Cursor
 }
 
 func (db *DBQuery) getMaxResults() int {
+	if db.MaxResults == nil {
+		return -1
+	}
 	return *db.MaxResults
 }
 
 // QueryDatabase will  query the database with the given id in the Notion API.
 func (c *Client) QueryDatabase(ctx context.Context, id string, query *DBQuery) ([]*notion.Page, error) {
+	if query == nil {
+		query = &DBQuery{}
+	}
 	var results notion.Pages
-	if err := c.queryForList(ctx, fmt.Sprintf("%s/v1/databases/%s/query", apiBaseURL, id), query, &results); err != nil {
+	if err := c.queryForList(ctx, fmt.Sprintf("%s/v1/databases/%s/query", c.baseURL, id), query, &results); err != nil {
 		return nil, err
 	}
 	return results, nil
@@ -47,7 +76,7 @@ func (c *Client) QueryDatabase(ctx context.Context, id string, query *DBQuery) (
 
 // CreateDatabase will send a request to create the given database in the Notion API.
 // All that is needed in the notion.Database object are the Parent and Properties.
-// Optionally, a Title can be set.
+// Optionally, a Title, Icon, and Cover can be set.
 // No IDs need to be given.
 // On success, the notion.Database will be the complete page from the Notion API.
 // On error, the notion.Page will not be changed.
@@ -59,11 +88,13 @@ func (c *Client) CreateDatabase(ctx context.Context, db *notion.Database) error
 	if len(db.Title) != 0 {
 		body["title"] = &db.Title
 	}
+	addIcons(body, &db.Icons)
 
-	return c.createObject(ctx, fmt.Sprintf("%s/v1/databases", apiBaseURL), body, db)
+	return c.createObject(ctx, fmt.Sprintf("%s/v1/databases", c.baseURL), body, db)
 }
 
 // UpdateDatabase updates the database in the Notion API.
+// The icon and cover are only updated if they are not nil. An icon or cover without a type is removed.
 // On success, the database is the complete database from the Notion API.
 // On error, the database is not updated.
 func (c *Client) UpdateDatabase(ctx context.Context, db *notion.Database) error {
@@ -71,14 +102,92 @@ func (c *Client) UpdateDatabase(ctx context.Context, db *notion.Database) error
 		"title":      db.Title,
 		"properties": db.Properties,
 	}
+	addIcons(body, &db.Icons)
+
+	return c.updateObject(ctx, fmt.Sprintf("%s/v1/databases/%s", c.baseURL, db.ID.String()), body, db)
+}
+
+// An ApplyOption customizes how ApplySchema applies a diff.
+type ApplyOption func(*applyOptions)
+
+type applyOptions struct {
+	allowRemovals, allowRetypes bool
+}
+
+// AllowRemovals lets ApplySchema remove the properties that a diff removes, along with their values in every page of the database.
+func AllowRemovals() ApplyOption {
+	return func(o *applyOptions) {
+		o.allowRemovals = true
+	}
+}
 
-	return c.updateObject(ctx, fmt.Sprintf("%s/v1/databases/%s", apiBaseURL, db.ID), body, db)
+// AllowRetypes lets ApplySchema change the types of the properties that a diff retypes.
+// The Notion API converts the values of those properties, and values that cannot be converted are lost.
+func AllowRetypes() ApplyOption {
+	return func(o *applyOptions) {
+		o.allowRetypes = true
+	}
+}
+
+// ApplySchema changes the properties of the database with the given id in the Notion API, so that they match the schema that the diff is to.
+// The diff should be from the current properties of the database, and is applied with one request for each of its updates.
+// If dryRun is true, then no requests are made. In either case, the updates are returned.
+// If a request fails, then the updates before it have been applied.
+//
+// A diff that removes properties is an error, even in a dry run, unless the AllowRemovals option is given,
+// because the values of those properties are lost. A property that has another name in the database that the diff is to
+// should be given as a rename hint to notion.DiffSchemaWithRenames instead.
+// Likewise, a diff that changes the types of properties is an error unless the AllowRetypes option is given.
+func (c *Client) ApplySchema(
+	ctx context.Context, id string, diff *notion.SchemaDiff, dryRun bool, options ...ApplyOption,
+) ([]notion.SchemaUpdate, error) {
+	opts := new(applyOptions)
+	for _, o := range options {
+		o(opts)
+	}
+
+	updates, err := diff.Updates()
+	if err == nil {
+		err = opts.check(diff)
+	}
+	if err != nil || dryRun {
+		return updates, err
+	}
+
+	for _, update := range updates {
+		body := map[string]interface{}{"properties": update}
+		if err := c.updateObject(ctx, fmt.Sprintf("%s/v1/databases/%s", c.baseURL, id), body, new(notion.Database)); err != nil {
+			return updates, err
+		}
+	}
+	return updates, nil
+}
+
+// check returns an error if the diff removes or retypes properties, and that is not allowed.
+func (o *applyOptions) check(diff *notion.SchemaDiff) error {
+	if !o.allowRemovals && len(diff.Removed) != 0 {
+		names := make([]string, 0, len(diff.Removed))
+		for _, p := range diff.Removed {
+			names = append(names, strconv.Quote(p.Name))
+		}
+		return fmt.Errorf("the schema diff removes the properties %s and their values: "+
+			"allow removals, or give rename hints for renamed properties", strings.Join(names, ", "))
+	}
+	if !o.allowRetypes && len(diff.Retyped) != 0 {
+		changes := make([]string, 0, len(diff.Retyped))
+		for _, pc := range diff.Retyped {
+			changes = append(changes, fmt.Sprintf("%q from %s to %s", pc.To.Name, pc.From.Type, pc.To.Type))
+		}
+		return fmt.Errorf("the schema diff retypes the properties %s, which can lose their values: allow retypes",
+			strings.Join(changes, ", "))
+	}
+	return nil
 }
 
 // GetDatabase gets a database with the given id from the Notion API.
 func (c *Client) GetDatabase(ctx context.Context, id string) (*notion.Database, error) {
 	db := &notion.Database{}
-	err := c.makeRequest(ctx, http.MethodGet, fmt.Sprintf("%s/v1/databases/%s", apiBaseURL, id), nil, db)
+	err := c.makeRequest(ctx, http.MethodGet, fmt.Sprintf("%s/v1/databases/%s", c.baseURL, id), nil, db)
 	if err != nil {
 		db = nil
 	}
@@ -100,8 +209,8 @@ func (c *Client) GetDatabaseAndChildren(ctx context.Context, id string, maxResul
 // GetDatabases gets a number of databases with from the Notion API.
 // If `maxResults < 0`, then all databases are retrieved.
 func (c *Client) GetDatabases(ctx context.Context, cursor *string, maxResults int) (*notion.Databases, error) {
-	var results *notion.Databases
-	if err := c.getList(ctx, fmt.Sprintf("%s/v1/databases", apiBaseURL), cursor, maxResults, results); err != nil {
+	results := new(notion.Databases)
+	if err := c.getList(ctx, fmt.Sprintf("%s/v1/databases", c.baseURL), cursor, maxResults, results); err != nil {
 		return nil, err
 	}
 
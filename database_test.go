@@ -0,0 +1,52 @@
+package gotion
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/thedadams/gotion/notion"
+)
+
+func TestDBQuerySetPage(t *testing.T) {
+	cursor := "8c7b4f5e-7a53-4e32-8a3b-53e0aa0b4c5d"
+	sort, err := notion.NewPropertySort("Name", notion.SortDirectionEnumAscending)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		query      *DBQuery
+		cursor     *string
+		maxResults int
+		want       string
+	}{
+		{name: "empty", query: &DBQuery{}, maxResults: 100, want: `{"page_size":100}`},
+		{name: "cursor", query: &DBQuery{}, cursor: &cursor, maxResults: 10, want: `{"start_cursor":"` + cursor + `","page_size":10}`},
+		{name: "nil filter", query: &DBQuery{Filter: (*notion.Filter)(nil)}, maxResults: 100, want: `{"page_size":100}`},
+		{name: "nil compound filter", query: &DBQuery{Filter: (*notion.CompoundFilter)(nil)}, maxResults: 100, want: `{"page_size":100}`},
+		{name: "nil raw filter", query: &DBQuery{Filter: notion.RawFilter(nil)}, maxResults: 100, want: `{"page_size":100}`},
+		{
+			name:       "filter and sorts",
+			query:      &DBQuery{Filter: notion.RawFilter(`{"property":"Done","checkbox":{"equals":true}}`), Sorts: []*notion.Sort{sort}},
+			maxResults: 100,
+			want:       `{"filter":{"property":"Done","checkbox":{"equals":true}},"sorts":[{"property":"Name","direction":"ascending"}],"page_size":100}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := tt.query.Filter
+			b, err := tt.query.setPage(tt.cursor, tt.maxResults)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != tt.want {
+				t.Errorf("got %s, want %s", b, tt.want)
+			}
+			if !reflect.DeepEqual(tt.query.Filter, filter) || tt.query.Cursor != nil || tt.query.MaxResults != nil {
+				t.Error("the query was changed")
+			}
+		})
+	}
+}
@@ -0,0 +1,415 @@
+// Package export streams the pages of a database in the Notion API to CSV or JSON Lines.
+//
+// There is one column for each property of the database: the title first, followed by the other properties by name,
+// so that the columns are the same from one export to the next. The values of the properties are flattened:
+// multi-selects, people, relations, and files are lists of names, ids, and URLs, and formulas and rollups are their values.
+//
+//	err := export.New(client, export.WithDateFormat("02/01/2006")).CSV(ctx, w, databaseID, nil)
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/thedadams/gotion"
+	"github.com/thedadams/gotion/notion"
+)
+
+// These are the default formats of the values of dates.
+const (
+	DefaultDateFormat     = "2006-01-02"
+	DefaultDateTimeFormat = time.RFC3339
+	// DefaultListSeparator is also the default of the importer package, so that exported lists can be imported again.
+	DefaultListSeparator = ", "
+)
+
+// An Option is a way of customizing an Exporter.
+type Option func(*Exporter)
+
+// WithDateFormat sets the layout, as in time.Format, of dates without a time. The default is DefaultDateFormat.
+func WithDateFormat(layout string) Option {
+	return func(e *Exporter) {
+		e.dateFormat = layout
+	}
+}
+
+// WithDateTimeFormat sets the layout, as in time.Format, of dates with a time, and of the created and last edited times of pages.
+// The default is DefaultDateTimeFormat.
+func WithDateTimeFormat(layout string) Option {
+	return func(e *Exporter) {
+		e.dateTimeFormat = layout
+	}
+}
+
+// WithLocation converts the times of dates to the given location before they are formatted.
+func WithLocation(loc *time.Location) Option {
+	return func(e *Exporter) {
+		e.location = loc
+	}
+}
+
+// WithListSeparator sets the separator of the values of lists, like multi-selects, in CSV. The default is DefaultListSeparator.
+// Values that contain the separator are quoted, as described in JoinList. In JSON Lines, lists are arrays.
+func WithListSeparator(sep string) Option {
+	return func(e *Exporter) {
+		e.separator = sep
+	}
+}
+
+// WithIDColumn adds a column with the given name, before the properties, holding the ids of the pages.
+func WithIDColumn(name string) Option {
+	return func(e *Exporter) {
+		e.idColumn = name
+	}
+}
+
+// An Exporter exports the pages of databases in the Notion API.
+type Exporter struct {
+	client         *gotion.Client
+	dateFormat     string
+	dateTimeFormat string
+	location       *time.Location
+	separator      string
+	idColumn       string
+}
+
+// New returns an Exporter that uses the client to query databases.
+func New(c *gotion.Client, options ...Option) *Exporter {
+	e := &Exporter{
+		client:         c,
+		dateFormat:     DefaultDateFormat,
+		dateTimeFormat: DefaultDateTimeFormat,
+		separator:      DefaultListSeparator,
+	}
+	for _, opt := range options {
+		opt(e)
+	}
+	return e
+}
+
+// CSV writes the pages of the database with the given id that match the query as CSV, with a header row of the property names.
+// The query can be nil to export all pages. Pages are written as they are received from the Notion API.
+func (e *Exporter) CSV(ctx context.Context, w io.Writer, databaseID string, query *gotion.DBQuery) error {
+	cw := csv.NewWriter(w)
+	err := e.export(ctx, databaseID, query, func(columns []string) error {
+		return cw.Write(columns)
+	}, func(id string, values []interface{}) error {
+		record := make([]string, 0, len(values)+1)
+		if e.idColumn != "" {
+			record = append(record, id)
+		}
+		for _, v := range values {
+			record = append(record, e.cell(v))
+		}
+		return cw.Write(record)
+	})
+	cw.Flush()
+	if err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// JSONL writes the pages of the database with the given id that match the query as JSON Lines: one JSON object per page,
+// with the property names as keys, in the same order as the CSV columns. Empty values are null.
+// The query can be nil to export all pages. Pages are written as they are received from the Notion API.
+func (e *Exporter) JSONL(ctx context.Context, w io.Writer, databaseID string, query *gotion.DBQuery) error {
+	var keys [][]byte
+	return e.export(ctx, databaseID, query, func(columns []string) error {
+		for _, c := range columns {
+			key, err := json.Marshal(c)
+			if err != nil {
+				return err
+			}
+			keys = append(keys, key)
+		}
+		return nil
+	}, func(id string, values []interface{}) error {
+		if e.idColumn != "" {
+			values = append([]interface{}{id}, values...)
+		}
+
+		var buf bytes.Buffer
+		buf.WriteByte('{')
+		for i, v := range values {
+			if i != 0 {
+				buf.WriteByte(',')
+			}
+			b, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			buf.Write(keys[i])
+			buf.WriteByte(':')
+			buf.Write(b)
+		}
+		buf.WriteString("}\n")
+		_, err := w.Write(buf.Bytes())
+		return err
+	})
+}
+
+// export gets the schema of the database, calls header with the columns, and then row with the values of each page.
+func (e *Exporter) export(
+	ctx context.Context, databaseID string, query *gotion.DBQuery, header func([]string) error, row func(string, []interface{}) error,
+) error {
+	db, err := e.client.GetDatabase(ctx, databaseID)
+	if err != nil {
+		return err
+	}
+
+	props := Columns(db)
+	columns := make([]string, 0, len(props)+1)
+	if e.idColumn != "" {
+		columns = append(columns, e.idColumn)
+	}
+	for _, prop := range props {
+		columns = append(columns, prop.Name)
+	}
+	if err := header(columns); err != nil {
+		return err
+	}
+
+	it := e.client.IterateDatabase(databaseID, query)
+	for it.Next(ctx) {
+		page := it.Value()
+		values := make([]interface{}, 0, len(props))
+		for _, prop := range props {
+			values = append(values, e.Value(page.Get(prop.Name)))
+		}
+		if err := row(page.ID.String(), values); err != nil {
+			return err
+		}
+	}
+	return it.Err()
+}
+
+// Columns returns the properties of the database in the order of the columns of an export:
+// the title first, followed by the other properties by name.
+func Columns(db *notion.Database) notion.DatabaseProperties {
+	props := make(notion.DatabaseProperties, 0, len(db.Properties))
+	for _, prop := range db.Properties {
+		if prop != nil {
+			props = append(props, prop)
+		}
+	}
+	sort.SliceStable(props, func(i, j int) bool {
+		if ti, tj := props[i].Type == notion.DatabasePropertyTypeEnumTitle, props[j].Type == notion.DatabasePropertyTypeEnumTitle; ti != tj {
+			return ti
+		}
+		return props[i].Name < props[j].Name
+	})
+	return props
+}
+
+// Value returns the flattened value of the page property: a string, float64, bool, or a slice of them,
+// or nil if the property is nil or empty. Dates are formatted strings.
+func (e *Exporter) Value(prop *notion.PageProperty) interface{} {
+	if prop == nil {
+		return nil
+	}
+
+	switch prop.Type {
+	case notion.DatabasePropertyTypeEnumTitle:
+		return nonEmpty(notion.PlainText(prop.Title))
+	case notion.DatabasePropertyTypeEnumRichText:
+		return nonEmpty(notion.PlainText(prop.RichText))
+	case notion.DatabasePropertyTypeEnumNumber:
+		if prop.Number != nil {
+			return *prop.Number
+		}
+	case notion.DatabasePropertyTypeEnumSelect:
+		if prop.Select != nil {
+			return prop.Select.Name
+		}
+	case notion.DatabasePropertyTypeEnumMultiSelect:
+		names := make([]string, 0, len(prop.MultiSelect))
+		for _, option := range prop.MultiSelect {
+			names = append(names, option.Name)
+		}
+		return list(names)
+	case notion.DatabasePropertyTypeEnumDate:
+		return e.date(prop.Date)
+	case notion.DatabasePropertyTypeEnumPeople:
+		names := make([]string, 0, len(prop.People))
+		for _, u := range prop.People {
+			if name := userName(u); name != "" {
+				names = append(names, name)
+			}
+		}
+		return list(names)
+	case notion.DatabasePropertyTypeEnumFile:
+		urls := make([]string, 0, len(prop.Files))
+		for _, f := range prop.Files {
+			if u := f.GetURL(); u != nil {
+				urls = append(urls, u.String())
+			}
+		}
+		return list(urls)
+	case notion.DatabasePropertyTypeEnumCheckbox:
+		return prop.Checkbox != nil && *prop.Checkbox
+	case notion.DatabasePropertyTypeEnumURL:
+		if u := (*url.URL)(prop.URL); u != nil {
+			return u.String()
+		}
+	case notion.DatabasePropertyTypeEnumEmail:
+		if prop.Email != nil {
+			return prop.Email.Address
+		}
+	case notion.DatabasePropertyTypeEnumPhoneNumber:
+		if prop.PhoneNumber != nil && *prop.PhoneNumber != "" {
+			return *prop.PhoneNumber
+		}
+	case notion.DatabasePropertyTypeEnumFormula:
+		return e.formula(prop.Formula)
+	case notion.DatabasePropertyTypeEnumRelation:
+		ids := make([]string, 0, len(prop.Relations))
+		for _, rid := range prop.Relations {
+			if rid != nil {
+				id := notion.UUID4(*rid)
+				ids = append(ids, id.String())
+			}
+		}
+		return list(ids)
+	case notion.DatabasePropertyTypeEnumRollup:
+		return e.rollup(prop.Rollup)
+	case notion.DatabasePropertyTypeEnumCreatedTime:
+		return e.time(prop.CreatedTime, true)
+	case notion.DatabasePropertyTypeEnumLastEditedTime:
+		return e.time(prop.LastEditedTime, true)
+	case notion.DatabasePropertyTypeEnumCreatedBy:
+		return nonEmpty(userName(prop.CreatedBy))
+	case notion.DatabasePropertyTypeEnumLastEditedBy:
+		return nonEmpty(userName(prop.LastEditedBy))
+	}
+	return nil
+}
+
+func (e *Exporter) formula(f *notion.Formula) interface{} {
+	if f == nil {
+		return nil
+	}
+	switch {
+	case f.String != nil:
+		return nonEmpty(*f.String)
+	case f.Number != nil:
+		return *f.Number
+	case f.Boolean != nil:
+		return *f.Boolean
+	case f.Date != nil:
+		return e.date(f.Date)
+	}
+	return nil
+}
+
+// rollup returns the value of the rollup. The values of an array rollup are flattened into one list.
+func (e *Exporter) rollup(r *notion.RollupValue) interface{} {
+	if r == nil {
+		return nil
+	}
+	switch {
+	case r.Number != nil:
+		return *r.Number
+	case r.Date != nil:
+		return e.date(r.Date)
+	}
+
+	var values []interface{}
+	for _, prop := range r.Array {
+		switch v := e.Value(prop).(type) {
+		case nil:
+		case []string:
+			for _, s := range v {
+				values = append(values, s)
+			}
+		case []interface{}:
+			values = append(values, v...)
+		default:
+			values = append(values, v)
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
+
+// date formats the date, with the start and end separated by a slash as in ISO 8601 if it is a range.
+func (e *Exporter) date(d *notion.Date) interface{} {
+	if d == nil || d.Start.IsZero() {
+		return nil
+	}
+	s := e.time(d.Start, d.HasTime).(string)
+	if !d.End.IsZero() {
+		s += "/" + e.time(d.End, d.HasTime).(string)
+	}
+	return s
+}
+
+func (e *Exporter) time(t time.Time, hasTime bool) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	if !hasTime {
+		return t.Format(e.dateFormat)
+	}
+	if e.location != nil {
+		t = t.In(e.location)
+	}
+	return t.Format(e.dateTimeFormat)
+}
+
+// cell returns the value as a CSV cell.
+func (e *Exporter) cell(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case []string:
+		return JoinList(v, e.separator)
+	case []interface{}:
+		cells := make([]string, 0, len(v))
+		for _, value := range v {
+			cells = append(cells, e.cell(value))
+		}
+		return JoinList(cells, e.separator)
+	}
+	return ""
+}
+
+// userName returns the name of the user, or its id if the name is not known, as for users that the integration cannot see.
+func userName(u *notion.User) string {
+	if u == nil {
+		return ""
+	}
+	if u.Name != "" {
+		return u.Name
+	}
+	return u.ID.String()
+}
+
+func list(values []string) interface{} {
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
+
+func nonEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,111 @@
+package export_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/thedadams/gotion/export"
+	"github.com/thedadams/gotion/gotiontest"
+	"github.com/thedadams/gotion/notion"
+)
+
+// addTasks adds a database of tasks with one page to the server, and returns the id of the database.
+func addTasks(t *testing.T, s *gotiontest.Server) string {
+	t.Helper()
+	db, err := s.AddDatabase(&notion.Database{Properties: notion.DatabaseProperties{
+		{Name: "Tags", Type: notion.DatabasePropertyTypeEnumMultiSelect},
+		{Name: "Points", Type: notion.DatabasePropertyTypeEnumNumber},
+		{Name: "Due", Type: notion.DatabasePropertyTypeEnumDate},
+		{Name: "Task", Type: notion.DatabasePropertyTypeEnumTitle},
+		{Name: "Done", Type: notion.DatabasePropertyTypeEnumCheckbox},
+		{Name: "Meeting", Type: notion.DatabasePropertyTypeEnumDate},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	props := notion.PageProperties{{Name: "Task", Type: notion.DatabasePropertyTypeEnumTitle}}
+	for _, err := range []error{
+		props.SetTitle("Launch"),
+		props.SetCheckbox("Done", false),
+		props.SetMultiSelect("Tags", "a, b", "c"),
+		props.SetNumber("Points", 2.5),
+		props.SetDate("Due", notion.Date{
+			Start: time.Date(2022, 3, 4, 0, 0, 0, 0, time.UTC),
+			End:   time.Date(2022, 3, 6, 0, 0, 0, 0, time.UTC),
+		}),
+		props.SetDate("Meeting", notion.Date{Start: time.Date(2022, 3, 4, 15, 30, 0, 0, time.UTC), HasTime: true}),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.AddPage(&notion.Page{Parent: notion.Parent{Type: notion.ParentTypeEnumDatabase, ID: db.ID.String()}, Properties: props}); err != nil {
+		t.Fatal(err)
+	}
+	return db.ID.String()
+}
+
+func TestCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []export.Option
+		want    string
+	}{
+		{
+			name: "defaults",
+			want: "Task,Done,Due,Meeting,Points,Tags\n" +
+				"Launch,false,2022-03-04/2022-03-06,2022-03-04T15:30:00Z,2.5,\"\"\"a, b\"\", c\"\n",
+		},
+		{
+			name: "formats",
+			options: []export.Option{
+				export.WithDateFormat("02/01/2006"),
+				export.WithDateTimeFormat("2006-01-02 15:04"),
+				export.WithLocation(time.FixedZone("UTC+2", 2*60*60)),
+				export.WithListSeparator(";"),
+			},
+			want: "Task,Done,Due,Meeting,Points,Tags\n" +
+				"Launch,false,04/03/2022/06/03/2022,2022-03-04 17:30,2.5,\"a, b;c\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := gotiontest.NewServer()
+			defer s.Close()
+			id := addTasks(t, s)
+
+			var buf bytes.Buffer
+			if err := export.New(s.NewClient(), tt.options...).CSV(context.Background(), &buf, id, nil); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONL(t *testing.T) {
+	s := gotiontest.NewServer()
+	defer s.Close()
+	id := addTasks(t, s)
+
+	var buf bytes.Buffer
+	if err := export.New(s.NewClient(), export.WithIDColumn("id")).JSONL(context.Background(), &buf, id, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	pages, err := s.NewClient().QueryDatabase(context.Background(), id, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"id":"` + pages[0].ID.String() + `","Task":"Launch","Done":false,"Due":"2022-03-04/2022-03-06",` +
+		`"Meeting":"2022-03-04T15:30:00Z","Points":2.5,"Tags":["a, b","c"]}` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,77 @@
+package export
+
+import "strings"
+
+// JoinList joins the values of a list, like the options of a multi-select, into one CSV cell.
+// A value that contains the separator, a double quote, or leading or trailing spaces is quoted as in CSV,
+// with its double quotes doubled, so that SplitList returns it unchanged.
+func JoinList(values []string, sep string) string {
+	quoted := make([]string, 0, len(values))
+	for _, v := range values {
+		if needsQuotes(v, sep) {
+			v = `"` + strings.ReplaceAll(v, `"`, `""`) + `"`
+		}
+		quoted = append(quoted, v)
+	}
+	return strings.Join(quoted, sep)
+}
+
+// SplitList splits a CSV cell into the values of a list, undoing JoinList. Values that are not quoted are trimmed,
+// and empty values are dropped. Spaces around the separator are ignored, so that "a,b" and "a, b" are both split into
+// "a" and "b" with the default separator.
+func SplitList(s, sep string) []string {
+	if trimmed := strings.TrimSpace(sep); trimmed != "" {
+		sep = trimmed
+	}
+
+	var values []string
+	for s != "" {
+		s = strings.TrimLeft(s, " \t")
+		var value string
+		if strings.HasPrefix(s, `"`) {
+			value, s = unquote(s)
+			s = strings.TrimLeft(s, " \t")
+		} else if i := strings.Index(s, sep); sep != "" && i >= 0 {
+			value, s = strings.TrimSpace(s[:i]), s[i:]
+		} else {
+			value, s = strings.TrimSpace(s), ""
+		}
+
+		if value != "" {
+			values = append(values, value)
+		}
+		if sep == "" {
+			// Without a separator, the cell is a single value.
+			break
+		}
+		s = strings.TrimPrefix(s, sep)
+	}
+	return values
+}
+
+// needsQuotes returns true if the value has to be quoted to be split unchanged.
+func needsQuotes(v, sep string) bool {
+	if trimmed := strings.TrimSpace(sep); trimmed != "" {
+		sep = trimmed
+	}
+	return (sep != "" && strings.Contains(v, sep)) || strings.HasPrefix(v, `"`) || strings.TrimSpace(v) != v
+}
+
+// unquote returns the value of the quoted string at the start of s, and the rest of s after the closing quote.
+// A string without a closing quote runs to the end of s.
+func unquote(s string) (string, string) {
+	var sb strings.Builder
+	for i := 1; i < len(s); i++ {
+		if s[i] != '"' {
+			sb.WriteByte(s[i])
+			continue
+		}
+		if i+1 < len(s) && s[i+1] == '"' {
+			sb.WriteByte('"')
+			i++
+			continue
+		}
+		return sb.String(), s[i+1:]
+	}
+	return sb.String(), ""
+}
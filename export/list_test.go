@@ -0,0 +1,61 @@
+package export_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/thedadams/gotion/export"
+)
+
+func TestJoinList(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []string
+		sep    string
+		want   string
+	}{
+		{name: "plain", values: []string{"a", "b"}, sep: ", ", want: "a, b"},
+		{name: "separator", values: []string{"a, b", "c"}, sep: ", ", want: `"a, b", c`},
+		{name: "separator without spaces", values: []string{"a,b"}, sep: ", ", want: `"a,b"`},
+		{name: "quote", values: []string{`"quoted" word`, `say "hi"`}, sep: ", ", want: `"""quoted"" word", say "hi"`},
+		{name: "spaces", values: []string{" padded "}, sep: ", ", want: `" padded "`},
+		{name: "other separator", values: []string{"a, b", "c;d"}, sep: ";", want: `a, b;"c;d"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := export.JoinList(tt.values, tt.sep)
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if split := export.SplitList(got, tt.sep); !reflect.DeepEqual(split, tt.values) {
+				t.Errorf("got %q after splitting %q, want %q", split, got, tt.values)
+			}
+		})
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		sep  string
+		want []string
+	}{
+		{name: "empty", s: "", sep: ", "},
+		{name: "without spaces", s: "a,b", sep: ", ", want: []string{"a", "b"}},
+		{name: "extra spaces", s: " a ,  b ", sep: ", ", want: []string{"a", "b"}},
+		{name: "empty values", s: "a,,b,", sep: ", ", want: []string{"a", "b"}},
+		{name: "quoted separator", s: `"a, b", c`, sep: ", ", want: []string{"a, b", "c"}},
+		{name: "unterminated quote", s: `"a, b`, sep: ", ", want: []string{"a, b"}},
+		{name: "no separator", s: "a, b", sep: "", want: []string{"a, b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := export.SplitList(tt.s, tt.sep); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
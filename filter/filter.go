@@ -0,0 +1,513 @@
+// Package filter provides a fluent builder for the filters used to query databases in the Notion API.
+//
+// A filter on a single property is started with Prop, followed by the type of the property and the condition:
+//
+//	done := filter.Prop("Status").Select().Equals("Done")
+//
+// Filters are combined with And and Or, which can be nested to express filters like (A and B) or C:
+//
+//	f := filter.Or(
+//		filter.Prop("Status").Select().Equals("Done").And(filter.Prop("Priority").Number().GreaterThan(2)),
+//		filter.Prop("Pinned").Checkbox().Equals(true),
+//	)
+package filter
+
+import (
+	"time"
+
+	"github.com/thedadams/gotion/notion"
+)
+
+// And returns a compound filter that matches when all the given filters match.
+// Given "and" filters are merged into the returned filter instead of being nested.
+func And(filters ...notion.QueryFilter) *notion.CompoundFilter {
+	cf := &notion.CompoundFilter{And: make([]notion.QueryFilter, 0, len(filters))}
+	for _, f := range filters {
+		if c, ok := f.(*notion.CompoundFilter); ok && c != nil && len(c.Or) == 0 {
+			cf.And = append(cf.And, c.And...)
+		} else {
+			cf.And = append(cf.And, f)
+		}
+	}
+	return cf
+}
+
+// Or returns a compound filter that matches when any of the given filters match.
+// Given "or" filters are merged into the returned filter instead of being nested.
+func Or(filters ...notion.QueryFilter) *notion.CompoundFilter {
+	cf := &notion.CompoundFilter{Or: make([]notion.QueryFilter, 0, len(filters))}
+	for _, f := range filters {
+		if c, ok := f.(*notion.CompoundFilter); ok && c != nil && len(c.And) == 0 {
+			cf.Or = append(cf.Or, c.Or...)
+		} else {
+			cf.Or = append(cf.Or, f)
+		}
+	}
+	return cf
+}
+
+// Object returns a filter on the type of object, page or database, to use when searching the Notion API.
+func Object(t notion.FilterObjectConditionEnum) *notion.Filter {
+	return &notion.Filter{Type: notion.FilterConditionTypeEnumObject, Object: &notion.ObjectFilter{Type: t}}
+}
+
+// A Property is a database property on which to build a filter.
+type Property struct {
+	condition
+}
+
+// Prop starts a filter on the database property with the given name or id.
+func Prop(name string) Property {
+	return Property{condition{property: name}}
+}
+
+// Text returns the conditions for a title, rich text, url, email, or phone number property.
+func (p Property) Text() TextCondition {
+	return TextCondition{p.condition}
+}
+
+// Number returns the conditions for a number property.
+func (p Property) Number() NumberCondition {
+	return NumberCondition{p.condition}
+}
+
+// Checkbox returns the conditions for a checkbox property.
+func (p Property) Checkbox() CheckboxCondition {
+	return CheckboxCondition{p.condition}
+}
+
+// Select returns the conditions for a select property.
+func (p Property) Select() SelectCondition {
+	return SelectCondition{p.condition}
+}
+
+// MultiSelect returns the conditions for a multi-select property.
+func (p Property) MultiSelect() MultiSelectCondition {
+	return MultiSelectCondition{p.condition}
+}
+
+// Date returns the conditions for a date, created time, or last edited time property.
+func (p Property) Date() DateCondition {
+	return DateCondition{p.condition}
+}
+
+// People returns the conditions for a people, created by, or last edited by property.
+func (p Property) People() PeopleCondition {
+	return PeopleCondition{p.condition}
+}
+
+// Files returns the conditions for a files property.
+func (p Property) Files() FilesCondition {
+	return FilesCondition{p.condition}
+}
+
+// Relation returns the conditions for a relation property.
+func (p Property) Relation() RelationCondition {
+	return RelationCondition{p.condition}
+}
+
+// Formula returns the conditions for a formula property, based on the type of its result.
+func (p Property) Formula() FormulaProperty {
+	return FormulaProperty{condition{property: p.property, formula: true}}
+}
+
+// A FormulaProperty is a formula database property on which to build a filter.
+type FormulaProperty struct {
+	condition
+}
+
+// Text returns the conditions for a formula with a text result.
+func (fp FormulaProperty) Text() TextCondition {
+	return TextCondition{fp.condition}
+}
+
+// Number returns the conditions for a formula with a number result.
+func (fp FormulaProperty) Number() NumberCondition {
+	return NumberCondition{fp.condition}
+}
+
+// Checkbox returns the conditions for a formula with a checkbox result.
+func (fp FormulaProperty) Checkbox() CheckboxCondition {
+	return CheckboxCondition{fp.condition}
+}
+
+// Date returns the conditions for a formula with a date result.
+func (fp FormulaProperty) Date() DateCondition {
+	return DateCondition{fp.condition}
+}
+
+// condition holds the property being filtered and whether it is a formula.
+type condition struct {
+	property string
+	formula  bool
+}
+
+func (c condition) newFilter(t notion.FilterConditionTypeEnum) *notion.Filter {
+	return &notion.Filter{Property: notion.DatabaseProperty{Name: c.property}, Type: t}
+}
+
+func (c condition) newFormulaFilter(ff *notion.FormulaFilter) *notion.Filter {
+	f := c.newFilter(notion.FilterConditionTypeEnumFormula)
+	f.Formula = ff
+	return f
+}
+
+// A TextCondition builds filters for text properties.
+type TextCondition struct {
+	condition
+}
+
+func (tc TextCondition) build(t notion.FilterTextConditionEnum, value *string) *notion.Filter {
+	tf := &notion.TextFilter{Type: t, Value: value}
+	if tc.formula {
+		return tc.newFormulaFilter(&notion.FormulaFilter{Type: notion.FilterFormulaConditionEnumText, Text: tf})
+	}
+	f := tc.newFilter(notion.FilterConditionTypeEnumText)
+	f.Text = tf
+	return f
+}
+
+// Equals returns a filter matching text equal to the given value.
+func (tc TextCondition) Equals(value string) *notion.Filter {
+	return tc.build(notion.FilterTextConditionEnumEquals, &value)
+}
+
+// DoesNotEqual returns a filter matching text not equal to the given value.
+func (tc TextCondition) DoesNotEqual(value string) *notion.Filter {
+	return tc.build(notion.FilterTextConditionEnumDoesNotEqual, &value)
+}
+
+// Contains returns a filter matching text containing the given value.
+func (tc TextCondition) Contains(value string) *notion.Filter {
+	return tc.build(notion.FilterTextConditionEnumContains, &value)
+}
+
+// DoesNotContain returns a filter matching text not containing the given value.
+func (tc TextCondition) DoesNotContain(value string) *notion.Filter {
+	return tc.build(notion.FilterTextConditionEnumDoesNotContain, &value)
+}
+
+// StartsWith returns a filter matching text starting with the given value.
+func (tc TextCondition) StartsWith(value string) *notion.Filter {
+	return tc.build(notion.FilterTextConditionEnumStartsWith, &value)
+}
+
+// EndsWith returns a filter matching text ending with the given value.
+func (tc TextCondition) EndsWith(value string) *notion.Filter {
+	return tc.build(notion.FilterTextConditionEnumEndWith, &value)
+}
+
+// IsEmpty returns a filter matching empty text.
+func (tc TextCondition) IsEmpty() *notion.Filter {
+	return tc.build(notion.FilterTextConditionEnumIsEmpty, nil)
+}
+
+// IsNotEmpty returns a filter matching text that is not empty.
+func (tc TextCondition) IsNotEmpty() *notion.Filter {
+	return tc.build(notion.FilterTextConditionEnumIsNotEmpty, nil)
+}
+
+// A NumberCondition builds filters for number properties.
+type NumberCondition struct {
+	condition
+}
+
+func (nc NumberCondition) build(t notion.FilterNumberConditionEnum, value *float64) *notion.Filter {
+	nf := &notion.NumberFilter{Type: t, Value: value}
+	if nc.formula {
+		return nc.newFormulaFilter(&notion.FormulaFilter{Type: notion.FilterFormulaConditionEnumNumber, Number: nf})
+	}
+	f := nc.newFilter(notion.FilterConditionTypeEnumNumber)
+	f.Number = nf
+	return f
+}
+
+// Equals returns a filter matching numbers equal to the given value.
+func (nc NumberCondition) Equals(value float64) *notion.Filter {
+	return nc.build(notion.FilterNumberConditionEnumEquals, &value)
+}
+
+// DoesNotEqual returns a filter matching numbers not equal to the given value.
+func (nc NumberCondition) DoesNotEqual(value float64) *notion.Filter {
+	return nc.build(notion.FilterNumberConditionEnumDoesNotEqual, &value)
+}
+
+// GreaterThan returns a filter matching numbers greater than the given value.
+func (nc NumberCondition) GreaterThan(value float64) *notion.Filter {
+	return nc.build(notion.FilterNumberConditionEnumGreaterThan, &value)
+}
+
+// LessThan returns a filter matching numbers less than the given value.
+func (nc NumberCondition) LessThan(value float64) *notion.Filter {
+	return nc.build(notion.FilterNumberConditionEnumLessThan, &value)
+}
+
+// GreaterThanOrEqualTo returns a filter matching numbers greater than or equal to the given value.
+func (nc NumberCondition) GreaterThanOrEqualTo(value float64) *notion.Filter {
+	return nc.build(notion.FilterNumberConditionEnumGreaterThanOrEqualTo, &value)
+}
+
+// LessThanOrEqualTo returns a filter matching numbers less than or equal to the given value.
+func (nc NumberCondition) LessThanOrEqualTo(value float64) *notion.Filter {
+	return nc.build(notion.FilterNumberConditionEnumLessThanOrEqualTo, &value)
+}
+
+// IsEmpty returns a filter matching an empty number.
+func (nc NumberCondition) IsEmpty() *notion.Filter {
+	return nc.build(notion.FilterNumberConditionEnumIsEmpty, nil)
+}
+
+// IsNotEmpty returns a filter matching a number that is not empty.
+func (nc NumberCondition) IsNotEmpty() *notion.Filter {
+	return nc.build(notion.FilterNumberConditionEnumIsNotEmpty, nil)
+}
+
+// A CheckboxCondition builds filters for checkbox properties.
+type CheckboxCondition struct {
+	condition
+}
+
+func (cc CheckboxCondition) build(t notion.FilterCheckboxConditionEnum, value bool) *notion.Filter {
+	cf := &notion.CheckboxFilter{Type: t, Value: &value}
+	if cc.formula {
+		return cc.newFormulaFilter(&notion.FormulaFilter{Type: notion.FilterFormulaConditionEnumCheckbox, Checkbox: cf})
+	}
+	f := cc.newFilter(notion.FilterConditionTypeEnumCheckbox)
+	f.Checkbox = cf
+	return f
+}
+
+// Equals returns a filter matching checkboxes equal to the given value.
+func (cc CheckboxCondition) Equals(value bool) *notion.Filter {
+	return cc.build(notion.FilterCheckboxConditionEnumEquals, value)
+}
+
+// DoesNotEqual returns a filter matching checkboxes not equal to the given value.
+func (cc CheckboxCondition) DoesNotEqual(value bool) *notion.Filter {
+	return cc.build(notion.FilterCheckboxConditionEnumDoesNotEqual, value)
+}
+
+// A SelectCondition builds filters for select properties.
+type SelectCondition struct {
+	condition
+}
+
+func (sc SelectCondition) build(t notion.FilterSelectConditionEnum, value *string) *notion.Filter {
+	f := sc.newFilter(notion.FilterConditionTypeEnumSelect)
+	f.Select = &notion.SelectFilter{Type: t, Value: value}
+	return f
+}
+
+// Equals returns a filter matching the select option with the given name.
+func (sc SelectCondition) Equals(option string) *notion.Filter {
+	return sc.build(notion.FilterSelectConditionEnumEquals, &option)
+}
+
+// DoesNotEqual returns a filter matching any select option other than the one with the given name.
+func (sc SelectCondition) DoesNotEqual(option string) *notion.Filter {
+	return sc.build(notion.FilterSelectConditionEnumDoesNotEqual, &option)
+}
+
+// IsEmpty returns a filter matching no selected option.
+func (sc SelectCondition) IsEmpty() *notion.Filter {
+	return sc.build(notion.FilterSelectConditionEnumIsEmpty, nil)
+}
+
+// IsNotEmpty returns a filter matching any selected option.
+func (sc SelectCondition) IsNotEmpty() *notion.Filter {
+	return sc.build(notion.FilterSelectConditionEnumIsNotEmpty, nil)
+}
+
+// A MultiSelectCondition builds filters for multi-select properties.
+type MultiSelectCondition struct {
+	condition
+}
+
+func (mc MultiSelectCondition) build(t notion.FilterMultiSelectConditionEnum, value *string) *notion.Filter {
+	f := mc.newFilter(notion.FilterConditionTypeEnumMultiSelect)
+	f.MultiSelect = &notion.MultiSelectFilter{Type: t, Value: value}
+	return f
+}
+
+// Contains returns a filter matching when the option with the given name is selected.
+func (mc MultiSelectCondition) Contains(option string) *notion.Filter {
+	return mc.build(notion.FilterMultiSelectConditionEnumContains, &option)
+}
+
+// DoesNotContain returns a filter matching when the option with the given name is not selected.
+func (mc MultiSelectCondition) DoesNotContain(option string) *notion.Filter {
+	return mc.build(notion.FilterMultiSelectConditionEnumDoesNotContain, &option)
+}
+
+// IsEmpty returns a filter matching no selected options.
+func (mc MultiSelectCondition) IsEmpty() *notion.Filter {
+	return mc.build(notion.FilterMultiSelectConditionEnumIsEmpty, nil)
+}
+
+// IsNotEmpty returns a filter matching any selected options.
+func (mc MultiSelectCondition) IsNotEmpty() *notion.Filter {
+	return mc.build(notion.FilterMultiSelectConditionEnumIsNotEmpty, nil)
+}
+
+// A DateCondition builds filters for date properties.
+type DateCondition struct {
+	condition
+}
+
+func (dc DateCondition) build(t notion.FilterDateConditionEnum, value time.Time) *notion.Filter {
+	df := &notion.DateFilter{Type: t, Value: value}
+	if dc.formula {
+		return dc.newFormulaFilter(&notion.FormulaFilter{Type: notion.FilterFormulaConditionEnumDate, Date: df})
+	}
+	f := dc.newFilter(notion.FilterConditionTypeEnumDate)
+	f.Date = df
+	return f
+}
+
+// Equals returns a filter matching dates equal to the given time.
+func (dc DateCondition) Equals(t time.Time) *notion.Filter {
+	return dc.build(notion.FilterDateConditionEnumEquals, t)
+}
+
+// Before returns a filter matching dates before the given time.
+func (dc DateCondition) Before(t time.Time) *notion.Filter {
+	return dc.build(notion.FilterDateConditionEnumBefore, t)
+}
+
+// After returns a filter matching dates after the given time.
+func (dc DateCondition) After(t time.Time) *notion.Filter {
+	return dc.build(notion.FilterDateConditionEnumAfter, t)
+}
+
+// OnOrBefore returns a filter matching dates on or before the given time.
+func (dc DateCondition) OnOrBefore(t time.Time) *notion.Filter {
+	return dc.build(notion.FilterDateConditionEnumOnOrBefore, t)
+}
+
+// OnOrAfter returns a filter matching dates on or after the given time.
+func (dc DateCondition) OnOrAfter(t time.Time) *notion.Filter {
+	return dc.build(notion.FilterDateConditionEnumOnOrAfter, t)
+}
+
+// IsEmpty returns a filter matching an empty date.
+func (dc DateCondition) IsEmpty() *notion.Filter {
+	return dc.build(notion.FilterDateConditionEnumIsEmpty, time.Time{})
+}
+
+// IsNotEmpty returns a filter matching a date that is not empty.
+func (dc DateCondition) IsNotEmpty() *notion.Filter {
+	return dc.build(notion.FilterDateConditionEnumIsNotEmpty, time.Time{})
+}
+
+// PastWeek returns a filter matching dates within the past week.
+func (dc DateCondition) PastWeek() *notion.Filter {
+	return dc.build(notion.FilterDateConditionEnumPastWeek, time.Time{})
+}
+
+// PastMonth returns a filter matching dates within the past month.
+func (dc DateCondition) PastMonth() *notion.Filter {
+	return dc.build(notion.FilterDateConditionEnumPastMonth, time.Time{})
+}
+
+// PastYear returns a filter matching dates within the past year.
+func (dc DateCondition) PastYear() *notion.Filter {
+	return dc.build(notion.FilterDateConditionEnumPastYear, time.Time{})
+}
+
+// NextWeek returns a filter matching dates within the next week.
+func (dc DateCondition) NextWeek() *notion.Filter {
+	return dc.build(notion.FilterDateConditionEnumNextWeek, time.Time{})
+}
+
+// NextMonth returns a filter matching dates within the next month.
+func (dc DateCondition) NextMonth() *notion.Filter {
+	return dc.build(notion.FilterDateConditionEnumNextMonth, time.Time{})
+}
+
+// NextYear returns a filter matching dates within the next year.
+func (dc DateCondition) NextYear() *notion.Filter {
+	return dc.build(notion.FilterDateConditionEnumNextYear, time.Time{})
+}
+
+// A PeopleCondition builds filters for people properties.
+type PeopleCondition struct {
+	condition
+}
+
+func (pc PeopleCondition) build(t notion.FilterPeopleConditionEnum, value *notion.UUID4) *notion.Filter {
+	f := pc.newFilter(notion.FilterConditionTypeEnumPeople)
+	f.People = &notion.PeopleFilter{Type: t, Value: value}
+	return f
+}
+
+// Contains returns a filter matching when the user with the given id is included.
+func (pc PeopleCondition) Contains(id notion.UUID4) *notion.Filter {
+	return pc.build(notion.FilterPeopleConditionEnumContains, &id)
+}
+
+// DoesNotContain returns a filter matching when the user with the given id is not included.
+func (pc PeopleCondition) DoesNotContain(id notion.UUID4) *notion.Filter {
+	return pc.build(notion.FilterPeopleConditionEnumDoesNotContain, &id)
+}
+
+// IsEmpty returns a filter matching no people.
+func (pc PeopleCondition) IsEmpty() *notion.Filter {
+	return pc.build(notion.FilterPeopleConditionEnumIsEmpty, nil)
+}
+
+// IsNotEmpty returns a filter matching any people.
+func (pc PeopleCondition) IsNotEmpty() *notion.Filter {
+	return pc.build(notion.FilterPeopleConditionEnumIsNotEmpty, nil)
+}
+
+// A FilesCondition builds filters for files properties.
+type FilesCondition struct {
+	condition
+}
+
+func (fc FilesCondition) build(t notion.FilterFilesConditionEnum) *notion.Filter {
+	f := fc.newFilter(notion.FilterConditionTypeEnumFiles)
+	f.Files = &notion.FilesFilter{Type: t}
+	return f
+}
+
+// IsEmpty returns a filter matching no files.
+func (fc FilesCondition) IsEmpty() *notion.Filter {
+	return fc.build(notion.FilterFilesConditionEnumIsEmpty)
+}
+
+// IsNotEmpty returns a filter matching any files.
+func (fc FilesCondition) IsNotEmpty() *notion.Filter {
+	return fc.build(notion.FilterFilesConditionEnumIsNotEmpty)
+}
+
+// A RelationCondition builds filters for relation properties.
+type RelationCondition struct {
+	condition
+}
+
+func (rc RelationCondition) build(t notion.FilterRelationConditionEnum, value *notion.UUID4) *notion.Filter {
+	f := rc.newFilter(notion.FilterConditionTypeEnumRelation)
+	f.Relation = &notion.RelationFilter{Type: t, Value: value}
+	return f
+}
+
+// Contains returns a filter matching when the page with the given id is related.
+func (rc RelationCondition) Contains(id notion.UUID4) *notion.Filter {
+	return rc.build(notion.FilterRelationConditionEnumContains, &id)
+}
+
+// DoesNotContain returns a filter matching when the page with the given id is not related.
+func (rc RelationCondition) DoesNotContain(id notion.UUID4) *notion.Filter {
+	return rc.build(notion.FilterRelationConditionEnumDoesNotContain, &id)
+}
+
+// IsEmpty returns a filter matching no related pages.
+func (rc RelationCondition) IsEmpty() *notion.Filter {
+	return rc.build(notion.FilterRelationConditionEnumIsEmpty, nil)
+}
+
+// IsNotEmpty returns a filter matching any related pages.
+func (rc RelationCondition) IsNotEmpty() *notion.Filter {
+	return rc.build(notion.FilterRelationConditionEnumIsNotEmpty, nil)
+}
@@ -0,0 +1,1050 @@
+// Package gotiontest provides an in-memory fake of the Notion API for testing code that uses a gotion client.
+//
+// The fake implements pages, databases, blocks, users, and search, including pagination, archiving,
+// and error responses in the same form as the Notion API. Errors and rate limiting can be injected
+// with FailNext and RateLimitNext.
+//
+// Database queries and search are simplified: filters on database queries are ignored, and search only
+// matches the query against titles. Sorts on database queries are applied for timestamps and for the
+// values of title, rich text, number, checkbox, select, and date properties.
+package gotiontest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thedadams/gotion"
+	"github.com/thedadams/gotion/notion"
+	"golang.org/x/time/rate"
+)
+
+// DefaultToken is the token that the fake Notion API accepts, unless a different one is set on the Server.
+const DefaultToken = "secret_gotiontest"
+
+// An object is a JSON object as it is returned by the Notion API.
+type object = map[string]interface{}
+
+type failure struct {
+	status     int
+	code       string
+	message    string
+	retryAfter time.Duration
+}
+
+// A Server is an in-memory fake of the Notion API.
+type Server struct {
+	// URL is the base URL of the server, to be used with gotion.WithBaseURL.
+	URL string
+	// Token is the API token that the server accepts.
+	Token string
+
+	srv      *httptest.Server
+	mu       sync.Mutex
+	objects  map[string]object
+	order    []string
+	children map[string][]string
+	// childPages holds the child_page blocks of pages, which share their ids with the pages themselves.
+	childPages map[string]object
+	failures   []failure
+	requests   []*http.Request
+	now        func() time.Time
+}
+
+// NewServer starts a new fake Notion API. The caller should call Close when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{
+		Token:      DefaultToken,
+		objects:    make(map[string]object),
+		children:   make(map[string][]string),
+		childPages: make(map[string]object),
+		now:        func() time.Time { return time.Now().UTC() },
+	}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.srv.URL
+	return s
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// NewClient creates a new gotion client that sends requests to the server.
+// The client is not rate limited and does not wait between retries. The options are applied after these defaults.
+func (s *Server) NewClient(options ...gotion.Option) *gotion.Client {
+	defaults := []gotion.Option{
+		gotion.WithBaseURL(s.URL),
+		gotion.WithRateLimiter(rate.NewLimiter(rate.Inf, 1)),
+		gotion.WithBackoffStrategy(func(int) time.Duration { return 0 }),
+	}
+	return gotion.NewClient(s.Token, append(defaults, options...)...)
+}
+
+// FailNext makes the next request to the server fail with the given status and error code.
+// Multiple calls queue multiple failures.
+func (s *Server) FailNext(status int, code, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, failure{status: status, code: code, message: message})
+}
+
+// RateLimitNext makes the next n requests to the server fail with a 429 status and the "rate_limited" error code.
+// The responses have a Retry-After header with the given duration, rounded up to the nearest second.
+func (s *Server) RateLimitNext(n int, retryAfter time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := 0; i < n; i++ {
+		s.failures = append(s.failures, failure{
+			status:     http.StatusTooManyRequests,
+			code:       notion.ErrorCodeRateLimited,
+			message:    "You have been rate limited. Please try again in a few minutes.",
+			retryAfter: retryAfter,
+		})
+	}
+}
+
+// Requests returns the requests that the server has received, in order.
+// The bodies of the requests have already been read.
+func (s *Server) Requests() []*http.Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*http.Request(nil), s.requests...)
+}
+
+// AddUser adds the user to the server. If the user has no ID, then one is generated.
+// The user is returned as the Notion API would return it.
+func (s *Server) AddUser(u *notion.User) (*notion.User, error) {
+	obj, err := toObject(u)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	obj["object"] = "user"
+	s.insert(obj)
+	s.mu.Unlock()
+
+	result := new(notion.User)
+	return result, fromObject(obj, result)
+}
+
+// AddPage adds the page, and its children, to the server. If the page or its children have no IDs, then they are generated.
+// The page is returned as the Notion API would return it, without its children.
+func (s *Server) AddPage(p *notion.Page) (*notion.Page, error) {
+	obj, err := toObject(p)
+	if err != nil {
+		return nil, err
+	}
+	children, _ := obj["children"].([]interface{})
+	delete(obj, "children")
+
+	s.mu.Lock()
+	s.insertPage(obj)
+	err = s.appendChildren(obj["id"].(string), children)
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	result := new(notion.Page)
+	return result, fromObject(obj, result)
+}
+
+// AddDatabase adds the database to the server. If the database has no ID, then one is generated.
+// The database is returned as the Notion API would return it.
+func (s *Server) AddDatabase(db *notion.Database) (*notion.Database, error) {
+	obj, err := toObject(db)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.insertDatabase(obj)
+	s.mu.Unlock()
+
+	result := new(notion.Database)
+	return result, fromObject(obj, result)
+}
+
+// AddBlocks appends the blocks, and their children, to the children of the page or block with the given id.
+// If the blocks have no IDs, then they are generated.
+func (s *Server) AddBlocks(parentID string, blocks ...*notion.Block) error {
+	raw, err := json.Marshal(notion.Blocks(blocks))
+	if err != nil {
+		return err
+	}
+	var children []interface{}
+	if err := json.Unmarshal(raw, &children); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.appendChildren(normalizeID(parentID), children)
+}
+
+func toObject(v interface{}) (object, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	obj := make(object)
+	if err := json.Unmarshal(b, &obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+func fromObject(obj object, v interface{}) error {
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// normalizeID turns ids without dashes, as they appear in Notion URLs, into UUIDs with dashes.
+func normalizeID(id string) string {
+	if u, err := uuid.Parse(id); err == nil {
+		return u.String()
+	}
+	return id
+}
+
+func (s *Server) timestamp() string {
+	return s.now().Format("2006-01-02T15:04:05.000Z")
+}
+
+// insert stores the object, generating an id if it doesn't have one. The mutex must be held.
+func (s *Server) insert(obj object) {
+	id, _ := obj["id"].(string)
+	if id == "" || id == (uuid.UUID{}).String() {
+		id = uuid.New().String()
+	}
+	id = normalizeID(id)
+	obj["id"] = id
+	if _, ok := s.objects[id]; !ok {
+		s.order = append(s.order, id)
+	}
+	s.objects[id] = obj
+}
+
+func (s *Server) stamp(obj object) {
+	now := s.timestamp()
+	for _, key := range []string{"created_time", "last_edited_time"} {
+		if t, ok := obj[key].(string); !ok || t == "" || strings.HasPrefix(t, "0001-01-01") {
+			obj[key] = now
+		}
+	}
+}
+
+// insertPage fills in the fields the Notion API sets on pages and stores the page. The mutex must be held.
+func (s *Server) insertPage(obj object) {
+	obj["object"] = "page"
+	s.stamp(obj)
+	if _, ok := obj["archived"]; !ok {
+		obj["archived"] = false
+	}
+	s.insert(obj)
+	obj["url"] = "https://www.notion.so/" + strings.ReplaceAll(obj["id"].(string), "-", "")
+
+	props, _ := obj["properties"].(map[string]interface{})
+	if props == nil {
+		props = make(map[string]interface{})
+		obj["properties"] = props
+	}
+	var schema map[string]interface{}
+	if parent, ok := obj["parent"].(map[string]interface{}); ok && parent["type"] == notion.ParentTypeEnumDatabase {
+		if db := s.objects[normalizeID(fmt.Sprint(parent[notion.ParentTypeEnumDatabase]))]; db != nil {
+			schema, _ = db["properties"].(map[string]interface{})
+		}
+	}
+	for name, p := range props {
+		prop, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		normalizeProperty(prop, schema[name])
+	}
+}
+
+// normalizeProperty fills in the type and id of a page property, from the database schema if there is one.
+func normalizeProperty(prop map[string]interface{}, schema interface{}) {
+	if dbProp, ok := schema.(map[string]interface{}); ok {
+		if unset(prop["id"]) {
+			prop["id"] = dbProp["id"]
+		}
+		if unset(prop["type"]) {
+			prop["type"] = dbProp["type"]
+		}
+	}
+	if unset(prop["type"]) {
+		for key := range prop {
+			if key != "id" && key != "type" {
+				prop["type"] = key
+				break
+			}
+		}
+	}
+	if unset(prop["id"]) {
+		prop["id"] = randomID()
+	}
+	if t, ok := prop["type"].(string); ok {
+		if rts, ok := prop[t].([]interface{}); ok && (t == notion.DatabasePropertyTypeEnumTitle || t == notion.DatabasePropertyTypeEnumRichText) {
+			normalizeRichText(rts)
+		}
+	}
+}
+
+// insertDatabase fills in the fields the Notion API sets on databases and stores the database. The mutex must be held.
+func (s *Server) insertDatabase(obj object) {
+	obj["object"] = "database"
+	s.stamp(obj)
+	s.insert(obj)
+	obj["url"] = "https://www.notion.so/" + strings.ReplaceAll(obj["id"].(string), "-", "")
+	if rts, ok := obj["title"].([]interface{}); ok {
+		normalizeRichText(rts)
+	} else {
+		obj["title"] = []interface{}{}
+	}
+
+	props, _ := obj["properties"].(map[string]interface{})
+	if props == nil {
+		props = make(map[string]interface{})
+		obj["properties"] = props
+	}
+	for name, p := range props {
+		prop, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		prop["name"] = name
+		if unset(prop["id"]) {
+			prop["id"] = randomID()
+		}
+		if prop["type"] == notion.DatabasePropertyTypeEnumTitle {
+			prop["id"] = "title"
+		}
+		if t, ok := prop["type"].(string); ok && prop[t] == nil {
+			prop[t] = map[string]interface{}{}
+		}
+	}
+}
+
+// unset reports whether a field is missing or empty, which is how the notion package marshals unset ids and types.
+func unset(v interface{}) bool {
+	return v == nil || v == ""
+}
+
+func randomID() string {
+	return uuid.New().String()[:4]
+}
+
+// normalizeRichText fills in the plain text and annotations of rich text objects.
+func normalizeRichText(rts []interface{}) {
+	for _, r := range rts {
+		rt, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if rt["type"] == nil {
+			rt["type"] = notion.RichTextTypeEnumText
+		}
+		annotations, _ := rt["annotations"].(map[string]interface{})
+		if annotations == nil {
+			annotations = make(map[string]interface{})
+			rt["annotations"] = annotations
+		}
+		for _, a := range []string{"bold", "italic", "strikethrough", "underline", "code"} {
+			if annotations[a] == nil {
+				annotations[a] = false
+			}
+		}
+		if annotations["color"] == nil || annotations["color"] == "" {
+			annotations["color"] = notion.AnnotationColorEnumDefault
+		}
+		if pt, _ := rt["plain_text"].(string); pt == "" {
+			switch rt["type"] {
+			case notion.RichTextTypeEnumText:
+				if text, ok := rt["text"].(map[string]interface{}); ok {
+					rt["plain_text"] = text["content"]
+					if link, ok := text["link"].(map[string]interface{}); ok {
+						rt["href"] = link["url"]
+					}
+				}
+			case notion.RichTextTypeEnumEquation:
+				if eq, ok := rt["equation"].(map[string]interface{}); ok {
+					rt["plain_text"] = eq["expression"]
+				}
+			}
+		}
+	}
+}
+
+// appendChildren stores the blocks as children of the parent, recursively storing their children. The mutex must be held.
+func (s *Server) appendChildren(parentID string, children []interface{}) error {
+	for _, c := range children {
+		block, ok := c.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("child of %s is not an object", parentID)
+		}
+		blockType, _ := block["type"].(string)
+		if blockType == "" {
+			for key := range block {
+				if key != "object" && key != "id" {
+					blockType = key
+				}
+			}
+			block["type"] = blockType
+		}
+
+		content, _ := block[blockType].(map[string]interface{})
+		if content == nil {
+			content = make(map[string]interface{})
+			block[blockType] = content
+		}
+		grandchildren, _ := content["children"].([]interface{})
+		delete(content, "children")
+		if rts, ok := content["text"].([]interface{}); ok {
+			normalizeRichText(rts)
+		}
+
+		block["object"] = "block"
+		block["has_children"] = false
+		if _, ok := block["archived"]; !ok {
+			block["archived"] = false
+		}
+		s.stamp(block)
+		s.insert(block)
+		id := block["id"].(string)
+		s.children[parentID] = append(s.children[parentID], id)
+		if parent := s.objects[parentID]; parent != nil && parent["object"] == "block" {
+			parent["has_children"] = true
+		}
+
+		if err := s.appendChildren(id, grandchildren); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	var body object
+	if r.Body != nil && r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, notion.ErrorCodeInvalidJSON, "Error parsing JSON body.")
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, r)
+
+	if len(s.failures) != 0 {
+		f := s.failures[0]
+		s.failures = s.failures[1:]
+		if f.retryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int((f.retryAfter+time.Second-1)/time.Second)))
+		}
+		writeError(w, f.status, f.code, f.message)
+		return
+	}
+
+	if r.Header.Get("Authorization") != "Bearer "+s.Token {
+		writeError(w, http.StatusUnauthorized, notion.ErrorCodeUnauthorized, "API token is invalid.")
+		return
+	}
+	if r.Header.Get("Notion-Version") == "" {
+		writeError(w, http.StatusBadRequest, notion.ErrorCodeValidationError,
+			"Notion-Version header failed validation: Notion-Version header should be defined.")
+		return
+	}
+
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(segments) < 2 || segments[0] != "v1" {
+		writeError(w, http.StatusBadRequest, notion.ErrorCodeInvalidRequestURL, "Invalid request URL.")
+		return
+	}
+
+	var (
+		id, sub string
+	)
+	if len(segments) > 2 {
+		id = normalizeID(segments[2])
+	}
+	if len(segments) > 3 {
+		sub = segments[3]
+	}
+
+	switch route := r.Method + " " + segments[1]; {
+	case route == "GET users" && id == "":
+		s.list(w, r, body, s.all("user", nil))
+	case route == "GET users":
+		s.get(w, id, "user")
+	case route == "GET pages" && id != "":
+		s.get(w, id, "page")
+	case route == "POST pages" && id == "":
+		s.createPage(w, body)
+	case route == "PATCH pages" && id != "":
+		s.updatePage(w, id, body)
+	case route == "GET databases" && id == "":
+		s.list(w, r, body, s.all("database", nil))
+	case route == "GET databases":
+		s.get(w, id, "database")
+	case route == "POST databases" && id == "":
+		s.createDatabase(w, body)
+	case route == "POST databases" && sub == "query":
+		s.queryDatabase(w, r, id, body)
+	case route == "PATCH databases" && id != "":
+		s.updateDatabase(w, id, body)
+	case route == "GET blocks" && sub == "children":
+		s.getChildren(w, r, id, body)
+	case route == "PATCH blocks" && sub == "children":
+		s.appendBlockChildren(w, id, body)
+	case route == "GET blocks" && id != "":
+		s.get(w, id, "block")
+	case route == "PATCH blocks" && id != "":
+		s.updateBlock(w, id, body)
+	case route == "DELETE blocks" && id != "":
+		s.deleteBlock(w, id)
+	case route == "POST search":
+		s.search(w, r, body)
+	default:
+		writeError(w, http.StatusBadRequest, notion.ErrorCodeInvalidRequestURL, "Invalid request URL.")
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, object{"object": "error", "status": status, "code": code, "message": message, "request_id": uuid.New().String()})
+}
+
+func notFound(w http.ResponseWriter, id string) {
+	writeError(w, http.StatusNotFound, notion.ErrorCodeObjectNotFound,
+		fmt.Sprintf("Could not find object with ID: %s. Make sure the relevant pages and databases are shared with your integration.", id))
+}
+
+func (s *Server) lookup(id, kind string) object {
+	obj := s.objects[id]
+	if obj == nil || obj["object"] != kind {
+		return nil
+	}
+	return obj
+}
+
+func (s *Server) get(w http.ResponseWriter, id, kind string) {
+	obj := s.lookup(id, kind)
+	if obj == nil {
+		notFound(w, id)
+		return
+	}
+	writeJSON(w, http.StatusOK, obj)
+}
+
+// all returns the objects of the given kind, in the order they were added, that satisfy keep, if it is not nil.
+func (s *Server) all(kind string, keep func(object) bool) []object {
+	var result []object
+	for _, id := range s.order {
+		if obj := s.objects[id]; obj["object"] == kind && (keep == nil || keep(obj)) {
+			result = append(result, obj)
+		}
+	}
+	return result
+}
+
+// list writes a page of the results, based on the start_cursor and page_size in the query string or body.
+func (s *Server) list(w http.ResponseWriter, r *http.Request, body object, results []object) {
+	cursor, size := r.URL.Query().Get("start_cursor"), r.URL.Query().Get("page_size")
+	if c, ok := body["start_cursor"].(string); ok {
+		cursor = c
+	}
+	if n, ok := body["page_size"].(float64); ok {
+		size = strconv.Itoa(int(n))
+	}
+
+	pageSize := 100
+	if size != "" {
+		n, err := strconv.Atoi(size)
+		if err != nil || n < 1 || n > 100 {
+			writeError(w, http.StatusBadRequest, notion.ErrorCodeValidationError, "page_size should be a number between 1 and 100.")
+			return
+		}
+		pageSize = n
+	}
+
+	start := 0
+	if cursor != "" {
+		start = -1
+		for i, obj := range results {
+			if obj["id"] == cursor {
+				start = i
+				break
+			}
+		}
+		if start < 0 {
+			writeError(w, http.StatusBadRequest, notion.ErrorCodeValidationError, "start_cursor provided is invalid: "+cursor)
+			return
+		}
+	}
+
+	end := start + pageSize
+	if end > len(results) {
+		end = len(results)
+	}
+	page := results[start:end]
+	if page == nil {
+		page = []object{}
+	}
+
+	var next interface{}
+	if end < len(results) {
+		next = results[end]["id"]
+	}
+	writeJSON(w, http.StatusOK, object{"object": "list", "results": page, "next_cursor": next, "has_more": next != nil})
+}
+
+func (s *Server) createPage(w http.ResponseWriter, body object) {
+	parent, ok := body["parent"].(map[string]interface{})
+	if !ok {
+		writeError(w, http.StatusBadRequest, notion.ErrorCodeValidationError, "body failed validation: body.parent should be defined.")
+		return
+	}
+	parentID := normalizeID(fmt.Sprint(parent[fmt.Sprint(parent["type"])]))
+	if parent["type"] == notion.ParentTypeEnumDatabase && s.lookup(parentID, "database") == nil ||
+		parent["type"] == notion.ParentTypeEnumPage && s.lookup(parentID, "page") == nil {
+		notFound(w, parentID)
+		return
+	}
+
+	page := object{"parent": parent, "properties": body["properties"]}
+	for _, key := range []string{"icon", "cover"} {
+		if v, ok := body[key]; ok {
+			page[key] = v
+		}
+	}
+	page["id"] = uuid.New().String()
+	s.insertPage(page)
+
+	children, _ := body["children"].([]interface{})
+	if err := s.appendChildren(page["id"].(string), children); err != nil {
+		writeError(w, http.StatusBadRequest, notion.ErrorCodeValidationError, err.Error())
+		return
+	}
+	if parent["type"] == notion.ParentTypeEnumPage {
+		s.addChildPage(parentID, page)
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+// addChildPage adds a child_page block for the page to the children of its parent page.
+func (s *Server) addChildPage(parentID string, page object) {
+	title := ""
+	for _, p := range page["properties"].(map[string]interface{}) {
+		if prop, ok := p.(map[string]interface{}); ok && prop["type"] == notion.DatabasePropertyTypeEnumTitle {
+			title = plainText(prop["title"])
+		}
+	}
+	block := object{
+		"object":       "block",
+		"id":           page["id"],
+		"type":         notion.BlockTypeEnumChildPage,
+		"has_children": false,
+		"archived":     false,
+		notion.BlockTypeEnumChildPage: map[string]interface{}{
+			"title": title,
+		},
+		"created_time":     page["created_time"],
+		"last_edited_time": page["last_edited_time"],
+	}
+	s.children[parentID] = append(s.children[parentID], page["id"].(string))
+	s.childPages[page["id"].(string)] = block
+}
+
+func (s *Server) updatePage(w http.ResponseWriter, id string, body object) {
+	page := s.lookup(id, "page")
+	if page == nil {
+		notFound(w, id)
+		return
+	}
+
+	if props, ok := body["properties"].(map[string]interface{}); ok {
+		current := page["properties"].(map[string]interface{})
+		var schema map[string]interface{}
+		if parent, ok := page["parent"].(map[string]interface{}); ok && parent["type"] == notion.ParentTypeEnumDatabase {
+			if db := s.lookup(normalizeID(fmt.Sprint(parent[notion.ParentTypeEnumDatabase])), "database"); db != nil {
+				schema, _ = db["properties"].(map[string]interface{})
+			}
+		}
+		for name, p := range props {
+			prop, ok := p.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if old, ok := current[name].(map[string]interface{}); ok {
+				if unset(prop["id"]) {
+					prop["id"] = old["id"]
+				}
+				if unset(prop["type"]) {
+					prop["type"] = old["type"]
+				}
+			}
+			normalizeProperty(prop, schema[name])
+			current[name] = prop
+		}
+	}
+	for _, key := range []string{"archived", "icon", "cover"} {
+		if v, ok := body[key]; ok {
+			if v == nil {
+				delete(page, key)
+			} else {
+				page[key] = v
+			}
+		}
+	}
+	page["last_edited_time"] = s.timestamp()
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (s *Server) createDatabase(w http.ResponseWriter, body object) {
+	parent, ok := body["parent"].(map[string]interface{})
+	if !ok || parent["type"] != notion.ParentTypeEnumPage {
+		writeError(w, http.StatusBadRequest, notion.ErrorCodeValidationError, "body failed validation: body.parent.page_id should be defined.")
+		return
+	}
+	if parentID := normalizeID(fmt.Sprint(parent[notion.ParentTypeEnumPage])); s.lookup(parentID, "page") == nil {
+		notFound(w, parentID)
+		return
+	}
+
+	db := object{"parent": parent, "properties": body["properties"], "title": body["title"]}
+	for _, key := range []string{"icon", "cover"} {
+		if v, ok := body[key]; ok {
+			db[key] = v
+		}
+	}
+	if db["title"] == nil {
+		db["title"] = []interface{}{}
+	}
+	db["id"] = uuid.New().String()
+	s.insertDatabase(db)
+	writeJSON(w, http.StatusOK, db)
+}
+
+func (s *Server) updateDatabase(w http.ResponseWriter, id string, body object) {
+	db := s.lookup(id, "database")
+	if db == nil {
+		notFound(w, id)
+		return
+	}
+
+	if title, ok := body["title"].([]interface{}); ok {
+		normalizeRichText(title)
+		db["title"] = title
+	}
+	if props, ok := body["properties"].(map[string]interface{}); ok {
+		current := db["properties"].(map[string]interface{})
+		for key, p := range props {
+			name, existing := key, current[key]
+			if existing == nil {
+				// Properties can be referred to by id as well as name.
+				for n, c := range current {
+					if c.(map[string]interface{})["id"] == key {
+						name, existing = n, c
+						break
+					}
+				}
+			}
+
+			if p == nil {
+				delete(current, name)
+				continue
+			}
+			prop, ok := p.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			// The type of a property is changed by giving the configuration of the new type, with or without "type".
+			t, hasType := prop["type"]
+			if !hasType {
+				for k := range prop {
+					if k != "name" && k != "id" {
+						t, hasType = k, true
+					}
+				}
+			}
+			merged := make(map[string]interface{})
+			if old, ok := existing.(map[string]interface{}); ok {
+				for k, v := range old {
+					merged[k] = v
+				}
+				if hasType && t != old["type"] {
+					delete(merged, fmt.Sprint(old["type"]))
+					merged["type"] = t
+				}
+			}
+			for k, v := range prop {
+				merged[k] = v
+			}
+			if newName, ok := prop["name"].(string); ok && newName != "" {
+				delete(current, name)
+				name = newName
+			}
+			if unset(merged["id"]) {
+				merged["id"] = randomID()
+			}
+			if unset(merged["type"]) {
+				for k := range prop {
+					if k != "name" && k != "id" && k != "type" {
+						merged["type"] = k
+					}
+				}
+			}
+			if t, ok := merged["type"].(string); ok && merged[t] == nil {
+				merged[t] = map[string]interface{}{}
+			}
+			merged["name"] = name
+			current[name] = merged
+		}
+	}
+	for _, key := range []string{"icon", "cover"} {
+		if v, ok := body[key]; ok {
+			if v == nil {
+				delete(db, key)
+			} else {
+				db[key] = v
+			}
+		}
+	}
+	db["last_edited_time"] = s.timestamp()
+	writeJSON(w, http.StatusOK, db)
+}
+
+func (s *Server) queryDatabase(w http.ResponseWriter, r *http.Request, id string, body object) {
+	if s.lookup(id, "database") == nil {
+		notFound(w, id)
+		return
+	}
+
+	pages := s.all("page", func(page object) bool {
+		parent, _ := page["parent"].(map[string]interface{})
+		return page["archived"] != true && parent["type"] == notion.ParentTypeEnumDatabase &&
+			normalizeID(fmt.Sprint(parent[notion.ParentTypeEnumDatabase])) == id
+	})
+	if sorts, ok := body["sorts"].([]interface{}); ok {
+		sortObjects(pages, sorts)
+	}
+	s.list(w, r, body, pages)
+}
+
+// sortObjects sorts the pages with the given sorts from a database query. The sort is stable, so pages with equal
+// values stay in the order they were added.
+func sortObjects(pages []object, sorts []interface{}) {
+	sort.SliceStable(pages, func(i, j int) bool {
+		for _, so := range sorts {
+			srt, _ := so.(map[string]interface{})
+			var a, b string
+			if ts, ok := srt["timestamp"].(string); ok {
+				a, b = fmt.Sprint(pages[i][ts]), fmt.Sprint(pages[j][ts])
+			} else {
+				name := fmt.Sprint(srt["property"])
+				a, b = sortValue(pages[i], name), sortValue(pages[j], name)
+			}
+			if a == b {
+				continue
+			}
+			if srt["direction"] == notion.SortDirectionEnumDescending {
+				return a > b
+			}
+			return a < b
+		}
+		return false
+	})
+}
+
+// sortValue returns a string for the property of the page that sorts in the same order as the value.
+func sortValue(page object, name string) string {
+	props, _ := page["properties"].(map[string]interface{})
+	prop, _ := props[name].(map[string]interface{})
+	t := fmt.Sprint(prop["type"])
+	switch v := prop[t].(type) {
+	case []interface{}:
+		return plainText(v)
+	case float64:
+		// Offset the number so that negative numbers sort before positive ones.
+		return fmt.Sprintf("%030.10f", v+1e15)
+	case bool:
+		return strconv.FormatBool(v)
+	case map[string]interface{}:
+		if name, ok := v["name"]; ok {
+			return fmt.Sprint(name)
+		}
+		return fmt.Sprint(v["start"])
+	case string:
+		return v
+	}
+	return ""
+}
+
+func plainText(v interface{}) string {
+	rts, _ := v.([]interface{})
+	var sb strings.Builder
+	for _, r := range rts {
+		if rt, ok := r.(map[string]interface{}); ok {
+			sb.WriteString(fmt.Sprint(rt["plain_text"]))
+		}
+	}
+	return sb.String()
+}
+
+// visibleChildren returns the children of the page or block that have not been archived.
+func (s *Server) visibleChildren(id string) []object {
+	var result []object
+	for _, childID := range s.children[id] {
+		if obj := s.objects[childID]; obj != nil && obj["object"] == "block" {
+			if obj["archived"] != true {
+				result = append(result, obj)
+			}
+		} else if block := s.childPages[childID]; block != nil && obj != nil && obj["archived"] != true {
+			result = append(result, block)
+		}
+	}
+	return result
+}
+
+func (s *Server) getChildren(w http.ResponseWriter, r *http.Request, id string, body object) {
+	if s.lookup(id, "block") == nil && s.lookup(id, "page") == nil {
+		notFound(w, id)
+		return
+	}
+	s.list(w, r, body, s.visibleChildren(id))
+}
+
+func (s *Server) appendBlockChildren(w http.ResponseWriter, id string, body object) {
+	parent := s.lookup(id, "block")
+	if parent == nil {
+		parent = s.lookup(id, "page")
+	}
+	if parent == nil {
+		notFound(w, id)
+		return
+	}
+
+	children, ok := body["children"].([]interface{})
+	if !ok {
+		writeError(w, http.StatusBadRequest, notion.ErrorCodeValidationError, "body failed validation: body.children should be defined.")
+		return
+	}
+	if err := s.appendChildren(id, children); err != nil {
+		writeError(w, http.StatusBadRequest, notion.ErrorCodeValidationError, err.Error())
+		return
+	}
+	parent["last_edited_time"] = s.timestamp()
+	writeJSON(w, http.StatusOK, parent)
+}
+
+func (s *Server) updateBlock(w http.ResponseWriter, id string, body object) {
+	block := s.lookup(id, "block")
+	if block == nil {
+		notFound(w, id)
+		return
+	}
+
+	blockType := fmt.Sprint(block["type"])
+	if content, ok := body[blockType].(map[string]interface{}); ok {
+		current, _ := block[blockType].(map[string]interface{})
+		if current == nil {
+			current = make(map[string]interface{})
+			block[blockType] = current
+		}
+		for k, v := range content {
+			if k == "children" {
+				continue
+			}
+			current[k] = v
+		}
+		if rts, ok := current["text"].([]interface{}); ok {
+			normalizeRichText(rts)
+		}
+	}
+	if archived, ok := body["archived"].(bool); ok {
+		block["archived"] = archived
+	}
+	block["last_edited_time"] = s.timestamp()
+	writeJSON(w, http.StatusOK, block)
+}
+
+func (s *Server) deleteBlock(w http.ResponseWriter, id string) {
+	block := s.lookup(id, "block")
+	if block == nil {
+		// Deleting a child page block archives the page.
+		if page := s.lookup(id, "page"); page != nil && s.childPages[id] != nil {
+			page["archived"] = true
+			writeJSON(w, http.StatusOK, s.childPages[id])
+			return
+		}
+		notFound(w, id)
+		return
+	}
+
+	block["archived"] = true
+	block["last_edited_time"] = s.timestamp()
+	writeJSON(w, http.StatusOK, block)
+}
+
+func (s *Server) search(w http.ResponseWriter, r *http.Request, body object) {
+	query := strings.ToLower(fmt.Sprint(body["query"]))
+	if body["query"] == nil {
+		query = ""
+	}
+	kind := ""
+	if filter, ok := body["filter"].(map[string]interface{}); ok && filter["property"] == "object" {
+		kind = fmt.Sprint(filter["value"])
+	}
+
+	var results []object
+	for _, id := range s.order {
+		obj := s.objects[id]
+		if (obj["object"] != "page" && obj["object"] != "database") || obj["archived"] == true || (kind != "" && obj["object"] != kind) {
+			continue
+		}
+		if query != "" && !strings.Contains(strings.ToLower(title(obj)), query) {
+			continue
+		}
+		results = append(results, obj)
+	}
+
+	if srt, ok := body["sort"].(map[string]interface{}); ok {
+		sortObjects(results, []interface{}{srt})
+	}
+	s.list(w, r, body, results)
+}
+
+// title returns the plain text title of a page or database.
+func title(obj object) string {
+	if obj["object"] == "database" {
+		return plainText(obj["title"])
+	}
+	props, _ := obj["properties"].(map[string]interface{})
+	for _, p := range props {
+		if prop, ok := p.(map[string]interface{}); ok && prop["type"] == notion.DatabasePropertyTypeEnumTitle {
+			return plainText(prop["title"])
+		}
+	}
+	return ""
+}
@@ -0,0 +1,563 @@
+// Package html renders Notion pages, blocks, and rich text as HTML that is safe to use with html/template.
+//
+// Blocks and annotations are rendered as semantic elements, like <h1>, <ul>, <details>, <strong>, and <code>.
+// Colors are rendered as classes, like "notion-color-red" or "notion-color-red-background", so that they can be styled.
+package html
+
+import (
+	"html/template"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/thedadams/gotion/notion"
+)
+
+const (
+	defaultClassPrefix = "notion-"
+	// plainTextLanguage is the language Notion uses for code blocks without a language.
+	plainTextLanguage = "plain text"
+)
+
+// A MentionRenderer renders a mention in rich text as HTML. The plain text is the text Notion shows for the mention.
+type MentionRenderer func(m *notion.Mention, plainText string) template.HTML
+
+// A Renderer renders Notion pages, blocks, and rich text as HTML.
+type Renderer struct {
+	classPrefix string
+	mention     MentionRenderer
+	pageURL     func(id string) string
+}
+
+// An Option is a way of customizing the HTML renderer
+type Option func(*Renderer)
+
+// WithClassPrefix uses the given prefix for all classes in the rendered HTML. The default prefix is "notion-".
+func WithClassPrefix(p string) Option {
+	return func(r *Renderer) {
+		if r != nil {
+			r.classPrefix = p
+		}
+	}
+}
+
+// WithMentionRenderer uses the given function to render mentions of users, pages, databases, and dates.
+func WithMentionRenderer(m MentionRenderer) Option {
+	return func(r *Renderer) {
+		if r != nil && m != nil {
+			r.mention = m
+		}
+	}
+}
+
+// WithPageURL uses the given function to get the URL to link to for pages, given their id.
+// It is used for child pages, and for mentions of pages and databases if there is no MentionRenderer.
+// By default, pages are linked to on notion.so.
+func WithPageURL(f func(id string) string) Option {
+	return func(r *Renderer) {
+		if r != nil && f != nil {
+			r.pageURL = f
+		}
+	}
+}
+
+// NewRenderer creates a new HTML renderer.
+// By default:
+// - classes are prefixed with "notion-"
+// - pages are linked to on notion.so
+// - mentions of users and dates are rendered as spans and mentions of pages and databases are rendered as links.
+func NewRenderer(options ...Option) *Renderer {
+	r := &Renderer{classPrefix: defaultClassPrefix, pageURL: notionURL}
+	for _, o := range options {
+		o(r)
+	}
+	return r
+}
+
+func notionURL(id string) string {
+	return "https://www.notion.so/" + strings.ReplaceAll(id, "-", "")
+}
+
+// RenderPage renders the title of the page as an <h1> element, followed by the children of the page.
+func (r *Renderer) RenderPage(page *notion.Page) template.HTML {
+	if page == nil {
+		return ""
+	}
+
+	var sb strings.Builder
+	for _, prop := range page.Properties {
+		if prop != nil && prop.Type == notion.DatabasePropertyTypeEnumTitle {
+			title := make([]*notion.RichText, 0, len(prop.Title))
+			for i := range prop.Title {
+				title = append(title, &prop.Title[i])
+			}
+			sb.WriteString("<h1>")
+			r.writeRichText(&sb, title)
+			sb.WriteString("</h1>\n")
+			break
+		}
+	}
+	r.writeBlocks(&sb, page.Children)
+
+	return template.HTML(sb.String()) //nolint:gosec
+}
+
+// RenderBlocks renders the blocks, and their children, as HTML.
+func (r *Renderer) RenderBlocks(blocks []*notion.Block) template.HTML {
+	var sb strings.Builder
+	r.writeBlocks(&sb, blocks)
+	return template.HTML(sb.String()) //nolint:gosec
+}
+
+// RenderRichText renders the rich text as inline HTML.
+func (r *Renderer) RenderRichText(rts []*notion.RichText) template.HTML {
+	var sb strings.Builder
+	r.writeRichText(&sb, rts)
+	return template.HTML(sb.String()) //nolint:gosec
+}
+
+func (r *Renderer) class(name string) string {
+	return template.HTMLEscapeString(r.classPrefix + name)
+}
+
+// listTag returns the tag of the list that the block is an item of, or the empty string if the block is not a list item.
+func listTag(b *notion.Block) string {
+	switch b.Type {
+	case notion.BlockTypeEnumBulletedListItem, notion.BlockTypeEnumToDo:
+		return "ul"
+	case notion.BlockTypeEnumNumberedListItem:
+		return "ol"
+	}
+	return ""
+}
+
+func (r *Renderer) writeBlocks(sb *strings.Builder, blocks []*notion.Block) {
+	var prev *notion.Block
+	for _, b := range blocks {
+		if b == nil || b.Type == notion.BlockTypeEnumUnsupported {
+			continue
+		}
+
+		// To do lists are kept separate from bulleted lists so that they can be styled differently.
+		if prev == nil || prev.Type != b.Type {
+			if prev != nil && listTag(prev) != "" {
+				sb.WriteString("</" + listTag(prev) + ">\n")
+			}
+			switch {
+			case b.Type == notion.BlockTypeEnumToDo:
+				sb.WriteString(`<ul class="` + r.class("to-do-list") + `">` + "\n")
+			case listTag(b) != "":
+				sb.WriteString("<" + listTag(b) + ">\n")
+			}
+		}
+		r.writeBlock(sb, b)
+		prev = b
+	}
+	if prev != nil && listTag(prev) != "" {
+		sb.WriteString("</" + listTag(prev) + ">\n")
+	}
+}
+
+func (r *Renderer) writeBlock(sb *strings.Builder, b *notion.Block) {
+	switch b.Type {
+	case notion.BlockTypeEnumHeading1:
+		r.writeElement(sb, "h1", b)
+	case notion.BlockTypeEnumHeading2:
+		r.writeElement(sb, "h2", b)
+	case notion.BlockTypeEnumHeading3:
+		r.writeElement(sb, "h3", b)
+	case notion.BlockTypeEnumBulletedListItem, notion.BlockTypeEnumNumberedListItem:
+		sb.WriteString("<li>")
+		r.writeRichText(sb, b.Text)
+		r.writeChildren(sb, b, false)
+		sb.WriteString("</li>\n")
+	case notion.BlockTypeEnumToDo:
+		sb.WriteString(`<li><input type="checkbox" disabled`)
+		if b.IsChecked() {
+			sb.WriteString(" checked")
+		}
+		sb.WriteString("> ")
+		r.writeRichText(sb, b.Text)
+		r.writeChildren(sb, b, false)
+		sb.WriteString("</li>\n")
+	case notion.BlockTypeEnumToggle:
+		sb.WriteString("<details>\n<summary>")
+		r.writeRichText(sb, b.Text)
+		sb.WriteString("</summary>\n")
+		r.writeBlocks(sb, b.Children)
+		sb.WriteString("</details>\n")
+	case notion.BlockTypeEnumChildPage, notion.BlockTypeEnumChildDatabase:
+		sb.WriteString(`<p><a class="` + r.class(strings.ReplaceAll(string(b.Type), "_", "-")) + `" href="` + safeURL(r.pageURL(b.ID.String())) + `">`)
+		sb.WriteString(template.HTMLEscapeString(b.GetTitle()))
+		sb.WriteString("</a></p>\n")
+	case notion.BlockTypeEnumLinkToPage:
+		if b.LinkToPage != nil {
+			href := safeURL(r.pageURL(b.LinkToPage.ID))
+			sb.WriteString(`<p><a class="` + r.class("link-to-page") + `" href="` + href + `">` + href + "</a></p>\n")
+		}
+	case notion.BlockTypeEnumQuote:
+		sb.WriteString("<blockquote>")
+		r.writeRichText(sb, b.Text)
+		r.writeChildren(sb, b, false)
+		sb.WriteString("</blockquote>\n")
+	case notion.BlockTypeEnumCallout:
+		sb.WriteString(`<div class="` + r.class("callout") + `">`)
+		if b.Callout != nil && b.Callout.Icon != nil {
+			r.writeIcon(sb, b.Callout.Icon)
+		}
+		sb.WriteString("<div>")
+		r.writeRichText(sb, b.Text)
+		r.writeChildren(sb, b, false)
+		sb.WriteString("</div></div>\n")
+	case notion.BlockTypeEnumCode:
+		sb.WriteString("<pre><code")
+		if b.Code != nil && b.Code.Language != "" && b.Code.Language != plainTextLanguage {
+			sb.WriteString(` class="language-` + template.HTMLEscapeString(strings.ReplaceAll(b.Code.Language, " ", "-")) + `"`)
+		}
+		sb.WriteString(">")
+		for _, rt := range b.Text {
+			if rt != nil {
+				sb.WriteString(template.HTMLEscapeString(runText(rt)))
+			}
+		}
+		sb.WriteString("</code></pre>\n")
+		if b.Code != nil {
+			r.writeCaption(sb, b.Code.Caption, "p")
+		}
+	case notion.BlockTypeEnumEquation:
+		if b.Equation != nil {
+			sb.WriteString(`<div class="` + r.class("equation") + `">` + template.HTMLEscapeString(b.Equation.Expression) + "</div>\n")
+		}
+	case notion.BlockTypeEnumDivider:
+		sb.WriteString("<hr>\n")
+	case notion.BlockTypeEnumImage, notion.BlockTypeEnumVideo, notion.BlockTypeEnumAudio, notion.BlockTypeEnumFile, notion.BlockTypeEnumPDF:
+		r.writeMedia(sb, b)
+	case notion.BlockTypeEnumBookmark, notion.BlockTypeEnumEmbed, notion.BlockTypeEnumLinkPreview:
+		if bm := bookmark(b); bm != nil {
+			sb.WriteString(`<p><a class="` + r.class(strings.ReplaceAll(string(b.Type), "_", "-")) + `" href="` + safeURL(bm.URL) + `">`)
+			if len(bm.Caption) != 0 {
+				r.writeRichText(sb, bm.Caption)
+			} else {
+				sb.WriteString(template.HTMLEscapeString(bm.URL))
+			}
+			sb.WriteString("</a></p>\n")
+		}
+	case notion.BlockTypeEnumTable:
+		r.writeTable(sb, b)
+	case notion.BlockTypeEnumColumnList, notion.BlockTypeEnumColumn:
+		sb.WriteString(`<div class="` + r.class(strings.ReplaceAll(string(b.Type), "_", "-")) + `">` + "\n")
+		r.writeBlocks(sb, b.Children)
+		sb.WriteString("</div>\n")
+	case notion.BlockTypeEnumSyncedBlock, notion.BlockTypeEnumTemplate:
+		r.writeBlocks(sb, b.Children)
+	case notion.BlockTypeEnumTableOfContents, notion.BlockTypeEnumBreadcrumb, notion.BlockTypeEnumTableRow:
+	default:
+		r.writeElement(sb, "p", b)
+	}
+}
+
+// writeIcon writes an emoji icon as a <span>, and a file icon as an <img>.
+func (r *Renderer) writeIcon(sb *strings.Builder, icon *notion.Icon) {
+	switch icon.Type {
+	case notion.IconTypeEnumEmoji:
+		sb.WriteString(`<span class="` + r.class("icon") + `">` + template.HTMLEscapeString(icon.Emoji) + "</span>")
+	default:
+		if u := icon.File.GetURL(); u != nil {
+			sb.WriteString(`<img class="` + r.class("icon") + `" src="` + safeURL(u.String()) + `" alt="">`)
+		}
+	}
+}
+
+// writeMedia writes images, videos, and audio as the corresponding elements, and other files as links,
+// in a <figure> with the caption of the block.
+func (r *Renderer) writeMedia(sb *strings.Builder, b *notion.Block) {
+	m := media(b)
+	if m == nil {
+		return
+	}
+	src := ""
+	if u := m.File.GetURL(); u != nil {
+		src = safeURL(u.String())
+	}
+
+	sb.WriteString(`<figure class="` + r.class(string(b.Type)) + `">`)
+	switch b.Type {
+	case notion.BlockTypeEnumImage:
+		sb.WriteString(`<img src="` + src + `" alt="` + template.HTMLEscapeString(plainText(m.Caption)) + `">`)
+	case notion.BlockTypeEnumVideo:
+		sb.WriteString(`<video controls src="` + src + `"></video>`)
+	case notion.BlockTypeEnumAudio:
+		sb.WriteString(`<audio controls src="` + src + `"></audio>`)
+	default:
+		name := m.File.Name
+		if name == "" {
+			name = path.Base(strings.TrimSuffix(src, "/"))
+		}
+		sb.WriteString(`<a href="` + src + `">` + template.HTMLEscapeString(name) + "</a>")
+	}
+	r.writeCaption(sb, m.Caption, "figcaption")
+	sb.WriteString("</figure>\n")
+}
+
+// writeCaption writes the caption, if there is one, in the given element.
+func (r *Renderer) writeCaption(sb *strings.Builder, caption []*notion.RichText, tag string) {
+	if len(caption) == 0 {
+		return
+	}
+	sb.WriteString(`<` + tag + ` class="` + r.class("caption") + `">`)
+	r.writeRichText(sb, caption)
+	sb.WriteString("</" + tag + ">")
+	if tag == "p" {
+		sb.WriteString("\n")
+	}
+}
+
+// writeTable writes the rows of the table, using <th> for the cells in the column and row headers.
+func (r *Renderer) writeTable(sb *strings.Builder, b *notion.Block) {
+	var rows []*notion.TableRow
+	for _, child := range b.Children {
+		if child != nil && child.TableRow != nil {
+			rows = append(rows, child.TableRow)
+		}
+	}
+	if len(rows) == 0 {
+		return
+	}
+
+	rowHeader := b.Table != nil && b.Table.HasRowHeader
+	sb.WriteString(`<table class="` + r.class("table") + `">` + "\n")
+	if b.Table != nil && b.Table.HasColumnHeader {
+		sb.WriteString("<thead>\n")
+		r.writeTableRow(sb, rows[0], true, rowHeader)
+		sb.WriteString("</thead>\n")
+		rows = rows[1:]
+	}
+	if len(rows) != 0 {
+		sb.WriteString("<tbody>\n")
+		for _, row := range rows {
+			r.writeTableRow(sb, row, false, rowHeader)
+		}
+		sb.WriteString("</tbody>\n")
+	}
+	sb.WriteString("</table>\n")
+}
+
+func (r *Renderer) writeTableRow(sb *strings.Builder, row *notion.TableRow, header, rowHeader bool) {
+	sb.WriteString("<tr>")
+	for i, cell := range row.Cells {
+		tag := "td"
+		if header || (rowHeader && i == 0) {
+			tag = "th"
+		}
+		sb.WriteString("<" + tag + ">")
+		r.writeRichText(sb, cell)
+		sb.WriteString("</" + tag + ">")
+	}
+	sb.WriteString("</tr>\n")
+}
+
+// writeElement writes the text of the block in the given element, followed by the children of the block.
+func (r *Renderer) writeElement(sb *strings.Builder, tag string, b *notion.Block) {
+	sb.WriteString("<" + tag + ">")
+	r.writeRichText(sb, b.Text)
+	sb.WriteString("</" + tag + ">\n")
+	r.writeChildren(sb, b, true)
+}
+
+// writeChildren writes the children of the block. If indent is true, then they are wrapped in an indented <div>.
+func (r *Renderer) writeChildren(sb *strings.Builder, b *notion.Block, indent bool) {
+	if len(b.Children) == 0 {
+		return
+	}
+
+	if indent {
+		sb.WriteString(`<div class="` + r.class("indent") + `">` + "\n")
+		r.writeBlocks(sb, b.Children)
+		sb.WriteString("</div>\n")
+		return
+	}
+
+	sb.WriteString("\n")
+	r.writeBlocks(sb, b.Children)
+}
+
+func (r *Renderer) writeRichText(sb *strings.Builder, rts []*notion.RichText) {
+	for _, rt := range rts {
+		if rt != nil {
+			r.writeRun(sb, rt)
+		}
+	}
+}
+
+// writeRun writes a single rich text object, wrapped in the elements for its annotations and link.
+func (r *Renderer) writeRun(sb *strings.Builder, rt *notion.RichText) {
+	var content string
+	switch rt.Type {
+	case notion.RichTextTypeEnumText:
+		if rt.Text == nil {
+			return
+		}
+		content = escapeText(rt.Text.Content)
+	case notion.RichTextTypeEnumEquation:
+		if rt.Equation == nil {
+			return
+		}
+		content = `<span class="` + r.class("equation") + `">` + template.HTMLEscapeString(rt.Equation.Expression) + "</span>"
+	case notion.RichTextTypeEnumMention:
+		if rt.Mention == nil {
+			return
+		}
+		if r.mention != nil {
+			content = string(r.mention(rt.Mention, runText(rt)))
+		} else {
+			content = r.defaultMention(rt)
+		}
+	default:
+		content = escapeText(runText(rt))
+	}
+	if content == "" {
+		return
+	}
+
+	var open, close []string
+	wrap := func(start, end string) {
+		open = append(open, start)
+		close = append([]string{end}, close...)
+	}
+
+	// Mentions of pages and databases are links already.
+	if link := linkURL(rt); link != "" && !(rt.Type == notion.RichTextTypeEnumMention && r.mention == nil && isPageMention(rt.Mention)) {
+		wrap(`<a href="`+safeURL(link)+`">`, "</a>")
+	}
+	a := rt.Annotations
+	if a.Color != "" && a.Color != notion.AnnotationColorEnumDefault {
+		wrap(`<span class="`+r.class("color-"+strings.ReplaceAll(string(a.Color), "_", "-"))+`">`, "</span>")
+	}
+	if a.Bold {
+		wrap("<strong>", "</strong>")
+	}
+	if a.Italic {
+		wrap("<em>", "</em>")
+	}
+	if a.Strikethrough {
+		wrap("<s>", "</s>")
+	}
+	if a.Underline {
+		wrap("<u>", "</u>")
+	}
+	if a.Code {
+		wrap("<code>", "</code>")
+	}
+
+	sb.WriteString(strings.Join(open, ""))
+	sb.WriteString(content)
+	sb.WriteString(strings.Join(close, ""))
+}
+
+func isPageMention(m *notion.Mention) bool {
+	return m != nil && (m.Type == notion.MentionTypeEnumPage || m.Type == notion.MentionTypeEnumDatabase)
+}
+
+// defaultMention renders a mention when there is no MentionRenderer.
+func (r *Renderer) defaultMention(rt *notion.RichText) string {
+	m := rt.Mention
+	text := escapeText(runText(rt))
+	switch m.Type {
+	case notion.MentionTypeEnumUser:
+		return `<span class="` + r.class("mention-user") + `">` + text + "</span>"
+	case notion.MentionTypeEnumPage, notion.MentionTypeEnumDatabase:
+		href := rt.HRef
+		if m.Ref != nil {
+			href = r.pageURL(m.Ref.String())
+		}
+		return `<a class="` + r.class("mention-"+string(m.Type)) + `" href="` + safeURL(href) + `">` + text + "</a>"
+	case notion.MentionTypeEnumData:
+		if m.Date != nil && !m.Date.Start.IsZero() {
+			return `<time class="` + r.class("mention-date") + `" datetime="` + dateTime(m.Date) + `">` + text + "</time>"
+		}
+	}
+	return `<span class="` + r.class("mention") + `">` + text + "</span>"
+}
+
+func media(b *notion.Block) *notion.Media {
+	switch b.Type {
+	case notion.BlockTypeEnumImage:
+		return b.Image
+	case notion.BlockTypeEnumVideo:
+		return b.Video
+	case notion.BlockTypeEnumFile:
+		return b.File
+	case notion.BlockTypeEnumPDF:
+		return b.PDF
+	case notion.BlockTypeEnumAudio:
+		return b.Audio
+	}
+	return nil
+}
+
+func bookmark(b *notion.Block) *notion.Bookmark {
+	switch b.Type {
+	case notion.BlockTypeEnumBookmark:
+		return b.Bookmark
+	case notion.BlockTypeEnumEmbed:
+		return b.Embed
+	case notion.BlockTypeEnumLinkPreview:
+		return b.LinkPreview
+	}
+	return nil
+}
+
+func plainText(rts []*notion.RichText) string {
+	var sb strings.Builder
+	for _, rt := range rts {
+		if rt != nil {
+			sb.WriteString(runText(rt))
+		}
+	}
+	return sb.String()
+}
+
+// runText returns the text of a single rich text object. Rich text that is built in code, rather than returned
+// by the Notion API, may only have the content of its text or equation, or the user of its mention, set.
+func runText(rt *notion.RichText) string {
+	switch {
+	case rt.Type == notion.RichTextTypeEnumText && rt.Text != nil:
+		return rt.Text.Content
+	case rt.Type == notion.RichTextTypeEnumEquation && rt.Equation != nil:
+		return rt.Equation.Expression
+	case rt.PlainText == "" && rt.Type == notion.RichTextTypeEnumMention && rt.Mention != nil && rt.Mention.User != nil:
+		return "@" + rt.Mention.User.Name
+	}
+	return rt.PlainText
+}
+
+func dateTime(d *notion.Date) string {
+	if d.HasTime {
+		return d.Start.Format(time.RFC3339)
+	}
+	return d.Start.Format("2006-01-02")
+}
+
+func linkURL(rt *notion.RichText) string {
+	if rt.Text != nil {
+		if u := rt.Text.GetURL(); u != nil {
+			return u.String()
+		}
+	}
+	return rt.HRef
+}
+
+// escapeText escapes the text for HTML, turning new lines into line breaks.
+func escapeText(s string) string {
+	return strings.ReplaceAll(template.HTMLEscapeString(s), "\n", "<br>")
+}
+
+// safeURL escapes the URL for use in an attribute, replacing URLs with unsafe schemes, like javascript:,
+// with the same value that html/template uses.
+func safeURL(s string) string {
+	if u, err := url.Parse(s); err != nil || (u.Scheme != "" && u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "mailto") {
+		return "#ZgotmplZ"
+	}
+	return template.HTMLEscapeString(s)
+}
@@ -0,0 +1,179 @@
+package html_test
+
+import (
+	"html/template"
+	"net/url"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/thedadams/gotion/html"
+	"github.com/thedadams/gotion/notion"
+	"github.com/thedadams/gotion/richtext"
+)
+
+func TestRenderRichText(t *testing.T) {
+	link, _ := url.Parse("https://example.com/docs")
+	id := notion.UUID4(uuid.MustParse("0b9c3b0a-5f5e-4a4c-9d2f-8d3b1f9e2c11"))
+	tests := []struct {
+		name string
+		rts  []*notion.RichText
+		want string
+	}{
+		{
+			name: "text",
+			rts:  richtext.FromPlainText("a <b> & c\nd"),
+			want: "a &lt;b&gt; &amp; c<br>d",
+		},
+		{
+			name: "annotations",
+			rts:  richtext.New().Bold("bold").Text(" ").Styled("both", notion.Annotations{Italic: true, Code: true}).Build(),
+			want: "<strong>bold</strong> <em><code>both</code></em>",
+		},
+		{
+			name: "color",
+			rts:  richtext.New().Color("red", notion.AnnotationColorEnumRedBackground).Build(),
+			want: `<span class="notion-color-red-background">red</span>`,
+		},
+		{
+			name: "link",
+			rts:  richtext.New().Link("docs", link).Build(),
+			want: `<a href="https://example.com/docs">docs</a>`,
+		},
+		{
+			name: "unsafe link",
+			rts:  []*notion.RichText{{Type: notion.RichTextTypeEnumText, Text: &notion.Text{Content: "click"}, HRef: "javascript:alert(1)"}},
+			want: `<a href="#ZgotmplZ">click</a>`,
+		},
+		{
+			name: "content without plain text",
+			rts:  []*notion.RichText{{Type: notion.RichTextTypeEnumText, Text: &notion.Text{Content: "built in code"}}},
+			want: "built in code",
+		},
+		{
+			name: "equation without plain text",
+			rts:  []*notion.RichText{{Type: notion.RichTextTypeEnumEquation, Equation: &notion.Equation{Expression: "x^2"}}},
+			want: `<span class="notion-equation">x^2</span>`,
+		},
+		{
+			name: "user mention",
+			rts:  richtext.New().Mention(&notion.User{Name: "Ada"}).Build(),
+			want: `<span class="notion-mention-user">@Ada</span>`,
+		},
+		{
+			name: "user mention without plain text",
+			rts: []*notion.RichText{{
+				Type:    notion.RichTextTypeEnumMention,
+				Mention: &notion.Mention{Type: notion.MentionTypeEnumUser, User: &notion.User{Name: "Ada"}},
+			}},
+			want: `<span class="notion-mention-user">@Ada</span>`,
+		},
+		{
+			name: "page mention",
+			rts:  richtext.New().MentionPage(id, "Plans").Build(),
+			want: `<a class="notion-mention-page" href="https://www.notion.so/0b9c3b0a5f5e4a4c9d2f8d3b1f9e2c11">Plans</a>`,
+		},
+	}
+
+	r := html.NewRenderer()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.RenderRichText(tt.rts); got != template.HTML(tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderBlocks(t *testing.T) {
+	checked := true
+	title := "Child"
+	tests := []struct {
+		name   string
+		blocks []*notion.Block
+		want   string
+	}{
+		{
+			name:   "heading",
+			blocks: []*notion.Block{{Type: notion.BlockTypeEnumHeading2, Text: richtext.FromPlainText("Section")}},
+			want:   "<h2>Section</h2>\n",
+		},
+		{
+			name: "paragraph with children",
+			blocks: []*notion.Block{{
+				Type:     notion.BlockTypeEnumParagraph,
+				Text:     richtext.FromPlainText("parent"),
+				Children: []*notion.Block{{Type: notion.BlockTypeEnumParagraph, Text: richtext.FromPlainText("child")}},
+			}},
+			want: "<p>parent</p>\n<div class=\"notion-indent\">\n<p>child</p>\n</div>\n",
+		},
+		{
+			name: "lists",
+			blocks: []*notion.Block{
+				{Type: notion.BlockTypeEnumBulletedListItem, Text: richtext.FromPlainText("a")},
+				{Type: notion.BlockTypeEnumBulletedListItem, Text: richtext.FromPlainText("b")},
+				{Type: notion.BlockTypeEnumNumberedListItem, Text: richtext.FromPlainText("one")},
+				{Type: notion.BlockTypeEnumToDo, Text: richtext.FromPlainText("done"), Checked: &checked},
+			},
+			want: "<ul>\n<li>a</li>\n<li>b</li>\n</ul>\n<ol>\n<li>one</li>\n</ol>\n" +
+				"<ul class=\"notion-to-do-list\">\n<li><input type=\"checkbox\" disabled checked> done</li>\n</ul>\n",
+		},
+		{
+			name: "toggle",
+			blocks: []*notion.Block{{
+				Type:     notion.BlockTypeEnumToggle,
+				Text:     richtext.FromPlainText("more"),
+				Children: []*notion.Block{{Type: notion.BlockTypeEnumParagraph, Text: richtext.FromPlainText("hidden")}},
+			}},
+			want: "<details>\n<summary>more</summary>\n<p>hidden</p>\n</details>\n",
+		},
+		{
+			name: "code",
+			blocks: []*notion.Block{{
+				Type: notion.BlockTypeEnumCode,
+				Text: []*notion.RichText{{Type: notion.RichTextTypeEnumText, Text: &notion.Text{Content: "if a < b {}"}}},
+				Code: &notion.Code{Language: "go"},
+			}},
+			want: "<pre><code class=\"language-go\">if a &lt; b {}</code></pre>\n",
+		},
+		{
+			name:   "child page",
+			blocks: []*notion.Block{{Type: notion.BlockTypeEnumChildPage, Title: &title}},
+			want:   "<p><a class=\"notion-child-page\" href=\"https://www.notion.so/00000000000000000000000000000000\">Child</a></p>\n",
+		},
+		{
+			name:   "unsupported",
+			blocks: []*notion.Block{{Type: notion.BlockTypeEnumUnsupported}, {Type: notion.BlockTypeEnumDivider}},
+			want:   "<hr>\n",
+		},
+	}
+
+	r := html.NewRenderer()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.RenderBlocks(tt.blocks); got != template.HTML(tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRendererOptions(t *testing.T) {
+	id := notion.UUID4(uuid.MustParse("0b9c3b0a-5f5e-4a4c-9d2f-8d3b1f9e2c11"))
+	r := html.NewRenderer(
+		html.WithClassPrefix("x-"),
+		html.WithPageURL(func(id string) string { return "/pages/" + id }),
+	)
+	rts := richtext.New().MentionDatabase(id, "Tasks").Build()
+	want := template.HTML(`<a class="x-mention-database" href="/pages/0b9c3b0a-5f5e-4a4c-9d2f-8d3b1f9e2c11">Tasks</a>`)
+	if got := r.RenderRichText(rts); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	r = html.NewRenderer(html.WithMentionRenderer(func(m *notion.Mention, plainText string) template.HTML {
+		return template.HTML("<b>" + template.HTMLEscapeString(plainText) + "</b>") //nolint:gosec
+	}))
+	if got := r.RenderRichText(rts); got != "<b>Tasks</b>" {
+		t.Errorf("got %q, want the mention renderer to be used", got)
+	}
+}
@@ -0,0 +1,92 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+)
+
+// A checkpointEntry is a line of a checkpoint file, for a row that has been created.
+type checkpointEntry struct {
+	Row  int    `json:"row"`
+	Hash string `json:"hash"`
+	ID   string `json:"id"`
+}
+
+// A checkpoint records the rows that have been created, as JSON Lines that are appended to a file.
+// A checkpoint without a file records nothing.
+type checkpoint struct {
+	mu   sync.Mutex
+	file *os.File
+	rows map[int]string
+}
+
+// openCheckpoint reads the rows that have been created from the file at the path, creating it if it doesn't exist.
+func openCheckpoint(path string) (*checkpoint, error) {
+	cp := &checkpoint{rows: make(map[int]string)}
+	if path == "" {
+		return cp, nil
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("opening the checkpoint: %w", err)
+	}
+	b, err := ioutil.ReadAll(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("reading the checkpoint: %w", err)
+	}
+
+	// A line that was only partly written when an import was interrupted is ignored, and its row is created again.
+	// It is removed from the file, so that the next entry is not appended to it.
+	complete := bytes.LastIndexByte(b, '\n') + 1
+	if complete != len(b) {
+		if err := f.Truncate(int64(complete)); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("removing a partly written entry from the checkpoint: %w", err)
+		}
+	}
+	for _, line := range bytes.Split(b[:complete], []byte("\n")) {
+		var entry checkpointEntry
+		if err := json.Unmarshal(line, &entry); err == nil {
+			cp.rows[entry.Row] = entry.Hash
+		}
+	}
+
+	cp.file = f
+	return cp, nil
+}
+
+// done returns true if the row has been created, with the same content.
+func (cp *checkpoint) done(r *row) bool {
+	hash, ok := cp.rows[r.number]
+	return ok && hash == r.hash
+}
+
+// record records that the row has been created as the page with the given id.
+func (cp *checkpoint) record(r *row, id string) error {
+	if cp.file == nil {
+		return nil
+	}
+	b, err := json.Marshal(checkpointEntry{Row: r.number, Hash: r.hash, ID: id})
+	if err != nil {
+		return err
+	}
+
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	if _, err := cp.file.Write(append(b, '\n')); err != nil {
+		return fmt.Errorf("could not be recorded in the checkpoint: %w", err)
+	}
+	return nil
+}
+
+func (cp *checkpoint) close() {
+	if cp.file != nil {
+		cp.file.Close()
+	}
+}
@@ -0,0 +1,84 @@
+package importer
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpenCheckpoint(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		wantDone []int
+		wantFile string
+	}{
+		{name: "new", contents: "", wantFile: ""},
+		{
+			name:     "complete",
+			contents: `{"row":1,"hash":"h1","id":"p1"}` + "\n" + `{"row":2,"hash":"h2","id":"p2"}` + "\n",
+			wantDone: []int{1, 2},
+			wantFile: `{"row":1,"hash":"h1","id":"p1"}` + "\n" + `{"row":2,"hash":"h2","id":"p2"}` + "\n",
+		},
+		{
+			name:     "partly written entry",
+			contents: `{"row":1,"hash":"h1","id":"p1"}` + "\n" + `{"row":2,"ha`,
+			wantDone: []int{1},
+			wantFile: `{"row":1,"hash":"h1","id":"p1"}` + "\n",
+		},
+		{
+			name:     "only a partly written entry",
+			contents: `{"row":1,"hash":"h1","id":"p1"}`,
+			wantFile: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "checkpoint")
+			if tt.contents != "" {
+				if err := ioutil.WriteFile(path, []byte(tt.contents), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			cp, err := openCheckpoint(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer cp.close()
+			for _, number := range tt.wantDone {
+				if !cp.done(&row{number: number, hash: "h" + string(rune('0'+number))}) {
+					t.Errorf("row %d is not done", number)
+				}
+			}
+			if len(cp.rows) != len(tt.wantDone) {
+				t.Errorf("got %d done rows, want %d", len(cp.rows), len(tt.wantDone))
+			}
+			if cp.done(&row{number: 1, hash: "changed"}) {
+				t.Error("row 1 is done with different content")
+			}
+
+			b, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != tt.wantFile {
+				t.Errorf("got checkpoint %q, want %q", b, tt.wantFile)
+			}
+
+			// The next entry starts on a line of its own.
+			if err := cp.record(&row{number: 3, hash: "h3"}, "p3"); err != nil {
+				t.Fatal(err)
+			}
+			cp.close()
+			cp, err = openCheckpoint(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !cp.done(&row{number: 3, hash: "h3"}) {
+				t.Error("row 3 is not done after it was recorded")
+			}
+		})
+	}
+}
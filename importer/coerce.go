@@ -0,0 +1,285 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thedadams/gotion/export"
+	"github.com/thedadams/gotion/notion"
+)
+
+// setProperty coerces the value to the type of the database property, and sets it in the page properties.
+// Empty values are not set.
+func (im *Importer) setProperty(
+	ctx context.Context, s *schema, props *notion.PageProperties, prop *notion.DatabaseProperty, value interface{},
+) error {
+	if isEmpty(value) {
+		return nil
+	}
+
+	name := prop.Name
+	switch prop.Type {
+	case notion.DatabasePropertyTypeEnumTitle:
+		text, err := str(value)
+		if err != nil {
+			return err
+		}
+		*props = append(*props, &notion.PageProperty{Name: name, Type: prop.Type, Title: notion.FromPlainText(text)})
+	case notion.DatabasePropertyTypeEnumRichText:
+		text, err := str(value)
+		if err != nil {
+			return err
+		}
+		*props = append(*props, &notion.PageProperty{Name: name, Type: prop.Type, RichText: notion.FromPlainText(text)})
+	case notion.DatabasePropertyTypeEnumNumber:
+		n, err := number(value)
+		if err != nil {
+			return err
+		}
+		return props.SetNumber(name, n)
+	case notion.DatabasePropertyTypeEnumSelect:
+		option, err := trimmed(value)
+		if err != nil {
+			return err
+		}
+		if err := s.ensureOptions(ctx, prop, []string{option}); err != nil {
+			return err
+		}
+		return props.SetSelect(name, option)
+	case notion.DatabasePropertyTypeEnumMultiSelect:
+		options, err := im.list(value)
+		if err != nil {
+			return err
+		}
+		if err := s.ensureOptions(ctx, prop, options); err != nil {
+			return err
+		}
+		return props.SetMultiSelect(name, options...)
+	case notion.DatabasePropertyTypeEnumDate:
+		text, err := trimmed(value)
+		if err != nil {
+			return err
+		}
+		d, err := im.date(text)
+		if err != nil {
+			return err
+		}
+		return props.SetDate(name, d)
+	case notion.DatabasePropertyTypeEnumPeople:
+		users, err := im.list(value)
+		if err != nil {
+			return err
+		}
+		ids := make([]notion.UUID4, 0, len(users))
+		for _, u := range users {
+			id, err := s.userID(ctx, u)
+			if err != nil {
+				return err
+			}
+			ids = append(ids, id)
+		}
+		return props.SetPeople(name, ids...)
+	case notion.DatabasePropertyTypeEnumFile:
+		urls, err := im.list(value)
+		if err != nil {
+			return err
+		}
+		files := make([]notion.File, 0, len(urls))
+		for _, raw := range urls {
+			u, err := parseURL(raw)
+			if err != nil {
+				return err
+			}
+			f := notion.NewExternalFile(u)
+			f.Name = raw
+			files = append(files, f)
+		}
+		return props.SetFiles(name, files...)
+	case notion.DatabasePropertyTypeEnumCheckbox:
+		checked, err := checkbox(value)
+		if err != nil {
+			return err
+		}
+		return props.SetCheckbox(name, checked)
+	case notion.DatabasePropertyTypeEnumURL:
+		raw, err := trimmed(value)
+		if err != nil {
+			return err
+		}
+		u, err := parseURL(raw)
+		if err != nil {
+			return err
+		}
+		return props.SetURL(name, u)
+	case notion.DatabasePropertyTypeEnumEmail:
+		address, err := trimmed(value)
+		if err != nil {
+			return err
+		}
+		return props.SetEmail(name, address)
+	case notion.DatabasePropertyTypeEnumPhoneNumber:
+		phone, err := trimmed(value)
+		if err != nil {
+			return err
+		}
+		return props.SetPhoneNumber(name, phone)
+	case notion.DatabasePropertyTypeEnumRelation:
+		values, err := im.list(value)
+		if err != nil {
+			return err
+		}
+		ids := make([]notion.UUID4, 0, len(values))
+		for _, v := range values {
+			id, err := uuid.Parse(v)
+			if err != nil {
+				return fmt.Errorf("%q is not a page id", v)
+			}
+			ids = append(ids, notion.UUID4(id))
+		}
+		return props.SetRelations(name, ids...)
+	}
+	return nil
+}
+
+// date parses the date, or the range of dates separated by a slash, with the layouts of the importer.
+func (im *Importer) date(s string) (notion.Date, error) {
+	if start, hasTime, err := im.parseTime(s); err == nil {
+		return notion.Date{Start: start, HasTime: hasTime}, nil
+	}
+
+	// The layouts can have slashes themselves, so every slash is tried as the separator of the range.
+	for i := strings.Index(s, "/"); i >= 0; i = nextIndex(s, "/", i) {
+		start, startHasTime, err := im.parseTime(strings.TrimSpace(s[:i]))
+		if err != nil {
+			continue
+		}
+		end, endHasTime, err := im.parseTime(strings.TrimSpace(s[i+1:]))
+		if err != nil {
+			continue
+		}
+		return notion.Date{Start: start, End: end, HasTime: startHasTime || endHasTime}, nil
+	}
+	return notion.Date{}, fmt.Errorf("%q is not a date", s)
+}
+
+// parseTime parses the time with the first layout that matches, and returns whether the layout has a time.
+func (im *Importer) parseTime(s string) (time.Time, bool, error) {
+	for _, layout := range im.dateFormats {
+		if t, err := time.ParseInLocation(layout, s, im.location); err == nil {
+			return t, strings.Contains(layout, "15") || strings.Contains(layout, "3:") || strings.Contains(layout, "03"), nil
+		}
+	}
+	return time.Time{}, false, fmt.Errorf("%q is not a date", s)
+}
+
+func nextIndex(s, sep string, after int) int {
+	i := strings.Index(s[after+1:], sep)
+	if i < 0 {
+		return -1
+	}
+	return after + 1 + i
+}
+
+// list returns the values of a list: the elements of a JSON array, or the values of a CSV cell split by the separator.
+func (im *Importer) list(value interface{}) ([]string, error) {
+	var values []string
+	switch v := value.(type) {
+	case []interface{}:
+		for _, e := range v {
+			s, err := trimmed(e)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, s)
+		}
+	case string:
+		values = export.SplitList(v, im.separator)
+	default:
+		s, err := str(v)
+		if err != nil {
+			return nil, err
+		}
+		values = []string{s}
+	}
+
+	result := make([]string, 0, len(values))
+	for _, s := range values {
+		if s = strings.TrimSpace(s); s != "" {
+			result = append(result, s)
+		}
+	}
+	return result, nil
+}
+
+// str returns the value as a string. Lists and objects are not strings.
+func str(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case nil:
+		return "", nil
+	}
+	return "", fmt.Errorf("%v is not a single value", value)
+}
+
+func trimmed(value interface{}) (string, error) {
+	s, err := str(value)
+	return strings.TrimSpace(s), err
+}
+
+func number(value interface{}) (float64, error) {
+	s, err := trimmed(value)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", s)
+	}
+	return n, nil
+}
+
+func checkbox(value interface{}) (bool, error) {
+	s, err := trimmed(value)
+	if err != nil {
+		return false, err
+	}
+	switch strings.ToLower(s) {
+	case "true", "t", "yes", "y", "1", "x", "checked":
+		return true, nil
+	case "false", "f", "no", "n", "0", "unchecked":
+		return false, nil
+	}
+	return false, fmt.Errorf("%q is not a checkbox value", s)
+}
+
+func parseURL(s string) (*url.URL, error) {
+	u, err := url.Parse(s)
+	if err != nil || u.Scheme == "" {
+		return nil, fmt.Errorf("%q is not a URL", s)
+	}
+	return u, nil
+}
+
+// isEmpty returns true for null, blank strings, and empty lists.
+func isEmpty(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(v) == ""
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
@@ -0,0 +1,366 @@
+// Package importer creates pages in a database in the Notion API from the rows of CSV or JSON Lines,
+// the inverse of the export package.
+//
+// The columns of CSV, or the keys of JSON Lines, are matched to the properties of the database by name,
+// and the values are coerced to the type of each property. Columns that don't match a property are ignored,
+// as are the properties that cannot be set, like formulas and rollups. Options of select and multi-select
+// properties that don't exist are added to the database.
+//
+//	report, err := importer.New(client, importer.WithCheckpoint("tasks.checkpoint")).CSV(ctx, f, databaseID)
+package importer
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thedadams/gotion"
+	"github.com/thedadams/gotion/export"
+	"github.com/thedadams/gotion/notion"
+)
+
+// DefaultListSeparator is the default separator of the values of lists, like multi-selects, in CSV.
+// It is the same as that of the export package, so that exported lists can be imported again.
+const DefaultListSeparator = export.DefaultListSeparator
+
+// maxConcurrency is the most rows that are created at the same time, when the rate limit of the client is not limited.
+const maxConcurrency = 16
+
+// An Option is a way of customizing an Importer.
+type Option func(*Importer)
+
+// WithConcurrency sets the number of rows that are created at the same time.
+// Every request still waits on the rate limiter of the client, so by default the concurrency is the rate limit
+// of the client, in requests per second.
+func WithConcurrency(n int) Option {
+	return func(im *Importer) {
+		if n > 0 {
+			im.concurrency = n
+		}
+	}
+}
+
+// WithCheckpoint records the rows that have been created in the file at the given path, and skips them on the next import.
+// An import that was interrupted, or had errors, can then be run again with the same input to create the remaining rows.
+// The rows are identified by their number and content, so the checkpoint should only be used with the same input.
+func WithCheckpoint(path string) Option {
+	return func(im *Importer) {
+		im.checkpointPath = path
+	}
+}
+
+// WithListSeparator sets the separator of the values of lists, like multi-selects, in CSV. The default is DefaultListSeparator.
+// Spaces around the separator are ignored, and values can be quoted, as described in export.SplitList.
+// In JSON Lines, lists are arrays.
+func WithListSeparator(sep string) Option {
+	return func(im *Importer) {
+		im.separator = sep
+	}
+}
+
+// WithDateFormats adds layouts, as in time.Parse, for the values of dates.
+// RFC 3339, with or without seconds and time zone, and dates like 2006-01-02 are always accepted.
+// The start and end of a range are separated by a slash, as in ISO 8601.
+func WithDateFormats(layouts ...string) Option {
+	return func(im *Importer) {
+		im.dateFormats = append(layouts, im.dateFormats...)
+	}
+}
+
+// WithLocation sets the location of dates that have a time but no time zone. The default is UTC.
+func WithLocation(loc *time.Location) Option {
+	return func(im *Importer) {
+		im.location = loc
+	}
+}
+
+// An Importer creates pages in databases in the Notion API from CSV and JSON Lines.
+type Importer struct {
+	client         *gotion.Client
+	concurrency    int
+	checkpointPath string
+	separator      string
+	dateFormats    []string
+	location       *time.Location
+}
+
+// New returns an Importer that uses the client to create pages.
+func New(c *gotion.Client, options ...Option) *Importer {
+	im := &Importer{
+		client:      c,
+		concurrency: concurrency(c),
+		separator:   DefaultListSeparator,
+		dateFormats: []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"},
+		location:    time.UTC,
+	}
+	for _, opt := range options {
+		opt(im)
+	}
+	return im
+}
+
+// concurrency returns the rate limit of the client, in requests per second, as the default number of rows created at the same time.
+func concurrency(c *gotion.Client) int {
+	limit := float64(c.RateLimitStats().Limit)
+	if math.IsInf(limit, 1) || limit > maxConcurrency {
+		return maxConcurrency
+	}
+	if limit < 1 {
+		return 1
+	}
+	return int(math.Ceil(limit))
+}
+
+// A Report is the result of an import.
+type Report struct {
+	// Created is the number of rows that were created as pages.
+	Created int
+	// Skipped is the number of rows that had already been created, according to the checkpoint.
+	Skipped int
+	// IgnoredColumns are the columns that don't match a property of the database that can be set.
+	IgnoredColumns []string
+	// Errors are the rows that could not be created, in order.
+	Errors []*RowError
+}
+
+// Err returns an error if any row could not be created.
+func (r *Report) Err() error {
+	if len(r.Errors) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d rows could not be imported; the first error is %w", len(r.Errors), r.Errors[0])
+}
+
+// A RowError is the error of a row that could not be created.
+type RowError struct {
+	// Row is the number of the row, starting at 1 for the first row after the header of CSV, or the first line of JSON Lines.
+	Row int
+	Err error
+}
+
+// Error implements the error interface for RowError
+func (re *RowError) Error() string {
+	return fmt.Sprintf("row %d: %v", re.Row, re.Err)
+}
+
+// Unwrap returns the error of the row, for errors.Is and errors.As.
+func (re *RowError) Unwrap() error {
+	return re.Err
+}
+
+// A row is a row of the input, with its values by column, or the error reading it.
+type row struct {
+	number int
+	hash   string
+	values map[string]interface{}
+	err    error
+}
+
+// CSV creates a page in the database with the given id for each row of the CSV, which must have a header row of column names.
+// An error is returned if the import could not run, for instance because the database or checkpoint could not be read.
+// The import stops if the checkpoint cannot be written, and the error names the row that was created without being recorded.
+// Errors of individual rows are in the report.
+func (im *Importer) CSV(ctx context.Context, r io.Reader, databaseID string) (*Report, error) {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading the CSV header: %w", err)
+	}
+	cr.FieldsPerRecord = len(header)
+
+	number := 0
+	return im.run(ctx, databaseID, header, func() (*row, error) {
+		record, err := cr.Read()
+		if pe, ok := err.(*csv.ParseError); ok && pe.Err == csv.ErrFieldCount {
+			number++
+			return &row{number: number, err: err}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		number++
+
+		values := make(map[string]interface{}, len(header))
+		for i, column := range header {
+			values[column] = record[i]
+		}
+		return &row{number: number, hash: hash(strings.Join(record, "\x00")), values: values}, nil
+	})
+}
+
+// JSONL creates a page in the database with the given id for each line of the JSON Lines, which must each be an object
+// with the property names as keys. Lists, like multi-selects, are arrays. Empty lines are skipped.
+// An error is returned if the import could not run, for instance because the database or checkpoint could not be read.
+// The import stops if the checkpoint cannot be written, and the error names the row that was created without being recorded.
+// Errors of individual rows are in the report.
+func (im *Importer) JSONL(ctx context.Context, r io.Reader, databaseID string) (*Report, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16*1024*1024)
+
+	number := 0
+	return im.run(ctx, databaseID, nil, func() (*row, error) {
+		for scanner.Scan() {
+			number++
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+
+			d := json.NewDecoder(strings.NewReader(line))
+			d.UseNumber()
+			values := make(map[string]interface{})
+			if err := d.Decode(&values); err != nil {
+				return &row{number: number, err: err}, nil
+			}
+			return &row{number: number, hash: hash(line), values: values}, nil
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	})
+}
+
+// run creates the rows returned by next, until it returns io.EOF. The columns are used to report ignored columns;
+// for JSON Lines, they are collected from the rows.
+func (im *Importer) run(ctx context.Context, databaseID string, columns []string, next func() (*row, error)) (*Report, error) {
+	db, err := im.client.GetDatabase(ctx, databaseID)
+	if err != nil {
+		return nil, err
+	}
+	cp, err := openCheckpoint(im.checkpointPath)
+	if err != nil {
+		return nil, err
+	}
+	defer cp.close()
+
+	// The import is stopped when the checkpoint cannot be written, since the rows created after that would be created
+	// again when the import is resumed.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var checkpointErr error
+
+	s := &schema{client: im.client, database: db}
+	report := new(Report)
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		rows    = make(chan *row)
+		ignored = make(map[string]bool)
+	)
+	fail := func(number int, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		report.Errors = append(report.Errors, &RowError{Row: number, Err: err})
+	}
+
+	for i := 0; i < im.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for r := range rows {
+				page, err := im.create(ctx, s, databaseID, r)
+				if err != nil {
+					fail(r.number, err)
+					continue
+				}
+				err = cp.record(r, page.ID.String())
+
+				mu.Lock()
+				report.Created++
+				if err != nil && checkpointErr == nil {
+					checkpointErr = fmt.Errorf("row %d was created as the page %s, but %w", r.number, page.ID.String(), err)
+					cancel()
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, column := range columns {
+		ignored[column] = s.settable(column) == nil
+	}
+
+	var readErr error
+	for ctx.Err() == nil {
+		r, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			readErr = err
+			break
+		}
+		if r.err != nil {
+			fail(r.number, r.err)
+			continue
+		}
+
+		if columns == nil {
+			for column := range r.values {
+				if _, ok := ignored[column]; !ok {
+					ignored[column] = s.settable(column) == nil
+				}
+			}
+		}
+		if cp.done(r) {
+			report.Skipped++
+			continue
+		}
+		rows <- r
+	}
+	close(rows)
+	wg.Wait()
+
+	for column, ignore := range ignored {
+		if ignore {
+			report.IgnoredColumns = append(report.IgnoredColumns, column)
+		}
+	}
+	sort.Strings(report.IgnoredColumns)
+	sort.Slice(report.Errors, func(i, j int) bool {
+		return report.Errors[i].Row < report.Errors[j].Row
+	})
+
+	if readErr != nil {
+		return report, readErr
+	}
+	if checkpointErr != nil {
+		return report, checkpointErr
+	}
+	return report, ctx.Err()
+}
+
+// create creates the page for the row.
+func (im *Importer) create(ctx context.Context, s *schema, databaseID string, r *row) (*notion.Page, error) {
+	var props notion.PageProperties
+	for column, value := range r.values {
+		prop := s.settable(column)
+		if prop == nil {
+			continue
+		}
+		if err := im.setProperty(ctx, s, &props, prop, value); err != nil {
+			return nil, fmt.Errorf("column %q: %w", column, err)
+		}
+	}
+
+	return im.client.CreatePage(ctx, &notion.Page{
+		Parent:     notion.Parent{Type: notion.ParentTypeEnumDatabase, ID: databaseID},
+		Properties: props,
+	})
+}
+
+func hash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
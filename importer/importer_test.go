@@ -0,0 +1,96 @@
+package importer_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/thedadams/gotion"
+	"github.com/thedadams/gotion/gotiontest"
+	"github.com/thedadams/gotion/importer"
+	"github.com/thedadams/gotion/notion"
+)
+
+func TestCheckpointResume(t *testing.T) {
+	s := gotiontest.NewServer()
+	defer s.Close()
+	db, err := s.AddDatabase(&notion.Database{
+		Properties: notion.DatabaseProperties{
+			{Name: "Name", Type: notion.DatabasePropertyTypeEnumTitle},
+			{Name: "Points", Type: notion.DatabasePropertyTypeEnumNumber},
+			{Name: "Tags", Type: notion.DatabasePropertyTypeEnumMultiSelect},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkpoint := filepath.Join(t.TempDir(), "tasks.checkpoint")
+
+	// Each run imports the same rows, except for the ones that failed before, which are fixed.
+	tests := []struct {
+		name        string
+		csv         string
+		wantCreated int
+		wantSkipped int
+		wantErrors  []int
+		wantPages   int
+	}{
+		{
+			name:        "first run",
+			csv:         "Name,Points,Tags\nLaunch,3,\"a, b\"\nReview,lots,c\nShip,5,\n",
+			wantCreated: 2,
+			wantErrors:  []int{2},
+			wantPages:   2,
+		},
+		{
+			name:        "fixed row",
+			csv:         "Name,Points,Tags\nLaunch,3,\"a, b\"\nReview,8,c\nShip,5,\n",
+			wantCreated: 1,
+			wantSkipped: 2,
+			wantPages:   3,
+		},
+		{
+			name:        "nothing left",
+			csv:         "Name,Points,Tags\nLaunch,3,\"a, b\"\nReview,8,c\nShip,5,\n",
+			wantSkipped: 3,
+			wantPages:   3,
+		},
+		{
+			name:        "added row",
+			csv:         "Name,Points,Tags\nLaunch,3,\"a, b\"\nReview,8,c\nShip,5,\nCelebrate,1,\n",
+			wantCreated: 1,
+			wantSkipped: 3,
+			wantPages:   4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			im := importer.New(s.NewClient(), importer.WithCheckpoint(checkpoint), importer.WithConcurrency(1))
+			report, err := im.CSV(ctx, strings.NewReader(tt.csv), db.ID.String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if report.Created != tt.wantCreated || report.Skipped != tt.wantSkipped {
+				t.Errorf("got %d created and %d skipped, want %d and %d", report.Created, report.Skipped, tt.wantCreated, tt.wantSkipped)
+			}
+			var rows []int
+			for _, rowErr := range report.Errors {
+				rows = append(rows, rowErr.Row)
+			}
+			if len(rows) != len(tt.wantErrors) || (len(rows) != 0 && rows[0] != tt.wantErrors[0]) {
+				t.Errorf("got errors in rows %v, want %v", rows, tt.wantErrors)
+			}
+
+			pages, err := s.NewClient().QueryDatabase(ctx, db.ID.String(), &gotion.DBQuery{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(pages) != tt.wantPages {
+				t.Errorf("got %d pages in the database, want %d", len(pages), tt.wantPages)
+			}
+		})
+	}
+}
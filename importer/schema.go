@@ -0,0 +1,110 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/thedadams/gotion"
+	"github.com/thedadams/gotion/notion"
+)
+
+// A schema is the database that is imported into, shared by the rows that are created at the same time.
+type schema struct {
+	client   *gotion.Client
+	database *notion.Database
+
+	// mu guards the options of the properties of the database, which are added to as rows are imported.
+	mu sync.Mutex
+
+	usersOnce sync.Once
+	users     map[string]notion.UUID4
+	usersErr  error
+}
+
+// settable returns the property for the column, matching the name exactly or otherwise ignoring case,
+// or nil if there is no such property or it cannot be set.
+func (s *schema) settable(column string) *notion.DatabaseProperty {
+	var match *notion.DatabaseProperty
+	for _, prop := range s.database.Properties {
+		if prop == nil {
+			continue
+		}
+		if prop.Name == column {
+			match = prop
+			break
+		}
+		if match == nil && strings.EqualFold(prop.Name, column) {
+			match = prop
+		}
+	}
+
+	if match == nil || match.Type.IsReadOnly() || !match.Type.IsValidEnum() {
+		return nil
+	}
+	return match
+}
+
+// ensureOptions adds the options that the select or multi-select property doesn't have yet to the database.
+// The Notion API would add them when the page is created, but rows that are created at the same time
+// could then add the same option twice.
+func (s *schema) ensureOptions(ctx context.Context, prop *notion.DatabaseProperty, names []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing := make(map[string]bool, len(prop.SelectOptions))
+	for _, o := range prop.SelectOptions {
+		existing[o.Name] = true
+	}
+	desired := *prop
+	desired.SelectOptions = append([]notion.SelectOption{}, prop.SelectOptions...)
+	for _, name := range names {
+		if !existing[name] {
+			existing[name] = true
+			desired.SelectOptions = append(desired.SelectOptions, notion.SelectOption{Name: name})
+		}
+	}
+	if len(desired.SelectOptions) == len(prop.SelectOptions) {
+		return nil
+	}
+
+	diff := notion.DiffSchema(notion.DatabaseProperties{prop}, notion.DatabaseProperties{&desired})
+	if _, err := s.client.ApplySchema(ctx, s.database.ID.String(), diff, false); err != nil {
+		return fmt.Errorf("adding options to property %q: %w", prop.Name, err)
+	}
+	prop.SelectOptions = desired.SelectOptions
+	return nil
+}
+
+// userID returns the id of the user with the given id, name, or email.
+// The users of the workspace are only listed the first time a user is given by name or email.
+func (s *schema) userID(ctx context.Context, user string) (notion.UUID4, error) {
+	if id, err := uuid.Parse(user); err == nil {
+		return notion.UUID4(id), nil
+	}
+
+	s.usersOnce.Do(func() {
+		s.users = make(map[string]notion.UUID4)
+		it := s.client.IterateUsers(nil)
+		for it.Next(ctx) {
+			u := it.Value()
+			for _, key := range []string{u.Name, u.Email} {
+				if key != "" {
+					s.users[strings.ToLower(key)] = u.ID
+				}
+			}
+		}
+		s.usersErr = it.Err()
+	})
+	if s.usersErr != nil {
+		return notion.UUID4{}, fmt.Errorf("listing users: %w", s.usersErr)
+	}
+
+	id, ok := s.users[strings.ToLower(user)]
+	if !ok {
+		return notion.UUID4{}, fmt.Errorf("no user with the name or email %q", user)
+	}
+	return id, nil
+}
@@ -0,0 +1,263 @@
+package gotion
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/thedadams/gotion/notion"
+)
+
+// cursorIterator holds the state common to all iterators over paginated endpoints in the Notion API.
+// Results are requested one page at a time, so only a single page of results is held in memory.
+type cursorIterator struct {
+	client      *Client
+	url         string
+	body        paginated
+	pageSize    int
+	batchCursor *string
+	cursor      *string
+	hasMore     bool
+	err         error
+
+	// newBuffer returns an empty slice for a batch of results, which the typed iterators read their values from.
+	newBuffer func() list
+	buf       list
+	pos       int
+}
+
+func newCursorIterator(c *Client, url string, body paginated, cursor *string) cursorIterator {
+	return cursorIterator{client: c, url: url, body: body, cursor: cursor, hasMore: true}
+}
+
+// fetch gets the next page of results from the Notion API and appends them to results.
+// If body is nil, then a GET request is made. Otherwise, the body is POSTed with the current cursor.
+// It returns false if there are no more results or if an error occurred.
+func (ci *cursorIterator) fetch(ctx context.Context, results list) bool {
+	if ci.err != nil || !ci.hasMore {
+		return false
+	}
+
+	var (
+		r   = Result{Results: results}
+		err error
+	)
+	if ci.body == nil {
+		err = ci.client.makeRequest(ctx, http.MethodGet, addQueryParams(ci.url, ci.cursor, ci.pageSize), nil, &r)
+	} else {
+		var bodyBytes []byte
+		if bodyBytes, err = ci.body.setPage(ci.cursor, pageSize(ci.pageSize)); err == nil {
+			err = ci.client.makeRequest(ctx, http.MethodPost, ci.url, bytes.NewReader(bodyBytes), &r)
+		}
+	}
+	if err != nil {
+		ci.err = err
+		return false
+	}
+
+	ci.batchCursor = ci.cursor
+	ci.hasMore = r.HasMore && r.NextCursor != nil
+	ci.cursor = r.NextCursor
+	return true
+}
+
+// Next advances the iterator to the next value, fetching more results from the Notion API as needed.
+// It returns false when there are no more values or an error occurred. Use Err to distinguish the two.
+func (ci *cursorIterator) Next(ctx context.Context) bool {
+	ci.pos++
+	for ci.buf == nil || ci.pos >= ci.buf.Len() {
+		ci.buf, ci.pos = ci.newBuffer(), 0
+		if !ci.fetch(ctx, ci.buf) {
+			ci.buf = nil
+			return false
+		}
+	}
+	return true
+}
+
+// Err returns the error, if any, that was encountered during iteration.
+func (ci *cursorIterator) Err() error {
+	return ci.err
+}
+
+// Cursor returns the cursor used to request the batch of results that the current value came from.
+// Resuming from this cursor may return values that have already been seen.
+// A nil cursor means the current value came from the first batch of results.
+func (ci *cursorIterator) Cursor() *string {
+	return ci.batchCursor
+}
+
+// NextCursor returns the cursor that will be used to request the next batch of results.
+// Resuming from this cursor will skip any results that have been fetched, but not yet returned by Next.
+// If there are no more results, then nil is returned.
+func (ci *cursorIterator) NextCursor() *string {
+	if !ci.hasMore {
+		return nil
+	}
+	return ci.cursor
+}
+
+// PageIterator iterates over pages from the Notion API, such as the results of a database query.
+type PageIterator struct {
+	cursorIterator
+}
+
+// Value returns the current page of the iterator.
+func (pi *PageIterator) Value() *notion.Page {
+	if buf, ok := pi.buf.(*notion.Pages); ok {
+		return (*buf)[pi.pos]
+	}
+	return nil
+}
+
+// BlockIterator iterates over blocks from the Notion API, such as the children of a block.
+type BlockIterator struct {
+	cursorIterator
+}
+
+// Value returns the current block of the iterator.
+func (bi *BlockIterator) Value() *notion.Block {
+	if buf, ok := bi.buf.(*notion.Blocks); ok {
+		return (*buf)[bi.pos]
+	}
+	return nil
+}
+
+// UserIterator iterates over users from the Notion API.
+type UserIterator struct {
+	cursorIterator
+}
+
+// Value returns the current user of the iterator.
+func (ui *UserIterator) Value() *notion.User {
+	if buf, ok := ui.buf.(*notion.Users); ok {
+		return (*buf)[ui.pos]
+	}
+	return nil
+}
+
+// DatabaseIterator iterates over databases from the Notion API.
+type DatabaseIterator struct {
+	cursorIterator
+}
+
+// Value returns the current database of the iterator.
+func (di *DatabaseIterator) Value() *notion.Database {
+	if buf, ok := di.buf.(*notion.Databases); ok {
+		return (*buf)[di.pos]
+	}
+	return nil
+}
+
+// A SearchResult is a single result from searching the Notion API.
+// Exactly one of Page and Database is set.
+type SearchResult struct {
+	Page     *notion.Page
+	Database *notion.Database
+}
+
+type searchResults []*SearchResult
+
+// Len returns the number of results in the slice.
+func (sr *searchResults) Len() int {
+	if sr == nil {
+		return 0
+	}
+	return len(*sr)
+}
+
+// UnmarshalJSON appends the unmarshaled results to the slice, keeping the order returned by the Notion API.
+func (sr *searchResults) UnmarshalJSON(b []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	for _, r := range raw {
+		obj := notion.Object{}
+		if err := json.Unmarshal(r, &obj); err != nil {
+			return err
+		}
+		switch obj.Object {
+		case notion.FilterObjectConditionEnumPage:
+			page := &notion.Page{}
+			if err := json.Unmarshal(r, page); err != nil {
+				return err
+			}
+			*sr = append(*sr, &SearchResult{Page: page})
+		case notion.FilterObjectConditionEnumDatabase:
+			db := &notion.Database{}
+			if err := json.Unmarshal(r, db); err != nil {
+				return err
+			}
+			*sr = append(*sr, &SearchResult{Database: db})
+		}
+	}
+
+	return nil
+}
+
+// SearchIterator iterates over the results of searching the Notion API.
+type SearchIterator struct {
+	cursorIterator
+}
+
+// Value returns the current search result of the iterator.
+func (si *SearchIterator) Value() *SearchResult {
+	if buf, ok := si.buf.(*searchResults); ok {
+		return (*buf)[si.pos]
+	}
+	return nil
+}
+
+// IterateDatabase returns an iterator over the results of querying the database with the given id in the Notion API.
+// If the query has a Cursor, then iteration starts there. If the query has MaxResults, then it is used as the page size.
+// Nothing is requested from the Notion API until Next is called.
+func (c *Client) IterateDatabase(id string, query *DBQuery) *PageIterator {
+	if query == nil {
+		query = &DBQuery{}
+	}
+	ci := newCursorIterator(c, fmt.Sprintf("%s/v1/databases/%s/query", c.baseURL, id), query, query.getCursor())
+	ci.pageSize = query.getMaxResults()
+	ci.newBuffer = func() list { return new(notion.Pages) }
+	return &PageIterator{cursorIterator: ci}
+}
+
+// IterateBlockChildren returns an iterator over the children of the block with the given id in the Notion API,
+// starting at the given cursor. Nothing is requested from the Notion API until Next is called.
+func (c *Client) IterateBlockChildren(id string, cursor *string) *BlockIterator {
+	ci := newCursorIterator(c, fmt.Sprintf("%s/v1/blocks/%s/children", c.baseURL, id), nil, cursor)
+	ci.newBuffer = func() list { return new(notion.Blocks) }
+	return &BlockIterator{cursorIterator: ci}
+}
+
+// IterateUsers returns an iterator over the users in the Notion API, starting at the given cursor.
+// Nothing is requested from the Notion API until Next is called.
+func (c *Client) IterateUsers(cursor *string) *UserIterator {
+	ci := newCursorIterator(c, fmt.Sprintf("%s/v1/users", c.baseURL), nil, cursor)
+	ci.newBuffer = func() list { return new(notion.Users) }
+	return &UserIterator{cursorIterator: ci}
+}
+
+// IterateDatabases returns an iterator over the databases in the Notion API, starting at the given cursor.
+// Nothing is requested from the Notion API until Next is called.
+func (c *Client) IterateDatabases(cursor *string) *DatabaseIterator {
+	ci := newCursorIterator(c, fmt.Sprintf("%s/v1/databases", c.baseURL), nil, cursor)
+	ci.newBuffer = func() list { return new(notion.Databases) }
+	return &DatabaseIterator{cursorIterator: ci}
+}
+
+// IterateSearch returns an iterator over the results of searching the Notion API.
+// If the query has a Cursor, then iteration starts there. If the query has MaxResults, then it is used as the page size.
+// Nothing is requested from the Notion API until Next is called.
+func (c *Client) IterateSearch(query *SearchQuery) *SearchIterator {
+	if query == nil {
+		query = &SearchQuery{}
+	}
+	ci := newCursorIterator(c, fmt.Sprintf("%s/v1/search", c.baseURL), query, query.getCursor())
+	ci.pageSize = query.getMaxResults()
+	ci.newBuffer = func() list { return new(searchResults) }
+	return &SearchIterator{cursorIterator: ci}
+}
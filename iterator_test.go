@@ -0,0 +1,189 @@
+package gotion_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/thedadams/gotion"
+	"github.com/thedadams/gotion/gotiontest"
+	"github.com/thedadams/gotion/notion"
+	"github.com/thedadams/gotion/richtext"
+)
+
+// addParagraphs adds a page with n paragraphs to the server, and returns the id of the page.
+func addParagraphs(t *testing.T, s *gotiontest.Server, n int) string {
+	t.Helper()
+	page, err := s.AddPage(&notion.Page{Parent: notion.Parent{Type: notion.ParentTypeEnumWorkspace}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blocks := make([]*notion.Block, 0, n)
+	for i := 0; i < n; i++ {
+		blocks = append(blocks, &notion.Block{Type: notion.BlockTypeEnumParagraph, Text: richtext.FromPlainText(fmt.Sprint(i))})
+	}
+	if err := s.AddBlocks(page.ID.String(), blocks...); err != nil {
+		t.Fatal(err)
+	}
+	return page.ID.String()
+}
+
+func TestBlockIterator(t *testing.T) {
+	tests := []struct {
+		name         string
+		blocks       int
+		wantRequests int
+	}{
+		{name: "empty", blocks: 0, wantRequests: 1},
+		{name: "one batch", blocks: 42, wantRequests: 1},
+		{name: "full batch", blocks: 100, wantRequests: 1},
+		{name: "several batches", blocks: 250, wantRequests: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := gotiontest.NewServer()
+			defer s.Close()
+			id := addParagraphs(t, s, tt.blocks)
+			before := len(s.Requests())
+
+			it := s.NewClient().IterateBlockChildren(id, nil)
+			n := 0
+			for it.Next(context.Background()) {
+				if got, want := richtext.PlainText(it.Value().Text), fmt.Sprint(n); got != want {
+					t.Fatalf("block %d has text %q, want %q", n, got, want)
+				}
+				n++
+			}
+			if err := it.Err(); err != nil {
+				t.Fatal(err)
+			}
+			if n != tt.blocks {
+				t.Errorf("got %d blocks, want %d", n, tt.blocks)
+			}
+			if got := len(s.Requests()) - before; got != tt.wantRequests {
+				t.Errorf("got %d requests, want %d", got, tt.wantRequests)
+			}
+			if it.NextCursor() != nil {
+				t.Errorf("got next cursor %q after the last block, want nil", *it.NextCursor())
+			}
+		})
+	}
+}
+
+func TestBlockIteratorResume(t *testing.T) {
+	s := gotiontest.NewServer()
+	defer s.Close()
+	id := addParagraphs(t, s, 150)
+	c := s.NewClient()
+	ctx := context.Background()
+
+	// Stop after the first batch, and resume from the cursor of the next one.
+	it := c.IterateBlockChildren(id, nil)
+	for i := 0; i < 100; i++ {
+		if !it.Next(ctx) {
+			t.Fatalf("iteration stopped after %d blocks: %v", i, it.Err())
+		}
+	}
+	if it.Cursor() != nil {
+		t.Errorf("got cursor %q for the first batch, want nil", *it.Cursor())
+	}
+	cursor := it.NextCursor()
+	if cursor == nil {
+		t.Fatal("got no next cursor after the first batch")
+	}
+
+	it = c.IterateBlockChildren(id, cursor)
+	n := 100
+	for it.Next(ctx) {
+		if got, want := richtext.PlainText(it.Value().Text), fmt.Sprint(n); got != want {
+			t.Fatalf("block %d has text %q, want %q", n, got, want)
+		}
+		n++
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if n != 150 {
+		t.Errorf("got %d blocks after resuming, want 150", n)
+	}
+}
+
+func TestQueryDatabaseMaxResults(t *testing.T) {
+	s := gotiontest.NewServer()
+	defer s.Close()
+	db, err := s.AddDatabase(&notion.Database{
+		Title: []notion.RichText{*richtext.FromPlainText("Tasks")[0]},
+		Properties: notion.DatabaseProperties{
+			{Name: "Name", Type: notion.DatabasePropertyTypeEnumTitle},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 120; i++ {
+		page := &notion.Page{Parent: notion.Parent{Type: notion.ParentTypeEnumDatabase, ID: db.ID.String()}}
+		if _, err := s.AddPage(page); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		maxResults *int
+		want       int
+	}{
+		{name: "all", want: 120},
+		{name: "fewer than a batch", maxResults: intPtr(30), want: 30},
+		{name: "more than a batch", maxResults: intPtr(110), want: 110},
+		{name: "more than there are", maxResults: intPtr(500), want: 120},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pages, err := s.NewClient().QueryDatabase(context.Background(), db.ID.String(), &gotion.DBQuery{MaxResults: tt.maxResults})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(pages) != tt.want {
+				t.Errorf("got %d pages, want %d", len(pages), tt.want)
+			}
+		})
+	}
+}
+
+func intPtr(n int) *int {
+	return &n
+}
+
+func TestSearchIterator(t *testing.T) {
+	s := gotiontest.NewServer()
+	defer s.Close()
+	for i := 0; i < 130; i++ {
+		if _, err := s.AddPage(&notion.Page{Parent: notion.Parent{Type: notion.ParentTypeEnumWorkspace}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	it := s.NewClient().IterateSearch(&gotion.SearchQuery{})
+	if it.Value() != nil {
+		t.Error("got a value before calling Next")
+	}
+	n := 0
+	for it.Next(context.Background()) {
+		if it.Value() == nil || it.Value().Page == nil {
+			t.Fatalf("result %d is not a page", n)
+		}
+		n++
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if n != 130 {
+		t.Errorf("got %d results, want 130", n)
+	}
+	if it.Value() != nil {
+		t.Error("got a value after the last result")
+	}
+}
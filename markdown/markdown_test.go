@@ -0,0 +1,123 @@
+package markdown_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/thedadams/gotion/markdown"
+	"github.com/thedadams/gotion/notion"
+	"github.com/thedadams/gotion/richtext"
+)
+
+func render(t *testing.T, blocks []*notion.Block) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := markdown.RenderBlocks(&buf, blocks); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{name: "headings", src: "# Title\n\n## Section\n\n### Subsection\n"},
+		{name: "annotations", src: "Some **bold**, *italic*, ~~struck~~, and `code` text.\n"},
+		{name: "link", src: "A [link](https://example.com/docs) here.\n"},
+		{name: "bulleted list", src: "- one\n- two\n  - nested\n"},
+		{name: "numbered list", src: "1. first\n2. second\n"},
+		{name: "to-dos", src: "- [ ] todo\n- [x] done\n"},
+		{name: "nested to-dos", src: "- [ ] parent\n  - [x] child\n"},
+		{name: "quote", src: "> quote\n"},
+		{name: "code", src: "```go\nfmt.Println(1)\n```\n"},
+		{name: "divider", src: "---\n"},
+		{name: "equation", src: "$$\nx^2\n$$\n"},
+		{name: "long paragraph", src: strings.Repeat("word ", 1000) + "end\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blocks, err := markdown.Parse([]byte(tt.src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := render(t, blocks); got != tt.src {
+				t.Errorf("got %q, want %q", got, tt.src)
+			}
+		})
+	}
+}
+
+func TestParseSplitsLongText(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{name: "paragraph", src: strings.Repeat("a", 4500)},
+		{name: "bold", src: "**" + strings.Repeat("a", 2500) + "**"},
+		{name: "code", src: "```\n" + strings.Repeat("a", 2500) + "\n```"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blocks, err := markdown.Parse([]byte(tt.src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(blocks) != 1 {
+				t.Fatalf("got %d blocks, want 1", len(blocks))
+			}
+
+			rts := blocks[0].Text
+			if len(rts) < 2 {
+				t.Errorf("got %d runs, want the text split", len(rts))
+			}
+			for i, rt := range rts {
+				if len(rt.Text.Content) > richtext.MaxTextLength {
+					t.Errorf("run %d is %d characters long", i, len(rt.Text.Content))
+				}
+			}
+		})
+	}
+}
+
+func TestParseLinks(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		wantText string
+		wantURL  string
+	}{
+		{name: "plain", src: "[docs](https://example.com/docs)", wantText: "docs", wantURL: "https://example.com/docs"},
+		{
+			name:     "parentheses",
+			src:      "[wiki](https://en.wikipedia.org/wiki/Go_(language))",
+			wantText: "wiki",
+			wantURL:  "https://en.wikipedia.org/wiki/Go_(language)",
+		},
+		{name: "title", src: `[docs](https://example.com/docs "The docs")`, wantText: "docs", wantURL: "https://example.com/docs"},
+		{name: "angle brackets", src: "[docs](<https://example.com/a b>)", wantText: "docs", wantURL: "https://example.com/a%20b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rts := markdown.ParseRichText(tt.src)
+			if len(rts) != 1 {
+				t.Fatalf("got %d runs, want 1: %q", len(rts), richtext.PlainText(rts))
+			}
+			if rts[0].Text.Content != tt.wantText {
+				t.Errorf("got text %q, want %q", rts[0].Text.Content, tt.wantText)
+			}
+			u := rts[0].Text.GetURL()
+			if u == nil {
+				t.Fatal("got no link")
+			}
+			if u.String() != tt.wantURL {
+				t.Errorf("got link %q, want %q", u.String(), tt.wantURL)
+			}
+		})
+	}
+}
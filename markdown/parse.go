@@ -0,0 +1,922 @@
+package markdown
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/thedadams/gotion/notion"
+	"github.com/thedadams/gotion/richtext"
+)
+
+var (
+	headingPattern     = regexp.MustCompile(`^(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	bulletPattern      = regexp.MustCompile(`^([-*+])(?:[ \t]+|$)`)
+	numberPattern      = regexp.MustCompile(`^([0-9]{1,9})([.)])(?:[ \t]+|$)`)
+	taskPattern        = regexp.MustCompile(`^\[([ xX])\](?:[ \t]+|$)`)
+	thematicPattern    = regexp.MustCompile(`^(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	fencePattern       = regexp.MustCompile("^(`{3,}|~{3,})")
+	summaryPattern     = regexp.MustCompile(`^<summary>(.*)</summary>$`)
+	detailsOpenPattern = regexp.MustCompile(`^<details(?:\s[^>]*)?>$`)
+	imagePattern       = regexp.MustCompile(`^!\[(.*)\]\(<?([^ <>]+)>?\)$`)
+	tableDelimPattern  = regexp.MustCompile(`^\|?[ \t]*:?-+:?[ \t]*(?:\|[ \t]*:?-+:?[ \t]*)*\|?$`)
+)
+
+// Parse parses the Markdown document into blocks, ready to be used as the children of a page or block in the Notion API.
+//
+// Headings, paragraphs, bulleted, numbered, and task lists, block quotes, code blocks, thematic breaks, tables,
+// images on a line of their own, equations written between `$$` lines, and toggles written as HTML `<details>` elements
+// are turned into the corresponding blocks, with nested list items as children.
+// Bold, italic, strikethrough, code, underline written as `<u>text</u>`, links, and equations written as `$expression$`
+// are turned into annotated rich text.
+// Headings deeper than level three are turned into level three headings, since those are the only ones Notion has.
+// Text longer than the limit of the Notion API is split into several rich text objects.
+func Parse(src []byte) ([]*notion.Block, error) {
+	text := strings.ReplaceAll(strings.ReplaceAll(string(src), "\r\n", "\n"), "\r", "\n")
+	lines := strings.Split(text, "\n")
+	for i, l := range lines {
+		lines[i] = expandTabs(l)
+	}
+
+	return parseBlocks(lines), nil
+}
+
+// ParseRichText parses inline Markdown into rich text, split as in Parse.
+func ParseRichText(s string) []*notion.RichText {
+	return parseInline(s)
+}
+
+func expandTabs(s string) string {
+	if !strings.Contains(s, "\t") {
+		return s
+	}
+
+	var sb strings.Builder
+	col := 0
+	for _, r := range s {
+		if r == '\t' {
+			n := 4 - col%4
+			sb.WriteString(strings.Repeat(" ", n))
+			col += n
+			continue
+		}
+		sb.WriteRune(r)
+		col++
+	}
+	return sb.String()
+}
+
+func isBlank(s string) bool {
+	return strings.TrimSpace(s) == ""
+}
+
+func indentation(s string) int {
+	return len(s) - len(strings.TrimLeft(s, " "))
+}
+
+// listMarker returns the type of list item that the line starts, whether a task is checked, and the width of the marker.
+// If the line does not start a list item, then the width is zero.
+func listMarker(line string) (notion.BlockTypeEnum, *bool, int) {
+	var (
+		blockType notion.BlockTypeEnum
+		width     int
+	)
+	if m := bulletPattern.FindString(line); m != "" && !thematicPattern.MatchString(strings.TrimSpace(line)) {
+		blockType, width = notion.BlockTypeEnumBulletedListItem, len(m)
+	} else if m := numberPattern.FindString(line); m != "" {
+		blockType, width = notion.BlockTypeEnumNumberedListItem, len(m)
+	} else {
+		return "", nil, 0
+	}
+
+	// A marker followed only by spaces, or by more than four spaces, has content that starts one space after the marker.
+	if rest := line[width:]; rest == "" || width-len(strings.TrimRight(line[:width], " ")) > 4 {
+		width = len(strings.TrimRight(line[:width], " ")) + 1
+	}
+
+	if blockType == notion.BlockTypeEnumBulletedListItem {
+		if m := taskPattern.FindStringSubmatch(line[min(width, len(line)):]); m != nil {
+			checked := m[1] != " "
+			return notion.BlockTypeEnumToDo, &checked, width
+		}
+	}
+	return blockType, nil, width
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// startsBlock returns true if the line, with its indentation already removed, would interrupt a paragraph.
+func startsBlock(line string) bool {
+	if headingPattern.MatchString(line) || thematicPattern.MatchString(line) || fencePattern.MatchString(line) ||
+		strings.HasPrefix(line, ">") || detailsOpenPattern.MatchString(line) || line == "</details>" || strings.TrimSpace(line) == "$$" {
+		return true
+	}
+	if _, _, width := listMarker(line); width != 0 {
+		// An empty list item, or a numbered list item that doesn't start with 1, cannot interrupt a paragraph.
+		if strings.TrimSpace(line[min(width, len(line)):]) == "" {
+			return false
+		}
+		if m := numberPattern.FindStringSubmatch(line); m != nil && m[1] != "1" {
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+func parseBlocks(lines []string) []*notion.Block {
+	var blocks []*notion.Block
+	for i := 0; i < len(lines); {
+		if isBlank(lines[i]) {
+			i++
+			continue
+		}
+
+		var b []*notion.Block
+		b, i = parseBlock(lines, i)
+		blocks = append(blocks, b...)
+	}
+
+	return blocks
+}
+
+// parseBlock parses the block that starts at lines[i] and returns the resulting blocks and the index of the next line.
+func parseBlock(lines []string, i int) ([]*notion.Block, int) {
+	line := lines[i]
+	indent := indentation(line)
+	trimmed := strings.TrimSpace(line)
+
+	if indent >= 4 {
+		return parseIndentedCode(lines, i)
+	}
+
+	if m := headingPattern.FindStringSubmatch(trimmed); m != nil {
+		var blockType notion.BlockTypeEnum = notion.BlockTypeEnumHeading3
+		switch len(m[1]) {
+		case 1:
+			blockType = notion.BlockTypeEnumHeading1
+		case 2:
+			blockType = notion.BlockTypeEnumHeading2
+		}
+		return []*notion.Block{newBlock(blockType, parseInline(m[2]))}, i + 1
+	}
+
+	if thematicPattern.MatchString(trimmed) {
+		return []*notion.Block{newBlock(notion.BlockTypeEnumDivider, nil)}, i + 1
+	}
+
+	if trimmed == "$$" {
+		return parseEquation(lines, i)
+	}
+
+	if fencePattern.MatchString(trimmed) {
+		return parseFencedCode(lines, i)
+	}
+
+	if strings.HasPrefix(trimmed, ">") {
+		return parseQuote(lines, i)
+	}
+
+	if detailsOpenPattern.MatchString(trimmed) {
+		return parseDetails(lines, i)
+	}
+
+	if _, _, width := listMarker(line[indent:]); width != 0 {
+		return parseListItem(lines, i)
+	}
+
+	if strings.Contains(trimmed, "|") && i+1 < len(lines) && tableDelimPattern.MatchString(strings.TrimSpace(lines[i+1])) {
+		return parseTable(lines, i)
+	}
+
+	text, next := paragraphText(lines, i)
+	if m := imagePattern.FindStringSubmatch(text); m != nil {
+		if u, err := url.Parse(m[2]); err == nil {
+			b := newBlock(notion.BlockTypeEnumImage, nil)
+			b.Image = &notion.Media{File: notion.NewExternalFile(u), Caption: parseInline(m[1])}
+			return []*notion.Block{b}, next
+		}
+	}
+	return []*notion.Block{newBlock(notion.BlockTypeEnumParagraph, parseInline(text))}, next
+}
+
+// paragraphText gathers the lines of the paragraph that starts at lines[i], and returns the text and the index of the next line.
+// Hard line breaks are turned into new lines and soft line breaks are turned into spaces.
+func paragraphText(lines []string, i int) (string, int) {
+	var sb strings.Builder
+	for j := i; j < len(lines); j++ {
+		line := lines[j]
+		if isBlank(line) || (j > i && startsBlock(strings.TrimLeft(line, " "))) {
+			return sb.String(), j
+		}
+
+		text := strings.TrimLeft(line, " ")
+		if j > i {
+			prev := sb.String()
+			switch {
+			case strings.HasSuffix(prev, "\\") && (len(prev)-len(strings.TrimRight(prev, "\\")))%2 == 1:
+				sb.Reset()
+				sb.WriteString(strings.TrimSuffix(prev, "\\") + "\n")
+			case strings.HasSuffix(lines[j-1], "  "):
+				sb.WriteString("\n")
+			default:
+				sb.WriteString(" ")
+			}
+		}
+		sb.WriteString(strings.TrimRight(text, " "))
+	}
+
+	return sb.String(), len(lines)
+}
+
+func parseListItem(lines []string, i int) ([]*notion.Block, int) {
+	line := lines[i]
+	indent := indentation(line)
+	blockType, checked, width := listMarker(line[indent:])
+	width += indent
+
+	first := line[min(width, len(line)):]
+	if checked != nil {
+		first = strings.TrimPrefix(first, taskPattern.FindString(first))
+	}
+
+	var (
+		content = []string{first}
+		j       = i + 1
+		inText  = !isBlank(content[0])
+	)
+	for j < len(lines) {
+		l := lines[j]
+		if isBlank(l) {
+			k := j
+			for k < len(lines) && isBlank(lines[k]) {
+				k++
+			}
+			if k == len(lines) || indentation(lines[k]) < width {
+				break
+			}
+			for ; j < k; j++ {
+				content = append(content, "")
+			}
+			inText = false
+			continue
+		}
+
+		if indentation(l) >= width {
+			stripped := l[width:]
+			if inText && startsBlock(strings.TrimLeft(stripped, " ")) {
+				inText = false
+			}
+			content = append(content, stripped)
+			j++
+			continue
+		}
+
+		// Lazy continuation lines are part of the text of the list item, unless they start another list item.
+		if _, _, w := listMarker(strings.TrimLeft(l, " ")); inText && w == 0 && !startsBlock(strings.TrimLeft(l, " ")) {
+			content = append(content, strings.TrimLeft(l, " "))
+			j++
+			continue
+		}
+		break
+	}
+
+	var (
+		text     string
+		children []string
+	)
+	if !isBlank(content[0]) {
+		var next int
+		text, next = paragraphText(content, 0)
+		children = content[next:]
+	} else {
+		children = content[1:]
+	}
+
+	b := newBlock(blockType, parseInline(text))
+	b.Checked = checked
+	b.Children = parseBlocks(children)
+	b.HasChildren = len(b.Children) != 0
+	return []*notion.Block{b}, j
+}
+
+func parseDetails(lines []string, i int) ([]*notion.Block, int) {
+	depth := 0
+	j := i
+	for ; j < len(lines); j++ {
+		trimmed := strings.TrimSpace(lines[j])
+		if detailsOpenPattern.MatchString(trimmed) {
+			depth++
+		} else if trimmed == "</details>" {
+			depth--
+			if depth == 0 {
+				break
+			}
+		}
+	}
+
+	inner := lines[i+1 : min(j, len(lines))]
+	var summary string
+	for k, l := range inner {
+		if isBlank(l) {
+			continue
+		}
+		if m := summaryPattern.FindStringSubmatch(strings.TrimSpace(l)); m != nil {
+			summary = m[1]
+			inner = inner[k+1:]
+		}
+		break
+	}
+
+	b := newBlock(notion.BlockTypeEnumToggle, parseInline(summary))
+	b.Children = parseBlocks(dedent(inner))
+	b.HasChildren = len(b.Children) != 0
+	return []*notion.Block{b}, j + 1
+}
+
+// dedent removes the indentation common to all non-blank lines.
+func dedent(lines []string) []string {
+	common := -1
+	for _, l := range lines {
+		if !isBlank(l) && (common < 0 || indentation(l) < common) {
+			common = indentation(l)
+		}
+	}
+	if common <= 0 {
+		return lines
+	}
+
+	result := make([]string, len(lines))
+	for i, l := range lines {
+		if !isBlank(l) {
+			result[i] = l[common:]
+		}
+	}
+	return result
+}
+
+func parseFencedCode(lines []string, i int) ([]*notion.Block, int) {
+	indent := indentation(lines[i])
+	fence := fencePattern.FindString(strings.TrimSpace(lines[i]))
+
+	var code []string
+	j := i + 1
+	for ; j < len(lines); j++ {
+		trimmed := strings.TrimSpace(lines[j])
+		if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+			j++
+			break
+		}
+		l := lines[j]
+		l = l[min(indent, indentation(l)):]
+		code = append(code, l)
+	}
+
+	language := strings.Fields(strings.TrimSpace(lines[i])[len(fence):])
+	if len(language) == 0 {
+		return []*notion.Block{newCode(strings.Join(code, "\n"), "")}, j
+	}
+	return []*notion.Block{newCode(strings.Join(code, "\n"), language[0])}, j
+}
+
+func parseIndentedCode(lines []string, i int) ([]*notion.Block, int) {
+	var code []string
+	j := i
+	for ; j < len(lines); j++ {
+		if !isBlank(lines[j]) && indentation(lines[j]) < 4 {
+			break
+		}
+		if isBlank(lines[j]) {
+			code = append(code, "")
+		} else {
+			code = append(code, lines[j][4:])
+		}
+	}
+
+	return []*notion.Block{newCode(strings.TrimRight(strings.Join(code, "\n"), "\n"), "")}, j
+}
+
+func parseQuote(lines []string, i int) ([]*notion.Block, int) {
+	var quoted []string
+	j := i
+	for ; j < len(lines); j++ {
+		trimmed := strings.TrimLeft(lines[j], " ")
+		if !strings.HasPrefix(trimmed, ">") {
+			break
+		}
+		trimmed = strings.TrimPrefix(trimmed, ">")
+		quoted = append(quoted, strings.TrimPrefix(trimmed, " "))
+	}
+
+	// The first paragraph of the quote is its text, and anything else is its children.
+	b := newBlock(notion.BlockTypeEnumQuote, nil)
+	inner := parseBlocks(quoted)
+	if len(inner) != 0 && inner[0].Type == notion.BlockTypeEnumParagraph {
+		b.Text, inner = inner[0].Text, inner[1:]
+	}
+	b.Children = inner
+	b.HasChildren = len(b.Children) != 0
+	return []*notion.Block{b}, j
+}
+
+func parseEquation(lines []string, i int) ([]*notion.Block, int) {
+	var expression []string
+	j := i + 1
+	for ; j < len(lines); j++ {
+		if strings.TrimSpace(lines[j]) == "$$" {
+			j++
+			break
+		}
+		expression = append(expression, strings.TrimSpace(lines[j]))
+	}
+
+	b := newBlock(notion.BlockTypeEnumEquation, nil)
+	b.Equation = &notion.Equation{Expression: strings.Join(expression, "\n")}
+	return []*notion.Block{b}, j
+}
+
+// parseTable parses a GitHub Flavored Markdown table. The header row becomes the first row of the table.
+func parseTable(lines []string, i int) ([]*notion.Block, int) {
+	header := tableCells(lines[i])
+	table := newBlock(notion.BlockTypeEnumTable, nil)
+	table.Table = &notion.Table{TableWidth: len(header), HasColumnHeader: true}
+
+	rows := [][]string{header}
+	j := i + 2
+	for ; j < len(lines) && !isBlank(lines[j]) && !startsBlock(strings.TrimLeft(lines[j], " ")); j++ {
+		rows = append(rows, tableCells(lines[j]))
+	}
+
+	for _, r := range rows {
+		row := newBlock(notion.BlockTypeEnumTableRow, nil)
+		row.TableRow = &notion.TableRow{Cells: make([][]*notion.RichText, len(header))}
+		for k := range row.TableRow.Cells {
+			if k < len(r) {
+				row.TableRow.Cells[k] = parseInline(r[k])
+			}
+			if row.TableRow.Cells[k] == nil {
+				row.TableRow.Cells[k] = []*notion.RichText{}
+			}
+		}
+		table.Children = append(table.Children, row)
+	}
+	table.HasChildren = true
+	return []*notion.Block{table}, j
+}
+
+// tableCells splits a row of a table into its cells. Escaped pipes are part of the cells.
+func tableCells(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, "\\|") {
+		line = strings.TrimSuffix(line, "|")
+	}
+
+	var (
+		cells []string
+		sb    strings.Builder
+	)
+	for k := 0; k < len(line); k++ {
+		switch {
+		case line[k] == '\\' && k+1 < len(line) && line[k+1] == '|':
+			sb.WriteByte('|')
+			k++
+		case line[k] == '|':
+			cells = append(cells, strings.TrimSpace(sb.String()))
+			sb.Reset()
+		default:
+			sb.WriteByte(line[k])
+		}
+	}
+	return append(cells, strings.TrimSpace(sb.String()))
+}
+
+func newCode(code, language string) *notion.Block {
+	if language == "" {
+		language = plainTextLanguage
+	}
+	b := newBlock(notion.BlockTypeEnumCode, nil)
+	if code != "" {
+		b.Text = richtext.Split([]*notion.RichText{newText(code, notion.Annotations{}, nil)})
+	}
+	b.Code = &notion.Code{Language: language}
+	return b
+}
+
+func newBlock(blockType notion.BlockTypeEnum, text []*notion.RichText) *notion.Block {
+	return &notion.Block{Object: notion.Object{Object: "block"}, Type: blockType, Text: text}
+}
+
+func newText(content string, annotations notion.Annotations, link *url.URL) *notion.RichText {
+	return &notion.RichText{
+		Type:        notion.RichTextTypeEnumText,
+		Annotations: annotations,
+		PlainText:   content,
+		Text:        &notion.Text{Content: content, Link: notion.NewLink(link)},
+	}
+}
+
+// These are the kinds of emphasis delimiters in inline Markdown.
+const (
+	delimBold = iota
+	delimItalic
+	delimStrikethrough
+	delimUnderline
+)
+
+// An inlineToken is a piece of inline Markdown: text, a code span, an equation, or an emphasis delimiter.
+type inlineToken struct {
+	text     string
+	code     bool
+	equation bool
+	link     *url.URL
+
+	delim    bool
+	kind     int
+	canOpen  bool
+	canClose bool
+	matched  bool
+	opens    bool
+}
+
+// parseInline parses inline Markdown into rich text. Emphasis delimiters are matched with a stack, as in CommonMark,
+// and any delimiter that is not matched is kept as text.
+func parseInline(s string) []*notion.RichText {
+	tokens := tokenize(s, nil)
+	matchDelimiters(tokens)
+
+	var (
+		runs        []*notion.RichText
+		annotations notion.Annotations
+	)
+	for _, t := range tokens {
+		if t.delim && t.matched {
+			setAnnotation(&annotations, t.kind, t.opens)
+			continue
+		}
+
+		a := annotations
+		var rt *notion.RichText
+		switch {
+		case t.equation:
+			rt = &notion.RichText{
+				Type:        notion.RichTextTypeEnumEquation,
+				Annotations: a,
+				PlainText:   t.text,
+				Equation:    &notion.Equation{Expression: t.text},
+			}
+		case t.code:
+			a.Code = true
+			rt = newText(t.text, a, t.link)
+		default:
+			rt = newText(t.text, a, t.link)
+		}
+		if rt.HRef = ""; t.link != nil {
+			rt.HRef = t.link.String()
+		}
+		runs = appendRun(runs, rt)
+	}
+
+	return richtext.Split(runs)
+}
+
+func setAnnotation(a *notion.Annotations, kind int, on bool) {
+	switch kind {
+	case delimBold:
+		a.Bold = on
+	case delimItalic:
+		a.Italic = on
+	case delimStrikethrough:
+		a.Strikethrough = on
+	case delimUnderline:
+		a.Underline = on
+	}
+}
+
+// appendRun appends the rich text, merging it into the last one if they have the same annotations and link.
+func appendRun(runs []*notion.RichText, rt *notion.RichText) []*notion.RichText {
+	if rt.PlainText == "" {
+		return runs
+	}
+	if len(runs) != 0 {
+		last := runs[len(runs)-1]
+		if last.Type == notion.RichTextTypeEnumText && rt.Type == notion.RichTextTypeEnumText &&
+			last.Annotations == rt.Annotations && last.HRef == rt.HRef {
+			last.Text.Content += rt.Text.Content
+			last.PlainText += rt.PlainText
+			return runs
+		}
+	}
+	return append(runs, rt)
+}
+
+// tokenize splits inline Markdown into tokens. Links are parsed recursively, with every token inside the link
+// having the link set.
+func tokenize(s string, link *url.URL) []*inlineToken {
+	var (
+		tokens []*inlineToken
+		text   strings.Builder
+	)
+	flush := func() {
+		if text.Len() != 0 {
+			tokens = append(tokens, &inlineToken{text: text.String(), link: link})
+			text.Reset()
+		}
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && isPunctuation(s[i+1]):
+			text.WriteByte(s[i+1])
+			i += 2
+			continue
+		case c == '`':
+			if code, n := codeSpanAt(s[i:]); n != 0 {
+				flush()
+				tokens = append(tokens, &inlineToken{text: code, code: true, link: link})
+				i += n
+				continue
+			}
+			n := len(s[i:]) - len(strings.TrimLeft(s[i:], "`"))
+			text.WriteString(s[i : i+n])
+			i += n
+			continue
+		case c == '$':
+			if expression, n := equationAt(s[i:]); n != 0 {
+				flush()
+				tokens = append(tokens, &inlineToken{text: expression, equation: true, link: link})
+				i += n
+				continue
+			}
+		case c == '[' && link == nil:
+			if inner, target, n := linkAt(s[i:]); n != 0 {
+				flush()
+				tokens = append(tokens, tokenize(inner, target)...)
+				i += n
+				continue
+			}
+		case c == '<':
+			if strings.HasPrefix(s[i:], "<u>") {
+				flush()
+				tokens = append(tokens, &inlineToken{text: "<u>", delim: true, kind: delimUnderline, canOpen: true, link: link})
+				i += len("<u>")
+				continue
+			}
+			if strings.HasPrefix(s[i:], "</u>") {
+				flush()
+				tokens = append(tokens, &inlineToken{text: "</u>", delim: true, kind: delimUnderline, canClose: true, link: link})
+				i += len("</u>")
+				continue
+			}
+			if end := strings.IndexByte(s[i:], '>'); end > 0 && link == nil {
+				if u, err := url.Parse(s[i+1 : i+end]); err == nil && u.Scheme != "" && !strings.ContainsAny(s[i+1:i+end], " <") {
+					flush()
+					tokens = append(tokens, &inlineToken{text: s[i+1 : i+end], link: u})
+					i += end + 1
+					continue
+				}
+			}
+		case c == '*' || c == '_' || (c == '~' && strings.HasPrefix(s[i:], "~~")):
+			n := len(s[i:]) - len(strings.TrimLeft(s[i:], string(c)))
+			flush()
+			tokens = append(tokens, emphasisTokens(s, i, n, link)...)
+			i += n
+			continue
+		}
+
+		text.WriteByte(c)
+		i++
+	}
+	flush()
+
+	return tokens
+}
+
+// emphasisTokens returns the delimiter tokens for the run of n emphasis characters starting at s[i].
+// Whether the delimiters can open or close emphasis depends on the characters around the run, as in CommonMark.
+func emphasisTokens(s string, i, n int, link *url.URL) []*inlineToken {
+	c := s[i]
+	before, after := ' ', ' '
+	if i > 0 {
+		before = rune(s[i-1])
+	}
+	if i+n < len(s) {
+		after = rune(s[i+n])
+	}
+
+	leftFlanking := !unicode.IsSpace(after) && (!unicode.IsPunct(after) || unicode.IsSpace(before) || unicode.IsPunct(before))
+	rightFlanking := !unicode.IsSpace(before) && (!unicode.IsPunct(before) || unicode.IsSpace(after) || unicode.IsPunct(after))
+	canOpen, canClose := leftFlanking, rightFlanking
+	if c == '_' {
+		canOpen = leftFlanking && (!rightFlanking || unicode.IsPunct(before))
+		canClose = rightFlanking && (!leftFlanking || unicode.IsPunct(after))
+	}
+
+	var tokens []*inlineToken
+	if c == '~' {
+		for ; n >= 2; n -= 2 {
+			tokens = append(tokens, &inlineToken{text: "~~", delim: true, kind: delimStrikethrough, canOpen: canOpen, canClose: canClose, link: link})
+		}
+		if n == 1 {
+			tokens = append(tokens, &inlineToken{text: "~", link: link})
+		}
+		return tokens
+	}
+
+	// A run of three is both bold and italic. Longer runs are split into bold delimiters, and possibly an italic one.
+	for ; n >= 2; n -= 2 {
+		tokens = append(tokens, &inlineToken{text: string([]byte{c, c}), delim: true, kind: delimBold, canOpen: canOpen, canClose: canClose, link: link})
+	}
+	if n == 1 {
+		italic := &inlineToken{text: string(c), delim: true, kind: delimItalic, canOpen: canOpen, canClose: canClose, link: link}
+		if canClose {
+			// When closing, the italic delimiter is innermost, so it comes first.
+			tokens = append([]*inlineToken{italic}, tokens...)
+		} else {
+			tokens = append(tokens, italic)
+		}
+	}
+	return tokens
+}
+
+// matchDelimiters pairs opening and closing delimiters of the same kind. Unmatched delimiters become text.
+func matchDelimiters(tokens []*inlineToken) {
+	var stack []*inlineToken
+	for _, t := range tokens {
+		if !t.delim {
+			continue
+		}
+
+		if t.canClose {
+			matched := false
+			for j := len(stack) - 1; j >= 0; j-- {
+				if stack[j].kind == t.kind && stack[j].link == t.link {
+					stack[j].matched, stack[j].opens = true, true
+					t.matched = true
+					stack = stack[:j]
+					matched = true
+					break
+				}
+			}
+			if matched {
+				continue
+			}
+		}
+		if t.canOpen {
+			stack = append(stack, t)
+		}
+	}
+}
+
+func isPunctuation(c byte) bool {
+	return c < 128 && unicode.IsPunct(rune(c)) ||
+		c == '`' || c == '$' || c == '^' || c == '|' || c == '~' || c == '<' || c == '>' || c == '+' || c == '='
+}
+
+// codeSpanAt returns the content of the code span at the start of s and its length, or a length of zero if there is none.
+func codeSpanAt(s string) (string, int) {
+	n := len(s) - len(strings.TrimLeft(s, "`"))
+	fence := s[:n]
+	for i := n; i < len(s); {
+		j := strings.Index(s[i:], fence)
+		if j < 0 {
+			return "", 0
+		}
+		j += i
+		end := j + n
+		if end < len(s) && s[end] == '`' {
+			i = end + len(s[end:]) - len(strings.TrimLeft(s[end:], "`"))
+			continue
+		}
+
+		code := strings.ReplaceAll(s[n:j], "\n", " ")
+		if len(code) > 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.TrimSpace(code) != "" {
+			code = code[1 : len(code)-1]
+		}
+		return code, end
+	}
+	return "", 0
+}
+
+// equationAt returns the expression of the inline equation at the start of s and its length,
+// or a length of zero if there is none.
+func equationAt(s string) (string, int) {
+	if len(s) < 3 || s[1] == ' ' || s[1] == '$' {
+		return "", 0
+	}
+
+	var sb strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 < len(s) && s[i+1] == '$' {
+				sb.WriteByte('$')
+				i++
+				continue
+			}
+		case '$':
+			if s[i-1] == ' ' {
+				return "", 0
+			}
+			return sb.String(), i + 1
+		}
+		sb.WriteByte(s[i])
+	}
+	return "", 0
+}
+
+// linkAt returns the text and target of the inline link at the start of s and its length,
+// or a length of zero if there is none.
+func linkAt(s string) (string, *url.URL, int) {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '`':
+			if _, n := codeSpanAt(s[i:]); n != 0 {
+				i += n - 1
+			}
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth != 0 {
+				continue
+			}
+			if i+1 >= len(s) || s[i+1] != '(' {
+				return "", nil, 0
+			}
+			end := linkTargetEnd(s[i+2:])
+			if end < 0 {
+				return "", nil, 0
+			}
+			// Drop the title of the link, which Notion has no place for.
+			target := strings.TrimSpace(s[i+2 : i+2+end])
+			if strings.HasPrefix(target, "<") {
+				target = target[1:strings.IndexByte(target, '>')]
+			} else if sp := strings.IndexAny(target, " \t"); sp >= 0 {
+				target = target[:sp]
+			}
+			u, err := url.Parse(target)
+			if err != nil {
+				return "", nil, 0
+			}
+			return s[1:i], u, i + 3 + end
+		}
+	}
+	return "", nil, 0
+}
+
+// linkTargetEnd returns the index of the ')' that ends the target of a link at the start of s, or -1 if there is none.
+// As in CommonMark, the destination can contain balanced parentheses, like in https://en.wikipedia.org/wiki/Go_(game),
+// or anything but '>' when it is written between '<' and '>', and the title can contain anything but its quote.
+func linkTargetEnd(s string) int {
+	i := len(s) - len(strings.TrimLeft(s, " \t"))
+	if strings.HasPrefix(s[i:], "<") {
+		end := strings.IndexByte(s[i:], '>')
+		if end < 0 {
+			return -1
+		}
+		i += end + 1
+	} else {
+		depth := 0
+	destination:
+		for ; i < len(s); i++ {
+			switch s[i] {
+			case '\\':
+				i++
+			case '(':
+				depth++
+			case ')':
+				if depth == 0 {
+					return i
+				}
+				depth--
+			case ' ', '\t':
+				break destination
+			}
+		}
+	}
+
+	i += len(s[i:]) - len(strings.TrimLeft(s[i:], " \t"))
+	if i < len(s) && (s[i] == '"' || s[i] == '\'') {
+		end := strings.IndexByte(s[i+1:], s[i])
+		if end < 0 {
+			return -1
+		}
+		i += end + 2
+		i += len(s[i:]) - len(strings.TrimLeft(s[i:], " \t"))
+	}
+	if i < len(s) && s[i] == ')' {
+		return i
+	}
+	return -1
+}
@@ -0,0 +1,592 @@
+// Package markdown converts Notion pages, blocks, and rich text to CommonMark, and parses Markdown into blocks.
+//
+// Strikethrough is written as `~~text~~`, as in GitHub Flavored Markdown, and underline is written as `<u>text</u>`,
+// since CommonMark has no syntax for either. Toggle blocks are written as HTML `<details>` elements,
+// tables as GitHub Flavored Markdown tables, callouts as block quotes, and equations between `$$` lines.
+// Blocks that only group other blocks, like columns and synced blocks, are replaced by their children.
+package markdown
+
+import (
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/thedadams/gotion/notion"
+)
+
+// RenderPage writes the page to w as Markdown. The title of the page is written as a level one heading,
+// followed by the children of the page.
+func RenderPage(w io.Writer, page *notion.Page) error {
+	if page == nil {
+		return nil
+	}
+
+	var lines []string
+	if title := pageTitle(page); title != "" {
+		lines = append(lines, "# "+title)
+	}
+	if children := blocksLines(page.Children); len(children) != 0 {
+		if len(lines) != 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, children...)
+	}
+
+	return writeLines(w, lines)
+}
+
+// RenderBlocks writes the blocks, and their children, to w as Markdown.
+func RenderBlocks(w io.Writer, blocks []*notion.Block) error {
+	return writeLines(w, blocksLines(blocks))
+}
+
+// RenderRichText returns the rich text as inline Markdown.
+func RenderRichText(rts []*notion.RichText) string {
+	iw := new(inlineWriter)
+	for _, rt := range rts {
+		iw.writeRichText(rt)
+	}
+	return iw.String()
+}
+
+func writeLines(w io.Writer, lines []string) error {
+	if len(lines) == 0 {
+		return nil
+	}
+	_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+	return err
+}
+
+func pageTitle(page *notion.Page) string {
+	for _, prop := range page.Properties {
+		if prop != nil && prop.Type == notion.DatabasePropertyTypeEnumTitle {
+			title := make([]*notion.RichText, 0, len(prop.Title))
+			for i := range prop.Title {
+				title = append(title, &prop.Title[i])
+			}
+			return RenderRichText(title)
+		}
+	}
+	return ""
+}
+
+// blocksLines returns the lines of Markdown for the given blocks, separating them with blank lines.
+// Consecutive items of the same kind of list are not separated so that they form a single list.
+func blocksLines(blocks []*notion.Block) []string {
+	var (
+		lines  []string
+		prev   *notion.Block
+		number int
+	)
+	for _, b := range blocks {
+		if b == nil || b.Type == notion.BlockTypeEnumUnsupported {
+			continue
+		}
+
+		if b.Type == notion.BlockTypeEnumNumberedListItem && (prev == nil || prev.Type != notion.BlockTypeEnumNumberedListItem) {
+			number = 1
+		}
+		bl := blockLines(b, number)
+		if len(bl) == 0 {
+			continue
+		}
+		if prev != nil && !sameList(prev, b) {
+			lines = append(lines, "")
+		}
+		lines = append(lines, bl...)
+		if b.Type == notion.BlockTypeEnumNumberedListItem {
+			number++
+		}
+		prev = b
+	}
+
+	return lines
+}
+
+func sameList(a, b *notion.Block) bool {
+	switch a.Type {
+	case notion.BlockTypeEnumBulletedListItem, notion.BlockTypeEnumToDo:
+		return b.Type == notion.BlockTypeEnumBulletedListItem || b.Type == notion.BlockTypeEnumToDo
+	case notion.BlockTypeEnumNumberedListItem:
+		return b.Type == notion.BlockTypeEnumNumberedListItem
+	}
+	return false
+}
+
+func isListItem(b *notion.Block) bool {
+	return b != nil && (b.Type == notion.BlockTypeEnumBulletedListItem ||
+		b.Type == notion.BlockTypeEnumNumberedListItem ||
+		b.Type == notion.BlockTypeEnumToDo)
+}
+
+// blockLines returns the lines of Markdown for a single block and its children.
+// The number is only used for numbered list items.
+func blockLines(b *notion.Block, number int) []string {
+	switch b.Type {
+	case notion.BlockTypeEnumHeading1:
+		return append([]string{"# " + singleLine(b.Text)}, childLines(b, "", false)...)
+	case notion.BlockTypeEnumHeading2:
+		return append([]string{"## " + singleLine(b.Text)}, childLines(b, "", false)...)
+	case notion.BlockTypeEnumHeading3:
+		return append([]string{"### " + singleLine(b.Text)}, childLines(b, "", false)...)
+	case notion.BlockTypeEnumBulletedListItem:
+		return listItemLines(b, "- ", "")
+	case notion.BlockTypeEnumNumberedListItem:
+		return listItemLines(b, strconv.Itoa(number)+". ", "")
+	case notion.BlockTypeEnumToDo:
+		if b.IsChecked() {
+			return listItemLines(b, "- ", "[x] ")
+		}
+		return listItemLines(b, "- ", "[ ] ")
+	case notion.BlockTypeEnumToggle:
+		lines := []string{"<details>", "<summary>" + singleLine(b.Text) + "</summary>"}
+		if children := blocksLines(b.Children); len(children) != 0 {
+			lines = append(lines, "")
+			lines = append(lines, children...)
+		}
+		return append(lines, "", "</details>")
+	case notion.BlockTypeEnumChildPage, notion.BlockTypeEnumChildDatabase:
+		return []string{"[" + escape(b.GetTitle()) + "](" + escapeURL(notionURL(b.ID.String())) + ")"}
+	case notion.BlockTypeEnumLinkToPage:
+		if b.LinkToPage == nil {
+			return nil
+		}
+		return []string{"<" + escapeURL(notionURL(b.LinkToPage.ID)) + ">"}
+	case notion.BlockTypeEnumQuote:
+		return quoteLines(append(textLines(b.Text), childLines(b, "", false)...))
+	case notion.BlockTypeEnumCallout:
+		text := textLines(b.Text)
+		if b.Callout != nil && b.Callout.Icon != nil && b.Callout.Icon.Emoji != "" {
+			if len(text) == 0 {
+				text = []string{""}
+			}
+			text[0] = strings.TrimRight(b.Callout.Icon.Emoji+" "+text[0], " ")
+		}
+		return quoteLines(append(text, childLines(b, "", false)...))
+	case notion.BlockTypeEnumCode:
+		language := ""
+		if b.Code != nil && b.Code.Language != plainTextLanguage {
+			language = b.Code.Language
+		}
+		return codeLines(plainText(b.Text), language)
+	case notion.BlockTypeEnumEquation:
+		if b.Equation == nil {
+			return nil
+		}
+		return []string{"$$", b.Equation.Expression, "$$"}
+	case notion.BlockTypeEnumDivider:
+		return []string{"---"}
+	case notion.BlockTypeEnumImage:
+		if b.Image == nil {
+			return nil
+		}
+		return []string{"![" + RenderRichText(b.Image.Caption) + "](" + escapeURL(fileURL(&b.Image.File)) + ")"}
+	case notion.BlockTypeEnumVideo, notion.BlockTypeEnumFile, notion.BlockTypeEnumPDF, notion.BlockTypeEnumAudio:
+		m := media(b)
+		if m == nil {
+			return nil
+		}
+		return []string{linkLine(fileURL(&m.File), m.Caption)}
+	case notion.BlockTypeEnumBookmark, notion.BlockTypeEnumEmbed, notion.BlockTypeEnumLinkPreview:
+		bm := bookmark(b)
+		if bm == nil {
+			return nil
+		}
+		return []string{linkLine(bm.URL, bm.Caption)}
+	case notion.BlockTypeEnumTable:
+		return tableLines(b)
+	case notion.BlockTypeEnumColumnList, notion.BlockTypeEnumColumn, notion.BlockTypeEnumSyncedBlock, notion.BlockTypeEnumTemplate:
+		// These blocks only group their children, which Markdown has no syntax for.
+		return blocksLines(b.Children)
+	case notion.BlockTypeEnumTableOfContents, notion.BlockTypeEnumBreadcrumb, notion.BlockTypeEnumTableRow:
+		return nil
+	}
+
+	return append(textLines(b.Text), childLines(b, "", false)...)
+}
+
+// listItemLines returns the lines for a list item, indenting continuation lines and children to line up with the
+// content of the list item. The task, if any, is the checkbox of a task list item, which is part of the content.
+func listItemLines(b *notion.Block, marker, task string) []string {
+	indent := strings.Repeat(" ", len(marker))
+	text := textLines(b.Text)
+	if len(text) == 0 {
+		text = []string{""}
+	}
+
+	lines := []string{strings.TrimRight(marker+task+text[0], " ")}
+	for _, l := range text[1:] {
+		lines = append(lines, indent+l)
+	}
+	return append(lines, childLines(b, indent, len(b.Children) != 0 && isListItem(b.Children[0]))...)
+}
+
+// childLines returns the lines of the children of the block, prefixed with indent.
+// Unless tight is true, the children are separated from the parent by a blank line.
+func childLines(b *notion.Block, indent string, tight bool) []string {
+	children := blocksLines(b.Children)
+	if len(children) == 0 {
+		return nil
+	}
+
+	var lines []string
+	if !tight {
+		lines = append(lines, "")
+	}
+	for _, l := range children {
+		if l == "" {
+			lines = append(lines, "")
+		} else {
+			lines = append(lines, indent+l)
+		}
+	}
+	return lines
+}
+
+// textLines renders the rich text, turning new lines in the text into hard line breaks.
+func textLines(rts []*notion.RichText) []string {
+	text := RenderRichText(rts)
+	if text == "" {
+		return nil
+	}
+
+	lines := strings.Split(text, "\n")
+	for i := range lines[:len(lines)-1] {
+		lines[i] += "\\"
+	}
+	lines[0] = escapeLineStart(lines[0])
+	return lines
+}
+
+// singleLine renders the rich text, replacing any new lines with spaces, for blocks that must be on one line.
+func singleLine(rts []*notion.RichText) string {
+	return strings.ReplaceAll(RenderRichText(rts), "\n", " ")
+}
+
+// plainTextLanguage is the language Notion uses for code blocks without a language.
+const plainTextLanguage = "plain text"
+
+func notionURL(id string) string {
+	return "https://www.notion.so/" + strings.ReplaceAll(id, "-", "")
+}
+
+func media(b *notion.Block) *notion.Media {
+	switch b.Type {
+	case notion.BlockTypeEnumImage:
+		return b.Image
+	case notion.BlockTypeEnumVideo:
+		return b.Video
+	case notion.BlockTypeEnumFile:
+		return b.File
+	case notion.BlockTypeEnumPDF:
+		return b.PDF
+	case notion.BlockTypeEnumAudio:
+		return b.Audio
+	}
+	return nil
+}
+
+func bookmark(b *notion.Block) *notion.Bookmark {
+	switch b.Type {
+	case notion.BlockTypeEnumBookmark:
+		return b.Bookmark
+	case notion.BlockTypeEnumEmbed:
+		return b.Embed
+	case notion.BlockTypeEnumLinkPreview:
+		return b.LinkPreview
+	}
+	return nil
+}
+
+func fileURL(f *notion.File) string {
+	if u := f.GetURL(); u != nil {
+		return u.String()
+	}
+	return ""
+}
+
+// linkLine returns a link to the URL, using the caption as the text of the link, or the URL if there is no caption.
+func linkLine(u string, caption []*notion.RichText) string {
+	text := singleLine(caption)
+	if text == "" {
+		return "<" + escapeURL(u) + ">"
+	}
+	return "[" + text + "](" + escapeURL(u) + ")"
+}
+
+// quoteLines prefixes the lines with the block quote marker.
+func quoteLines(lines []string) []string {
+	quoted := make([]string, 0, len(lines))
+	for _, l := range lines {
+		if l == "" {
+			quoted = append(quoted, ">")
+		} else {
+			quoted = append(quoted, "> "+l)
+		}
+	}
+	return quoted
+}
+
+// codeLines returns a fenced code block, using a fence that is longer than any run of backticks in the code.
+func codeLines(code, language string) []string {
+	fence := "```"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+
+	lines := []string{fence + language}
+	if code != "" {
+		lines = append(lines, strings.Split(code, "\n")...)
+	}
+	return append(lines, fence)
+}
+
+// tableLines returns the table as a GitHub Flavored Markdown table. Markdown tables always have a header row,
+// so the first row is used as the header, even if the table doesn't have a column header.
+func tableLines(b *notion.Block) []string {
+	var rows [][]string
+	width := 0
+	if b.Table != nil {
+		width = b.Table.TableWidth
+	}
+	for _, child := range b.Children {
+		if child == nil || child.TableRow == nil {
+			continue
+		}
+		cells := make([]string, 0, len(child.TableRow.Cells))
+		for _, cell := range child.TableRow.Cells {
+			cells = append(cells, singleLine(cell))
+		}
+		if len(cells) > width {
+			width = len(cells)
+		}
+		rows = append(rows, cells)
+	}
+	if len(rows) == 0 || width == 0 {
+		return nil
+	}
+
+	row := func(cells []string) string {
+		for len(cells) < width {
+			cells = append(cells, "")
+		}
+		return "| " + strings.Join(cells, " | ") + " |"
+	}
+	separator := make([]string, width)
+	for i := range separator {
+		separator[i] = "---"
+	}
+	lines := []string{row(rows[0]), row(separator)}
+	for _, r := range rows[1:] {
+		lines = append(lines, row(r))
+	}
+	return lines
+}
+
+// plainText returns the text of the rich text without any Markdown, for code blocks.
+func plainText(rts []*notion.RichText) string {
+	var sb strings.Builder
+	for _, rt := range rts {
+		if rt == nil {
+			continue
+		}
+		switch {
+		case rt.Type == notion.RichTextTypeEnumText && rt.Text != nil:
+			sb.WriteString(rt.Text.Content)
+		case rt.Type == notion.RichTextTypeEnumEquation && rt.Equation != nil:
+			sb.WriteString(rt.Equation.Expression)
+		default:
+			sb.WriteString(rt.PlainText)
+		}
+	}
+	return sb.String()
+}
+
+// escapeLineStart escapes characters at the beginning of a paragraph that would otherwise start a different block.
+func escapeLineStart(s string) string {
+	if s == "" {
+		return s
+	}
+
+	switch s[0] {
+	case '-', '+', '>', '=':
+		return "\\" + s
+	}
+
+	digits := 0
+	for digits < len(s) && s[digits] >= '0' && s[digits] <= '9' {
+		digits++
+	}
+	if digits > 0 && digits < len(s) && (s[digits] == '.' || s[digits] == ')') {
+		return s[:digits] + "\\" + s[digits:]
+	}
+	return s
+}
+
+const escapedCharacters = "\\`*_[]<>~$#|"
+
+// escape escapes the characters in s that have meaning in inline Markdown.
+func escape(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(escapedCharacters, r) {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// inlineWriter writes rich text as inline Markdown. Annotations that are shared by consecutive rich text objects
+// are only opened and closed once, so that the output is valid CommonMark.
+type inlineWriter struct {
+	buf  []byte
+	open []string
+	link string
+}
+
+var closers = map[string]string{"**": "**", "*": "*", "~~": "~~", "<u>": "</u>"}
+
+func markers(a notion.Annotations) []string {
+	var m []string
+	if a.Bold {
+		m = append(m, "**")
+	}
+	if a.Italic {
+		m = append(m, "*")
+	}
+	if a.Strikethrough {
+		m = append(m, "~~")
+	}
+	if a.Underline {
+		m = append(m, "<u>")
+	}
+	return m
+}
+
+func linkURL(rt *notion.RichText) string {
+	if rt.Text != nil {
+		if u := rt.Text.GetURL(); u != nil {
+			return u.String()
+		}
+	}
+	return rt.HRef
+}
+
+func (iw *inlineWriter) writeRichText(rt *notion.RichText) {
+	if rt == nil {
+		return
+	}
+
+	var content string
+	switch rt.Type {
+	case notion.RichTextTypeEnumText:
+		if rt.Text == nil {
+			return
+		}
+		content = rt.Text.Content
+	case notion.RichTextTypeEnumEquation:
+		if rt.Equation == nil {
+			return
+		}
+		content = rt.Equation.Expression
+	default:
+		content = rt.PlainText
+	}
+	if content == "" {
+		return
+	}
+
+	if link := linkURL(rt); link != iw.link {
+		iw.closeLink()
+		if link != "" {
+			trimmed := strings.TrimLeft(content, " \t")
+			iw.buf = append(iw.buf, content[:len(content)-len(trimmed)]...)
+			iw.buf = append(iw.buf, '[')
+			content = trimmed
+		}
+		iw.link = link
+	}
+
+	want := markers(rt.Annotations)
+	if strings.TrimSpace(content) == "" && !rt.Annotations.Code {
+		// Whitespace cannot be wrapped in emphasis, so keep whatever is currently open.
+		want = iw.open
+	}
+	common := 0
+	for common < len(iw.open) && common < len(want) && iw.open[common] == want[common] {
+		common++
+	}
+	iw.closeTo(common)
+
+	if len(want) > common {
+		trimmed := strings.TrimLeft(content, " \t")
+		iw.buf = append(iw.buf, content[:len(content)-len(trimmed)]...)
+		content = trimmed
+		for _, m := range want[common:] {
+			iw.buf = append(iw.buf, m...)
+		}
+		iw.open = append(iw.open, want[common:]...)
+	}
+
+	switch {
+	case rt.Annotations.Code:
+		iw.buf = append(iw.buf, codeSpan(content)...)
+	case rt.Type == notion.RichTextTypeEnumEquation:
+		iw.buf = append(iw.buf, '$')
+		iw.buf = append(iw.buf, strings.ReplaceAll(content, "$", "\\$")...)
+		iw.buf = append(iw.buf, '$')
+	default:
+		iw.buf = append(iw.buf, escape(content)...)
+	}
+}
+
+// closeTo closes the open annotations until only n remain open.
+// Trailing whitespace is moved outside of the closed annotations.
+func (iw *inlineWriter) closeTo(n int) {
+	if n >= len(iw.open) {
+		return
+	}
+
+	trimmed := strings.TrimRight(string(iw.buf), " \t")
+	space := string(iw.buf[len(trimmed):])
+	iw.buf = iw.buf[:len(trimmed)]
+	for i := len(iw.open) - 1; i >= n; i-- {
+		iw.buf = append(iw.buf, closers[iw.open[i]]...)
+	}
+	iw.buf = append(iw.buf, space...)
+	iw.open = iw.open[:n]
+}
+
+func (iw *inlineWriter) closeLink() {
+	iw.closeTo(0)
+	if iw.link != "" {
+		iw.buf = append(iw.buf, "]("...)
+		iw.buf = append(iw.buf, escapeURL(iw.link)...)
+		iw.buf = append(iw.buf, ')')
+		iw.link = ""
+	}
+}
+
+// String closes any open annotations or link and returns the Markdown.
+func (iw *inlineWriter) String() string {
+	iw.closeLink()
+	return string(iw.buf)
+}
+
+func codeSpan(s string) string {
+	fence := "`"
+	for strings.Contains(s, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") || fence != "`" {
+		return fence + " " + s + " " + fence
+	}
+	return fence + s + fence
+}
+
+var urlReplacer = strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29", "<", "%3C", ">", "%3E")
+
+func escapeURL(u string) string {
+	return urlReplacer.Replace(u)
+}
@@ -0,0 +1,64 @@
+package mirror
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/thedadams/gotion/notion"
+	"github.com/thedadams/gotion/richtext"
+)
+
+// A blockRow is a block of a page, with its place in the tree of blocks.
+type blockRow struct {
+	block    *notion.Block
+	parentID string
+	position int
+}
+
+// blockRows flattens the tree of blocks of the page from Client.GetBlockTree, depth first.
+func blockRows(pageID string, blocks []*notion.Block) []*blockRow {
+	var rows []*blockRow
+	var walk func(parentID string, blocks []*notion.Block)
+	walk = func(parentID string, blocks []*notion.Block) {
+		for position, b := range blocks {
+			rows = append(rows, &blockRow{block: b, parentID: parentID, position: position})
+			walk(b.ID.String(), b.Children)
+		}
+	}
+	walk(pageID, blocks)
+	return rows
+}
+
+// saveBlocks replaces the blocks of the page with the given ones.
+func saveBlocks(ctx context.Context, tx *sql.Tx, pageID string, rows []*blockRow) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM notion_blocks WHERE page_id = ?`, pageID); err != nil {
+		return err
+	}
+
+	for _, r := range rows {
+		b := *r.block
+		b.Children = nil
+		raw, err := json.Marshal(&b)
+		if err != nil {
+			return err
+		}
+
+		// A block that was moved from another page is still in the mirror with that page.
+		if _, err := tx.ExecContext(ctx, `DELETE FROM notion_blocks WHERE id = ?`, b.ID.String()); err != nil {
+			return err
+		}
+		text := richtext.PlainText(b.Text)
+		if text == "" {
+			text = b.GetTitle()
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO notion_blocks
+			(id, page_id, parent_id, position, type, plain_text, has_children, created_time, last_edited_time, raw)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			b.ID.String(), pageID, r.parentID, r.position, string(b.Type), text, b.HasChildren,
+			timestamp(b.CreatedTime), timestamp(b.LastEditedTime), string(raw)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+package mirror_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// fakeDriver is a database/sql driver for in-memory databases that understand the statements of the mirror, and no more.
+// Each data source name is a separate database.
+type fakeDriver struct {
+	mu  sync.Mutex
+	dbs map[string]*fakeDB
+}
+
+func init() {
+	sql.Register("mirrortest", &fakeDriver{dbs: make(map[string]*fakeDB)})
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	db := d.dbs[name]
+	if db == nil {
+		db = &fakeDB{tables: make(map[string]*fakeTable)}
+		d.dbs[name] = db
+	}
+	return &fakeConn{db: db}, nil
+}
+
+type fakeTable struct {
+	columns map[string]bool
+	rows    []map[string]driver.Value
+}
+
+type fakeDB struct {
+	mu     sync.Mutex
+	tables map[string]*fakeTable
+}
+
+// snapshot returns a copy of the tables, to restore when a transaction is rolled back.
+func (db *fakeDB) snapshot() map[string]*fakeTable {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	tables := make(map[string]*fakeTable, len(db.tables))
+	for name, t := range db.tables {
+		c := &fakeTable{columns: t.columns}
+		for _, row := range t.rows {
+			r := make(map[string]driver.Value, len(row))
+			for k, v := range row {
+				r[k] = v
+			}
+			c.rows = append(c.rows, r)
+		}
+		tables[name] = c
+	}
+	return tables
+}
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{db: c.db, query: strings.Join(strings.Fields(query), " ")}, nil
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return &fakeTx{db: c.db, saved: c.db.snapshot()}, nil
+}
+
+type fakeTx struct {
+	db    *fakeDB
+	saved map[string]*fakeTable
+}
+
+func (tx *fakeTx) Commit() error { return nil }
+
+func (tx *fakeTx) Rollback() error {
+	tx.db.mu.Lock()
+	defer tx.db.mu.Unlock()
+	tx.db.tables = tx.saved
+	return nil
+}
+
+var (
+	createRE = regexp.MustCompile(`^CREATE TABLE IF NOT EXISTS (\w+) \((.*)\)$`)
+	deleteRE = regexp.MustCompile(`^DELETE FROM (\w+) WHERE (\w+) = \?$`)
+	insertRE = regexp.MustCompile(`^INSERT INTO (\w+) \(([^)]*)\) VALUES \(([^)]*)\)$`)
+	selectRE = regexp.MustCompile(`^SELECT ([\w, ]+) FROM (\w+)(?: WHERE (\w+) = \?)?$`)
+	updateRE = regexp.MustCompile(`^UPDATE (\w+) SET (.*) WHERE (\w+) = \?$`)
+	keyRE    = regexp.MustCompile(`PRIMARY KEY \([^)]*\)`)
+)
+
+type fakeStmt struct {
+	db    *fakeDB
+	query string
+}
+
+func (s *fakeStmt) Close() error { return nil }
+
+func (s *fakeStmt) NumInput() int { return strings.Count(s.query, "?") }
+
+func (s *fakeStmt) table(name string) (*fakeTable, error) {
+	t := s.db.tables[name]
+	if t == nil {
+		return nil, fmt.Errorf("no such table: %s", name)
+	}
+	return t, nil
+}
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if m := createRE.FindStringSubmatch(s.query); m != nil {
+		if s.db.tables[m[1]] != nil {
+			return driver.RowsAffected(0), nil
+		}
+		t := &fakeTable{columns: make(map[string]bool)}
+		for _, def := range strings.Split(keyRE.ReplaceAllString(m[2], ""), ",") {
+			if fields := strings.Fields(def); len(fields) != 0 {
+				t.columns[fields[0]] = true
+			}
+		}
+		s.db.tables[m[1]] = t
+		return driver.RowsAffected(0), nil
+	}
+	if m := deleteRE.FindStringSubmatch(s.query); m != nil {
+		t, err := s.table(m[1])
+		if err != nil {
+			return nil, err
+		}
+		kept := t.rows[:0]
+		for _, row := range t.rows {
+			if row[m[2]] != args[0] {
+				kept = append(kept, row)
+			}
+		}
+		n := len(t.rows) - len(kept)
+		t.rows = kept
+		return driver.RowsAffected(n), nil
+	}
+	if m := insertRE.FindStringSubmatch(s.query); m != nil {
+		t, err := s.table(m[1])
+		if err != nil {
+			return nil, err
+		}
+		row := make(map[string]driver.Value)
+		for i, col := range strings.Split(m[2], ",") {
+			col = strings.TrimSpace(col)
+			if !t.columns[col] {
+				return nil, fmt.Errorf("no such column: %s", col)
+			}
+			row[col] = args[i]
+		}
+		t.rows = append(t.rows, row)
+		return driver.RowsAffected(1), nil
+	}
+	if m := updateRE.FindStringSubmatch(s.query); m != nil {
+		t, err := s.table(m[1])
+		if err != nil {
+			return nil, err
+		}
+		var cols []string
+		for _, set := range strings.Split(m[2], ",") {
+			cols = append(cols, strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(set), "= ?")))
+		}
+		n := 0
+		for _, row := range t.rows {
+			if row[m[3]] == args[len(args)-1] {
+				for i, col := range cols {
+					row[col] = args[i]
+				}
+				n++
+			}
+		}
+		return driver.RowsAffected(n), nil
+	}
+	return nil, fmt.Errorf("unsupported statement: %s", s.query)
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	m := selectRE.FindStringSubmatch(s.query)
+	if m == nil {
+		return nil, fmt.Errorf("unsupported query: %s", s.query)
+	}
+	t, err := s.table(m[2])
+	if err != nil {
+		return nil, err
+	}
+	rows := &fakeRows{}
+	for _, col := range strings.Split(m[1], ",") {
+		rows.columns = append(rows.columns, strings.TrimSpace(col))
+	}
+	for _, row := range t.rows {
+		if m[3] != "" && row[m[3]] != args[0] {
+			continue
+		}
+		values := make([]driver.Value, 0, len(rows.columns))
+		for _, col := range rows.columns {
+			values = append(values, row[col])
+		}
+		rows.values = append(rows.values, values)
+	}
+	return rows, nil
+}
+
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
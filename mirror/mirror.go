@@ -0,0 +1,471 @@
+// Package mirror mirrors the databases, pages, and blocks of a workspace in the Notion API into a SQL database,
+// so that they can be queried with SQL without making requests to the Notion API.
+//
+// The mirror is written for SQLite, and works with any driver for it, like modernc.org/sqlite or github.com/mattn/go-sqlite3,
+// which must be imported by the caller. The tables only use portable column types, so other databases that accept ? placeholders,
+// like MySQL, also work.
+//
+//	db, err := sql.Open("sqlite", "notion.db")
+//	stats, err := mirror.New(client, db).Sync(ctx)
+//
+// Each Sync searches the Notion API for the pages and databases that have been edited since the last sync,
+// newest first, and stops at the first one that is older. Only the integration's pages and databases are found.
+//
+// Search doesn't return archived pages, so once every DefaultReconcileInterval, or the interval set with WithReconcileInterval,
+// a Sync reconciles the mirror: it searches everything, and looks up each mirrored page that wasn't found. Pages that
+// have been archived are marked as archived, and pages that no longer exist, or that the integration can no longer see,
+// are deleted with their properties and blocks.
+//
+// The tables are:
+//
+//	notion_databases(id, title, parent_type, parent_id, url, created_time, last_edited_time, properties, raw)
+//	notion_pages(id, database_id, title, parent_type, parent_id, url, archived, created_time, last_edited_time, raw)
+//	notion_page_properties(page_id, name, type, value, raw)
+//	notion_blocks(id, page_id, parent_id, position, type, plain_text, has_children, created_time, last_edited_time, raw)
+//	notion_sync(name, value)
+//
+// Times are RFC 3339 in UTC, raw is the JSON of the object as in the Notion API, properties is the JSON of the schema of a database,
+// and value is the JSON of the flattened value of a property, as in the export package, so that it can be used with json_extract.
+package mirror
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/thedadams/gotion"
+	"github.com/thedadams/gotion/export"
+	"github.com/thedadams/gotion/notion"
+)
+
+const (
+	// checkpointName is the name of the row of notion_sync that holds the last edited time of the newest object of the last sync.
+	checkpointName = "last_edited_time"
+	// reconciledName is the name of the row of notion_sync that holds the time of the last reconcile.
+	reconciledName = "reconciled_time"
+)
+
+// DefaultReconcileInterval is the default time between the syncs that reconcile the mirror with everything that search finds.
+const DefaultReconcileInterval = 24 * time.Hour
+
+var schemaStatements = []string{
+	`CREATE TABLE IF NOT EXISTS notion_databases (
+		id VARCHAR(36) PRIMARY KEY,
+		title TEXT,
+		parent_type TEXT,
+		parent_id TEXT,
+		url TEXT,
+		created_time TEXT,
+		last_edited_time TEXT,
+		properties TEXT,
+		raw TEXT
+	)`,
+	`CREATE TABLE IF NOT EXISTS notion_pages (
+		id VARCHAR(36) PRIMARY KEY,
+		database_id TEXT,
+		title TEXT,
+		parent_type TEXT,
+		parent_id TEXT,
+		url TEXT,
+		archived INTEGER,
+		created_time TEXT,
+		last_edited_time TEXT,
+		raw TEXT
+	)`,
+	`CREATE TABLE IF NOT EXISTS notion_page_properties (
+		page_id VARCHAR(36),
+		name VARCHAR(255),
+		type TEXT,
+		value TEXT,
+		raw TEXT,
+		PRIMARY KEY (page_id, name)
+	)`,
+	`CREATE TABLE IF NOT EXISTS notion_blocks (
+		id VARCHAR(36) PRIMARY KEY,
+		page_id TEXT,
+		parent_id TEXT,
+		position INTEGER,
+		type TEXT,
+		plain_text TEXT,
+		has_children INTEGER,
+		created_time TEXT,
+		last_edited_time TEXT,
+		raw TEXT
+	)`,
+	`CREATE TABLE IF NOT EXISTS notion_sync (
+		name VARCHAR(255) PRIMARY KEY,
+		value TEXT
+	)`,
+}
+
+// An Option is a way of customizing a Mirror.
+type Option func(*Mirror)
+
+// WithoutBlocks only mirrors databases and pages, not the blocks of pages, which take a request for each block with children.
+func WithoutBlocks() Option {
+	return func(m *Mirror) {
+		m.blocks = false
+	}
+}
+
+// WithFullSync ignores the checkpoint of the last sync on the next Sync, so that everything the integration can find is mirrored.
+func WithFullSync() Option {
+	return func(m *Mirror) {
+		m.full = true
+	}
+}
+
+// WithReconcileInterval sets the time between the syncs that reconcile the mirror, marking the pages that have been archived
+// and deleting the ones that are gone. The default is DefaultReconcileInterval, and zero or less never reconciles.
+// A full sync always reconciles.
+func WithReconcileInterval(d time.Duration) Option {
+	return func(m *Mirror) {
+		m.reconcileInterval = d
+	}
+}
+
+// A Mirror mirrors a workspace in the Notion API into a SQL database.
+type Mirror struct {
+	client            *gotion.Client
+	db                *sql.DB
+	exporter          *export.Exporter
+	blocks            bool
+	full              bool
+	reconcileInterval time.Duration
+}
+
+// New returns a Mirror of the workspace of the client into the SQL database.
+func New(c *gotion.Client, db *sql.DB, options ...Option) *Mirror {
+	m := &Mirror{
+		client:   c,
+		db:       db,
+		exporter: export.New(c, export.WithDateTimeFormat(time.RFC3339), export.WithLocation(time.UTC)),
+		blocks:   true,
+
+		reconcileInterval: DefaultReconcileInterval,
+	}
+	for _, opt := range options {
+		opt(m)
+	}
+	return m
+}
+
+// SyncStats are the number of objects that a Sync mirrored.
+type SyncStats struct {
+	Databases int
+	Pages     int
+	Blocks    int
+	// Archived is the number of mirrored pages that a reconcile found to be archived or gone.
+	Archived int
+	// Reconciled is true if the sync reconciled the mirror with everything that search finds.
+	Reconciled bool
+	// Since is the checkpoint that the sync started from, which is zero for the first sync.
+	Since time.Time
+	// Until is the checkpoint for the next sync: the last edited time of the newest object that has been mirrored.
+	Until time.Time
+}
+
+// Sync creates the tables if they don't exist, and mirrors the pages and databases that have been edited since the last sync.
+// The checkpoint is only saved if the sync completes, so a sync that fails is repeated by the next one.
+// When a reconcile is due, the search continues past the checkpoint, without mirroring the older objects again,
+// and the mirrored pages that it didn't find are looked up.
+//
+// The Notion API rounds last edited times to the minute, so the objects edited in the same minute as the checkpoint
+// are mirrored again, to not miss the ones that were edited after the last sync.
+func (m *Mirror) Sync(ctx context.Context) (*SyncStats, error) {
+	for _, stmt := range schemaStatements {
+		if _, err := m.db.ExecContext(ctx, stmt); err != nil {
+			return nil, fmt.Errorf("creating the mirror tables: %w", err)
+		}
+	}
+
+	stats := new(SyncStats)
+	if !m.full {
+		since, err := m.syncTime(ctx, checkpointName)
+		if err != nil {
+			return nil, err
+		}
+		stats.Since = since
+	}
+	stats.Until = stats.Since
+
+	reconciled, err := m.syncTime(ctx, reconciledName)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	stats.Reconciled = m.full || (m.reconcileInterval > 0 && start.Sub(reconciled) >= m.reconcileInterval)
+	seen := make(map[string]bool)
+
+	sort, err := notion.NewTimestampSort(notion.SortTimestampEnumLastEditedTime, notion.SortDirectionEnumDescending)
+	if err != nil {
+		return nil, err
+	}
+	it := m.client.IterateSearch(&gotion.SearchQuery{Sort: sort})
+	for it.Next(ctx) {
+		result := it.Value()
+		var edited time.Time
+		if result.Page != nil {
+			edited = result.Page.LastEditedTime
+			seen[result.Page.ID.String()] = true
+		} else {
+			edited = result.Database.LastEditedTime
+		}
+		if !stats.Since.IsZero() && edited.Before(stats.Since) {
+			if stats.Reconciled {
+				continue
+			}
+			break
+		}
+		if edited.After(stats.Until) {
+			stats.Until = edited
+		}
+
+		if result.Page != nil {
+			blocks, err := m.savePage(ctx, result.Page)
+			if err != nil {
+				return stats, err
+			}
+			stats.Pages++
+			stats.Blocks += blocks
+		} else {
+			if err := m.saveDatabase(ctx, result.Database); err != nil {
+				return stats, err
+			}
+			stats.Databases++
+		}
+	}
+	if err := it.Err(); err != nil {
+		return stats, err
+	}
+
+	if stats.Reconciled {
+		if stats.Archived, err = m.reconcile(ctx, seen); err != nil {
+			return stats, err
+		}
+		if err := m.setSyncTime(ctx, reconciledName, start); err != nil {
+			return stats, err
+		}
+	}
+	if err := m.setSyncTime(ctx, checkpointName, stats.Until); err != nil {
+		return stats, err
+	}
+	m.full = false
+	return stats, nil
+}
+
+// reconcile looks up the mirrored pages that are not archived and that a search of everything didn't find.
+// The ones that have been archived are marked as archived, and the ones that no longer exist, or that the integration
+// can no longer see, are deleted with their properties and blocks. It returns the number of pages that were marked or deleted.
+func (m *Mirror) reconcile(ctx context.Context, seen map[string]bool) (int, error) {
+	rows, err := m.db.QueryContext(ctx, `SELECT id FROM notion_pages WHERE archived = ?`, false)
+	if err != nil {
+		return 0, err
+	}
+	var missing []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if !seen[id] {
+			missing = append(missing, id)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for _, id := range missing {
+		page, err := m.client.GetPage(ctx, id)
+		switch {
+		case notion.IsNotFound(err):
+			err = m.deletePage(ctx, id)
+		case err != nil:
+			return n, err
+		case page.Archived:
+			err = m.archivePage(ctx, page)
+		default:
+			// The page exists and is not archived: search may not have indexed it yet.
+			continue
+		}
+		if err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// archivePage marks the mirrored page as archived, leaving its properties and blocks as they were before it was archived.
+func (m *Mirror) archivePage(ctx context.Context, page *notion.Page) error {
+	raw, err := json.Marshal(page)
+	if err != nil {
+		return err
+	}
+	_, err = m.db.ExecContext(ctx, `UPDATE notion_pages SET archived = ?, last_edited_time = ?, raw = ? WHERE id = ?`,
+		true, timestamp(page.LastEditedTime), string(raw), page.ID.String())
+	return err
+}
+
+// deletePage deletes the mirrored page with its properties and blocks.
+func (m *Mirror) deletePage(ctx context.Context, id string) error {
+	return m.inTx(ctx, func(tx *sql.Tx) error {
+		for _, stmt := range []string{
+			`DELETE FROM notion_pages WHERE id = ?`,
+			`DELETE FROM notion_page_properties WHERE page_id = ?`,
+			`DELETE FROM notion_blocks WHERE page_id = ?`,
+		} {
+			if _, err := tx.ExecContext(ctx, stmt, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// syncTime returns the time in the row of notion_sync with the given name, or the zero time if there is none.
+func (m *Mirror) syncTime(ctx context.Context, name string) (time.Time, error) {
+	var value string
+	err := m.db.QueryRowContext(ctx, `SELECT value FROM notion_sync WHERE name = ?`, name).Scan(&value)
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("reading the sync state %s: %w", name, err)
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+func (m *Mirror) setSyncTime(ctx context.Context, name string, t time.Time) error {
+	if t.IsZero() {
+		return nil
+	}
+	return m.inTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM notion_sync WHERE name = ?`, name); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `INSERT INTO notion_sync (name, value) VALUES (?, ?)`, name, timestamp(t))
+		return err
+	})
+}
+
+func (m *Mirror) saveDatabase(ctx context.Context, db *notion.Database) error {
+	raw, err := json.Marshal(db)
+	if err != nil {
+		return err
+	}
+	props, err := json.Marshal(&db.Properties)
+	if err != nil {
+		return err
+	}
+
+	return m.inTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM notion_databases WHERE id = ?`, db.ID.String()); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `INSERT INTO notion_databases
+			(id, title, parent_type, parent_id, url, created_time, last_edited_time, properties, raw)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			db.ID.String(), notion.PlainText(db.Title), string(db.Parent.Type), db.Parent.ID, urlString((*url.URL)(db.URL)),
+			timestamp(db.CreatedTime), timestamp(db.LastEditedTime), string(props), string(raw))
+		return err
+	})
+}
+
+// savePage saves the page, its properties and, unless they are not mirrored, its blocks.
+// It returns the number of blocks that were saved.
+func (m *Mirror) savePage(ctx context.Context, page *notion.Page) (int, error) {
+	var blocks []*blockRow
+	if m.blocks {
+		tree, err := m.client.GetBlockTree(ctx, page.ID.String(), -1, 0)
+		if err != nil {
+			return 0, err
+		}
+		blocks = blockRows(page.ID.String(), tree)
+	}
+	raw, err := json.Marshal(page)
+	if err != nil {
+		return 0, err
+	}
+
+	databaseID := ""
+	if page.Parent.Type == notion.ParentTypeEnumDatabase {
+		databaseID = page.Parent.ID
+	}
+	title, _ := page.Title()
+	id := page.ID.String()
+
+	return len(blocks), m.inTx(ctx, func(tx *sql.Tx) error {
+		for _, stmt := range []string{
+			`DELETE FROM notion_pages WHERE id = ?`,
+			`DELETE FROM notion_page_properties WHERE page_id = ?`,
+		} {
+			if _, err := tx.ExecContext(ctx, stmt, id); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO notion_pages
+			(id, database_id, title, parent_type, parent_id, url, archived, created_time, last_edited_time, raw)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			id, databaseID, title, string(page.Parent.Type), page.Parent.ID, urlString((*url.URL)(page.URL)),
+			page.Archived, timestamp(page.CreatedTime), timestamp(page.LastEditedTime), string(raw)); err != nil {
+			return err
+		}
+
+		for _, prop := range page.Properties {
+			if prop == nil {
+				continue
+			}
+			value, err := json.Marshal(m.exporter.Value(prop))
+			if err != nil {
+				return err
+			}
+			rawProp, err := json.Marshal(prop)
+			if err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, `INSERT INTO notion_page_properties (page_id, name, type, value, raw) VALUES (?, ?, ?, ?, ?)`,
+				id, prop.Name, string(prop.Type), string(value), string(rawProp)); err != nil {
+				return err
+			}
+		}
+
+		if !m.blocks {
+			return nil
+		}
+		return saveBlocks(ctx, tx, id, blocks)
+	})
+}
+
+func (m *Mirror) inTx(ctx context.Context, f func(*sql.Tx) error) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := f(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func timestamp(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func urlString(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	return u.String()
+}
@@ -0,0 +1,236 @@
+package mirror_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/thedadams/gotion/gotiontest"
+	"github.com/thedadams/gotion/mirror"
+	"github.com/thedadams/gotion/notion"
+	"github.com/thedadams/gotion/richtext"
+)
+
+// day returns the time of noon on the given day of January 2022.
+func day(d int) time.Time {
+	return time.Date(2022, 1, d, 12, 0, 0, 0, time.UTC)
+}
+
+// toggle returns a toggle block with the text and children.
+func toggle(text string, children ...*notion.Block) *notion.Block {
+	return &notion.Block{Type: notion.BlockTypeEnumToggle, Text: richtext.New().Text(text).Build(), Children: children}
+}
+
+// addTasks adds a database of tasks, edited on the first day, to the server.
+func addTasks(t *testing.T, s *gotiontest.Server) string {
+	t.Helper()
+	db, err := s.AddDatabase(&notion.Database{
+		Title:      notion.FromPlainText("Tasks"),
+		Editable:   notion.Editable{LastEditedTime: day(1)},
+		Properties: notion.DatabaseProperties{{Name: "Task", Type: notion.DatabasePropertyTypeEnumTitle}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db.ID.String()
+}
+
+// addTask adds a task, last edited on the given day, to the database, and returns its id.
+func addTask(t *testing.T, s *gotiontest.Server, databaseID, title string, edited int, blocks ...*notion.Block) string {
+	t.Helper()
+	props := notion.PageProperties{{Name: "Task", Type: notion.DatabasePropertyTypeEnumTitle}}
+	if err := props.SetTitle(title); err != nil {
+		t.Fatal(err)
+	}
+	page, err := s.AddPage(&notion.Page{
+		Parent:     notion.Parent{Type: notion.ParentTypeEnumDatabase, ID: databaseID},
+		Properties: props,
+		Editable:   notion.Editable{LastEditedTime: day(edited)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) != 0 {
+		if err := s.AddBlocks(page.ID.String(), blocks...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return page.ID.String()
+}
+
+func openDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("mirrortest", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// count returns the number of rows of the table with the value in the column.
+func count(t *testing.T, db *sql.DB, table, column string, value interface{}) int {
+	t.Helper()
+	rows, err := db.Query(`SELECT `+column+` FROM `+table+` WHERE `+column+` = ?`, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	n := 0
+	for rows.Next() {
+		n++
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestSyncIncremental(t *testing.T) {
+	s := gotiontest.NewServer()
+	defer s.Close()
+	databaseID := addTasks(t, s)
+	first := addTask(t, s, databaseID, "First", 2, toggle("1", toggle("1.1")), toggle("2"))
+	addTask(t, s, databaseID, "Second", 3)
+
+	ctx := context.Background()
+	db := openDB(t)
+	m := mirror.New(s.NewClient(), db, mirror.WithReconcileInterval(0))
+
+	stats, err := m.Sync(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Databases != 1 || stats.Pages != 2 || stats.Blocks != 3 || stats.Reconciled {
+		t.Errorf("got %+v, want 1 database, 2 pages, 3 blocks, and no reconcile", stats)
+	}
+	if !stats.Since.IsZero() || !stats.Until.Equal(day(3)) {
+		t.Errorf("got checkpoints %v and %v, want zero and %v", stats.Since, stats.Until, day(3))
+	}
+	if n := count(t, db, "notion_blocks", "page_id", first); n != 3 {
+		t.Errorf("got %d blocks of the first page, want 3", n)
+	}
+
+	// The nested block is mirrored under its parent block, and the others under the page.
+	var parentID, text string
+	var position int64
+	if err := db.QueryRow(`SELECT parent_id, position FROM notion_blocks WHERE plain_text = ?`, "2").Scan(&parentID, &position); err != nil {
+		t.Fatal(err)
+	}
+	if parentID != first || position != 1 {
+		t.Errorf("got parent %s and position %d for the second block, want %s and 1", parentID, position, first)
+	}
+	if err := db.QueryRow(`SELECT parent_id FROM notion_blocks WHERE plain_text = ?`, "1.1").Scan(&parentID); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.QueryRow(`SELECT plain_text FROM notion_blocks WHERE id = ?`, parentID).Scan(&text); err != nil || text != "1" {
+		t.Errorf("got parent %q and error %v for the nested block, want the first block", text, err)
+	}
+
+	// The next sync starts from the checkpoint: the page edited at the checkpoint is mirrored again, and the older ones are not.
+	third := addTask(t, s, databaseID, "Third", 4)
+	stats, err = m.Sync(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Databases != 0 || stats.Pages != 2 || stats.Blocks != 0 {
+		t.Errorf("got %+v, want only the second and third pages", stats)
+	}
+	if !stats.Since.Equal(day(3)) || !stats.Until.Equal(day(4)) {
+		t.Errorf("got checkpoints %v and %v, want %v and %v", stats.Since, stats.Until, day(3), day(4))
+	}
+	if n := count(t, db, "notion_pages", "id", third); n != 1 {
+		t.Errorf("got %d rows for the third page, want 1", n)
+	}
+	if n := count(t, db, "notion_pages", "database_id", databaseID); n != 3 {
+		t.Errorf("got %d pages, want 3", n)
+	}
+
+	// A sync with nothing new keeps the checkpoint.
+	stats, err = m.Sync(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Pages != 1 || !stats.Until.Equal(day(4)) {
+		t.Errorf("got %+v, want the third page again and the same checkpoint", stats)
+	}
+}
+
+func TestSyncReconcile(t *testing.T) {
+	s := gotiontest.NewServer()
+	defer s.Close()
+	databaseID := addTasks(t, s)
+	archived := addTask(t, s, databaseID, "Archived", 2, toggle("1"))
+	kept := addTask(t, s, databaseID, "Kept", 3)
+
+	ctx := context.Background()
+	c := s.NewClient()
+	db := openDB(t)
+
+	// The first sync always reconciles, and the next one doesn't until the interval has passed.
+	stats, err := mirror.New(c, db, mirror.WithReconcileInterval(time.Hour)).Sync(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stats.Reconciled || stats.Archived != 0 {
+		t.Errorf("got %+v, want a reconcile that found nothing", stats)
+	}
+	if stats, err = mirror.New(c, db, mirror.WithReconcileInterval(time.Hour)).Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if stats.Reconciled {
+		t.Errorf("got %+v, want no reconcile before the interval has passed", stats)
+	}
+
+	// A page that the integration can no longer see is in the mirror, but not in the Notion API.
+	gone := "6f1c2d3e-4b5a-4978-8a6b-5c4d3e2f1a0b"
+	for _, row := range []struct {
+		stmt string
+		args []interface{}
+	}{
+		{`INSERT INTO notion_pages (id, database_id, archived) VALUES (?, ?, ?)`, []interface{}{gone, databaseID, false}},
+		{`INSERT INTO notion_page_properties (page_id, name) VALUES (?, ?)`, []interface{}{gone, "Task"}},
+		{`INSERT INTO notion_blocks (id, page_id) VALUES (?, ?)`, []interface{}{"7a8b9c0d-1e2f-4a3b-8c4d-5e6f7a8b9c0d", gone}},
+	} {
+		if _, err := db.Exec(row.stmt, row.args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := c.ArchivePage(ctx, archived); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err = mirror.New(c, db, mirror.WithFullSync()).Sync(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stats.Reconciled || stats.Archived != 2 || stats.Pages != 1 {
+		t.Errorf("got %+v, want a reconcile of 2 pages, and the page that was kept", stats)
+	}
+
+	for _, tt := range []struct {
+		id   string
+		want bool
+	}{{archived, true}, {kept, false}} {
+		var got bool
+		if err := db.QueryRow(`SELECT archived FROM notion_pages WHERE id = ?`, tt.id).Scan(&got); err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("got archived %v for page %s, want %v", got, tt.id, tt.want)
+		}
+	}
+	// The archived page keeps its blocks, and the page that is gone is deleted with its properties and blocks.
+	if n := count(t, db, "notion_blocks", "page_id", archived); n != 1 {
+		t.Errorf("got %d blocks of the archived page, want 1", n)
+	}
+	for _, table := range []string{"notion_page_properties", "notion_blocks"} {
+		if n := count(t, db, table, "page_id", gone); n != 0 {
+			t.Errorf("got %d rows of %s for the page that is gone, want 0", n, table)
+		}
+	}
+	if n := count(t, db, "notion_pages", "id", gone); n != 0 {
+		t.Errorf("got %d rows for the page that is gone, want 0", n)
+	}
+}
@@ -1,6 +1,9 @@
 package notion
 
-import "encoding/json"
+import (
+	"bytes"
+	"encoding/json"
+)
 
 // These constants represent the valid type enums for a block type
 const (
@@ -13,7 +16,32 @@ const (
 	BlockTypeEnumToDo             = "to_do"
 	BlockTypeEnumToggle           = "toggle"
 	BlockTypeEnumChildPage        = "child_page"
+	BlockTypeEnumChildDatabase    = "child_database"
+	BlockTypeEnumEmbed            = "embed"
+	BlockTypeEnumImage            = "image"
+	BlockTypeEnumVideo            = "video"
+	BlockTypeEnumFile             = "file"
+	BlockTypeEnumPDF              = "pdf"
+	BlockTypeEnumAudio            = "audio"
+	BlockTypeEnumBookmark         = "bookmark"
+	BlockTypeEnumCallout          = "callout"
+	BlockTypeEnumQuote            = "quote"
+	BlockTypeEnumCode             = "code"
+	BlockTypeEnumEquation         = "equation"
+	BlockTypeEnumDivider          = "divider"
+	BlockTypeEnumTableOfContents  = "table_of_contents"
+	BlockTypeEnumBreadcrumb       = "breadcrumb"
+	BlockTypeEnumColumnList       = "column_list"
+	BlockTypeEnumColumn           = "column"
+	BlockTypeEnumLinkPreview      = "link_preview"
+	BlockTypeEnumLinkToPage       = "link_to_page"
+	BlockTypeEnumSyncedBlock      = "synced_block"
+	BlockTypeEnumTemplate         = "template"
+	BlockTypeEnumTable            = "table"
+	BlockTypeEnumTableRow         = "table_row"
 	BlockTypeEnumUnsupported      = "unsupported"
+
+	SyncedFromTypeEnumBlockID = "block_id"
 )
 
 // A BlockTypeEnum represents a type of a block in the Notion API.
@@ -37,16 +65,60 @@ func (bte *BlockTypeEnum) IsValidEnum() bool {
 		BlockTypeEnumToDo,
 		BlockTypeEnumToggle,
 		BlockTypeEnumChildPage,
+		BlockTypeEnumChildDatabase,
+		BlockTypeEnumEmbed,
+		BlockTypeEnumImage,
+		BlockTypeEnumVideo,
+		BlockTypeEnumFile,
+		BlockTypeEnumPDF,
+		BlockTypeEnumAudio,
+		BlockTypeEnumBookmark,
+		BlockTypeEnumCallout,
+		BlockTypeEnumQuote,
+		BlockTypeEnumCode,
+		BlockTypeEnumEquation,
+		BlockTypeEnumDivider,
+		BlockTypeEnumTableOfContents,
+		BlockTypeEnumBreadcrumb,
+		BlockTypeEnumColumnList,
+		BlockTypeEnumColumn,
+		BlockTypeEnumLinkPreview,
+		BlockTypeEnumLinkToPage,
+		BlockTypeEnumSyncedBlock,
+		BlockTypeEnumTemplate,
+		BlockTypeEnumTable,
+		BlockTypeEnumTableRow,
 		BlockTypeEnumUnsupported,
 	)
 }
 
-// UnmarshalJSON returns an error if the provided string is not a valid BlockTypeEnum in the Notion API.
+// UnmarshalJSON sets the BlockTypeEnum to the string, even if it is not valid in the Notion API. Use IsValidEnum to check it.
 func (bte *BlockTypeEnum) UnmarshalJSON(b []byte) error {
 	return unmarshalEnum(b, bte)
 }
 
+// SyncedFromTypeEnum represents the type of the original block of a synced block in the Notion API.
+type SyncedFromTypeEnum string
+
+// SetValue sets the SyncedFromTypeEnum to the given string
+func (sfte *SyncedFromTypeEnum) SetValue(s string) {
+	if sfte != nil {
+		*sfte = SyncedFromTypeEnum(s)
+	}
+}
+
+// IsValidEnum returns true if the string represents a valid SyncedFromTypeEnum in the Notion API.
+func (sfte *SyncedFromTypeEnum) IsValidEnum() bool {
+	return sfte != nil && isValidEnum(string(*sfte), SyncedFromTypeEnumBlockID)
+}
+
+// UnmarshalJSON sets the SyncedFromTypeEnum to the string, even if it is not valid in the Notion API. Use IsValidEnum to check it.
+func (sfte *SyncedFromTypeEnum) UnmarshalJSON(b []byte) error {
+	return unmarshalEnum(b, sfte)
+}
+
 // Block represents the common fields in a "block" in Notion (i.e. text, headings, list, etc)
+// The fields that only apply to some types of blocks are in the field named after the type, for example Code for code blocks.
 type Block struct {
 	Object
 	Editable
@@ -57,8 +129,27 @@ type Block struct {
 	Children    []*Block      `json:"children,omitempty"`
 	// Only valid for To Do blocks
 	Checked *bool `json:"checked,omitempty"`
-	// Only valid for Child Page blocks
+	// Only valid for Child Page and Child Database blocks
 	Title *string `json:"title,omitempty"`
+
+	Callout     *Callout     `json:"callout,omitempty"`
+	Code        *Code        `json:"code,omitempty"`
+	Equation    *Equation    `json:"equation,omitempty"`
+	Image       *Media       `json:"image,omitempty"`
+	Video       *Media       `json:"video,omitempty"`
+	File        *Media       `json:"file,omitempty"`
+	PDF         *Media       `json:"pdf,omitempty"`
+	Audio       *Media       `json:"audio,omitempty"`
+	Bookmark    *Bookmark    `json:"bookmark,omitempty"`
+	Embed       *Bookmark    `json:"embed,omitempty"`
+	LinkPreview *Bookmark    `json:"link_preview,omitempty"`
+	LinkToPage  *Parent      `json:"link_to_page,omitempty"`
+	SyncedBlock *SyncedBlock `json:"synced_block,omitempty"`
+	Table       *Table       `json:"table,omitempty"`
+	TableRow    *TableRow    `json:"table_row,omitempty"`
+
+	// Raw holds the JSON of a block with a type that is not known to this package, so that it is marshaled unchanged.
+	Raw json.RawMessage `json:"-"`
 }
 
 type block Block
@@ -76,14 +167,39 @@ func (b *block) fieldsToExpand() []string {
 	return []string{"text", "children", "checked", "title"}
 }
 
+// blockContent holds the fields that are common to many types of blocks, and are flattened into the Block when unmarshaling.
+// Flattening the whole object of the type into the Block doesn't work, because some types, like image and link_to_page,
+// have a type of their own.
+type blockContent struct {
+	Text     []*RichText `json:"text"`
+	Children []*Block    `json:"children"`
+	Checked  *bool       `json:"checked"`
+	Title    *string     `json:"title"`
+}
+
 // UnmarshalJSON sets the JSON object based on the Type of the Block object.
 // Text and Children are set based on the Type of the Block.
 func (b *Block) UnmarshalJSON(bt []byte) error {
 	bb := new(block)
-	if err := unmarshalJSONFlattenByType(bt, bb); err != nil {
+	if err := json.Unmarshal(bt, bb); err != nil {
 		return err
 	}
 
+	m := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(bt, &m); err != nil {
+		return err
+	}
+	if raw := bytes.TrimSpace(m[bb.getType()]); len(raw) != 0 && raw[0] == '{' {
+		content := new(blockContent)
+		if err := json.Unmarshal(raw, content); err != nil {
+			return err
+		}
+		bb.Text, bb.Children, bb.Checked, bb.Title = content.Text, content.Children, content.Checked, content.Title
+	}
+	if !bb.Type.IsValidEnum() {
+		bb.Raw = append(json.RawMessage(nil), bt...)
+	}
+
 	*b = Block(*bb)
 	return nil
 }
@@ -93,10 +209,129 @@ func (b *Block) MarshalJSON() ([]byte, error) {
 	if b == nil {
 		return nil, nil
 	}
+	if b.Raw != nil && !b.Type.IsValidEnum() {
+		return b.Raw, nil
+	}
 	bb := block(*b)
 	return marshalJSONExpandByType(&bb)
 }
 
+// A Callout holds the fields of a callout block in the Notion API, other than its text and children.
+type Callout struct {
+	Icon *Icon `json:"icon,omitempty"`
+}
+
+// A Code holds the fields of a code block in the Notion API, other than its text.
+type Code struct {
+	Language string      `json:"language"`
+	Caption  []*RichText `json:"caption,omitempty"`
+}
+
+// A Media holds the fields of an image, video, file, pdf, or audio block in the Notion API.
+type Media struct {
+	File    File        `json:"-"`
+	Caption []*RichText `json:"caption,omitempty"`
+}
+
+// UnmarshalJSON unmarshals the file and caption of the media block.
+func (m *Media) UnmarshalJSON(b []byte) error {
+	if err := json.Unmarshal(b, &m.File); err != nil {
+		return err
+	}
+
+	caption := struct {
+		Caption []*RichText `json:"caption"`
+	}{}
+	if err := json.Unmarshal(b, &caption); err != nil {
+		return err
+	}
+	m.Caption = caption.Caption
+	return nil
+}
+
+// MarshalJSON marshals the media block to be compatible with the Notion API.
+func (m *Media) MarshalJSON() ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+
+	b, err := json.Marshal(&m.File)
+	if err != nil {
+		return nil, err
+	}
+	var mm map[string]interface{}
+	if err := json.Unmarshal(b, &mm); err != nil {
+		return nil, err
+	}
+	if mm == nil {
+		mm = make(map[string]interface{})
+	}
+
+	// Files in blocks don't have names.
+	delete(mm, "name")
+	if len(m.Caption) != 0 {
+		mm["caption"] = m.Caption
+	}
+	return json.Marshal(mm)
+}
+
+// A Bookmark holds the fields of a bookmark, embed, or link_preview block in the Notion API.
+type Bookmark struct {
+	URL     string      `json:"url"`
+	Caption []*RichText `json:"caption,omitempty"`
+}
+
+// A SyncedBlock holds the fields of a synced_block block in the Notion API, other than its children.
+// SyncedFrom is nil for the original block, and refers to the original block for its copies.
+type SyncedBlock struct {
+	SyncedFrom *SyncedFrom `json:"synced_from"`
+}
+
+// SyncedFrom refers to the original block of a synced block in the Notion API.
+type SyncedFrom struct {
+	Type    SyncedFromTypeEnum `json:"type"`
+	BlockID UUID4              `json:"block_id"`
+
+	// Raw holds the JSON of a synced block reference with a type that is not known to this package, so that it is marshaled unchanged.
+	Raw json.RawMessage `json:"-"`
+}
+
+type syncedFrom SyncedFrom
+
+// UnmarshalJSON unmarshals the reference, keeping the JSON of a reference with an unknown type.
+func (sf *SyncedFrom) UnmarshalJSON(b []byte) error {
+	s := new(syncedFrom)
+	if err := json.Unmarshal(b, s); err != nil {
+		return err
+	}
+	if s.Type != "" && !s.Type.IsValidEnum() {
+		s.Raw = append(json.RawMessage(nil), b...)
+	}
+
+	*sf = SyncedFrom(*s)
+	return nil
+}
+
+// MarshalJSON marshals the reference, using the original JSON for a reference with an unknown type.
+func (sf *SyncedFrom) MarshalJSON() ([]byte, error) {
+	if sf.Raw != nil && !sf.Type.IsValidEnum() {
+		return sf.Raw, nil
+	}
+	return json.Marshal((*syncedFrom)(sf))
+}
+
+// A Table holds the fields of a table block in the Notion API. The rows of the table are its children.
+type Table struct {
+	TableWidth      int  `json:"table_width"`
+	HasColumnHeader bool `json:"has_column_header"`
+	HasRowHeader    bool `json:"has_row_header"`
+}
+
+// A TableRow holds the cells of a table_row block in the Notion API.
+type TableRow struct {
+	Cells [][]*RichText `json:"cells"`
+}
+
 // IsChecked returns true if the Block is a To Do block and it is checked,
 // and returns false otherwise
 func (b *Block) IsChecked() bool {
@@ -0,0 +1,250 @@
+package notion_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/thedadams/gotion/notion"
+)
+
+const (
+	blockText = `"text":[{"type":"text","text":{"content":"hi"},"plain_text":"hi","annotations":` +
+		`{"bold":false,"italic":false,"strikethrough":false,"underline":false,"code":false,"color":"default"}}]`
+	blockFields = `"object":"block","id":"0b9c3b0a-5f5e-4a4c-9d2f-8d3b1f9e2c11","has_children":false,"archived":false`
+	otherID     = "5e1a2f0c-7d3b-4c8e-9a61-2b4f8d0e3c77"
+)
+
+func TestBlockJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		json  string
+		check func(*notion.Block) bool
+	}{
+		{
+			name:  "paragraph",
+			json:  `{"type":"paragraph","paragraph":{` + blockText + `}}`,
+			check: func(b *notion.Block) bool { return len(b.Text) == 1 && b.Text[0].PlainText == "hi" },
+		},
+		{
+			name:  "heading_1",
+			json:  `{"type":"heading_1","heading_1":{` + blockText + `}}`,
+			check: func(b *notion.Block) bool { return len(b.Text) == 1 },
+		},
+		{
+			name:  "heading_2",
+			json:  `{"type":"heading_2","heading_2":{` + blockText + `}}`,
+			check: func(b *notion.Block) bool { return len(b.Text) == 1 },
+		},
+		{
+			name:  "heading_3",
+			json:  `{"type":"heading_3","heading_3":{` + blockText + `}}`,
+			check: func(b *notion.Block) bool { return len(b.Text) == 1 },
+		},
+		{
+			name:  "bulleted_list_item",
+			json:  `{"type":"bulleted_list_item","bulleted_list_item":{` + blockText + `}}`,
+			check: func(b *notion.Block) bool { return len(b.Text) == 1 },
+		},
+		{
+			name:  "numbered_list_item",
+			json:  `{"type":"numbered_list_item","numbered_list_item":{` + blockText + `}}`,
+			check: func(b *notion.Block) bool { return len(b.Text) == 1 },
+		},
+		{
+			name:  "to_do",
+			json:  `{"type":"to_do","to_do":{` + blockText + `,"checked":true}}`,
+			check: func(b *notion.Block) bool { return b.IsChecked() && len(b.Text) == 1 },
+		},
+		{
+			name: "toggle with children",
+			json: `{"type":"toggle","toggle":{` + blockText + `,"children":[{"type":"paragraph","has_children":false,"archived":false,` +
+				`"paragraph":{` + blockText + `}}]}}`,
+			check: func(b *notion.Block) bool {
+				return len(b.Children) == 1 && b.Children[0].Type == notion.BlockTypeEnumParagraph
+			},
+		},
+		{
+			name:  "child_page",
+			json:  `{"type":"child_page","child_page":{"title":"Plans"}}`,
+			check: func(b *notion.Block) bool { return b.GetTitle() == "Plans" },
+		},
+		{
+			name:  "child_database",
+			json:  `{"type":"child_database","child_database":{"title":"Tasks"}}`,
+			check: func(b *notion.Block) bool { return b.GetTitle() == "Tasks" },
+		},
+		{
+			name:  "embed",
+			json:  `{"type":"embed","embed":{"url":"https://example.com/embed"}}`,
+			check: func(b *notion.Block) bool { return b.Embed != nil && b.Embed.URL == "https://example.com/embed" },
+		},
+		{
+			name: "image",
+			json: `{"type":"image","image":{"type":"external","external":{"url":"https://example.com/a.png"},"caption":[` + blockText[8:] + `}}`,
+			check: func(b *notion.Block) bool {
+				return b.Image != nil && b.Image.File.GetURL() != nil && len(b.Image.Caption) == 1
+			},
+		},
+		{
+			name: "video",
+			json: `{"type":"video","video":{"type":"file","file":{"url":"https://example.com/a.mp4","expiry_time":"2022-01-02T03:04:05Z"}}}`,
+			check: func(b *notion.Block) bool {
+				return b.Video != nil && b.Video.File.Type == notion.FileTypeEnumFile && b.Video.File.GetURL() != nil
+			},
+		},
+		{
+			name:  "file",
+			json:  `{"type":"file","file":{"type":"external","external":{"url":"https://example.com/a.zip"}}}`,
+			check: func(b *notion.Block) bool { return b.File != nil && b.File.File.GetURL() != nil },
+		},
+		{
+			name:  "pdf",
+			json:  `{"type":"pdf","pdf":{"type":"external","external":{"url":"https://example.com/a.pdf"}}}`,
+			check: func(b *notion.Block) bool { return b.PDF != nil && b.PDF.File.GetURL() != nil },
+		},
+		{
+			name:  "audio",
+			json:  `{"type":"audio","audio":{"type":"external","external":{"url":"https://example.com/a.mp3"}}}`,
+			check: func(b *notion.Block) bool { return b.Audio != nil && b.Audio.File.GetURL() != nil },
+		},
+		{
+			name:  "bookmark",
+			json:  `{"type":"bookmark","bookmark":{"url":"https://example.com","caption":[` + blockText[8:] + `}}`,
+			check: func(b *notion.Block) bool { return b.Bookmark != nil && len(b.Bookmark.Caption) == 1 },
+		},
+		{
+			name: "callout",
+			json: `{"type":"callout","callout":{` + blockText + `,"icon":{"type":"emoji","emoji":"💡"}}}`,
+			check: func(b *notion.Block) bool {
+				return b.Callout != nil && b.Callout.Icon.Emoji == "💡" && len(b.Text) == 1
+			},
+		},
+		{
+			name:  "quote",
+			json:  `{"type":"quote","quote":{` + blockText + `}}`,
+			check: func(b *notion.Block) bool { return len(b.Text) == 1 },
+		},
+		{
+			name:  "code",
+			json:  `{"type":"code","code":{` + blockText + `,"language":"go"}}`,
+			check: func(b *notion.Block) bool { return b.Code != nil && b.Code.Language == "go" && len(b.Text) == 1 },
+		},
+		{
+			name:  "equation",
+			json:  `{"type":"equation","equation":{"expression":"e=mc^2"}}`,
+			check: func(b *notion.Block) bool { return b.Equation != nil && b.Equation.Expression == "e=mc^2" },
+		},
+		{
+			name:  "divider",
+			json:  `{"type":"divider","divider":{}}`,
+			check: func(b *notion.Block) bool { return b.Type == notion.BlockTypeEnumDivider },
+		},
+		{
+			name:  "table_of_contents",
+			json:  `{"type":"table_of_contents","table_of_contents":{}}`,
+			check: func(b *notion.Block) bool { return b.Type == notion.BlockTypeEnumTableOfContents },
+		},
+		{
+			name:  "breadcrumb",
+			json:  `{"type":"breadcrumb","breadcrumb":{}}`,
+			check: func(b *notion.Block) bool { return b.Type == notion.BlockTypeEnumBreadcrumb },
+		},
+		{
+			name:  "column_list",
+			json:  `{"type":"column_list","column_list":{}}`,
+			check: func(b *notion.Block) bool { return b.Type == notion.BlockTypeEnumColumnList },
+		},
+		{
+			name:  "column",
+			json:  `{"type":"column","column":{}}`,
+			check: func(b *notion.Block) bool { return b.Type == notion.BlockTypeEnumColumn },
+		},
+		{
+			name: "link_preview",
+			json: `{"type":"link_preview","link_preview":{"url":"https://github.com/thedadams/gotion"}}`,
+			check: func(b *notion.Block) bool {
+				return b.LinkPreview != nil && b.LinkPreview.URL == "https://github.com/thedadams/gotion"
+			},
+		},
+		{
+			name:  "link_to_page",
+			json:  `{"type":"link_to_page","link_to_page":{"type":"page_id","page_id":"` + otherID + `"}}`,
+			check: func(b *notion.Block) bool { return b.LinkToPage != nil && b.LinkToPage.ID == otherID },
+		},
+		{
+			name:  "original synced_block",
+			json:  `{"type":"synced_block","synced_block":{"synced_from":null}}`,
+			check: func(b *notion.Block) bool { return b.SyncedBlock != nil && b.SyncedBlock.SyncedFrom == nil },
+		},
+		{
+			name: "copied synced_block",
+			json: `{"type":"synced_block","synced_block":{"synced_from":{"type":"block_id","block_id":"` + otherID + `"}}}`,
+			check: func(b *notion.Block) bool {
+				return b.SyncedBlock != nil && b.SyncedBlock.SyncedFrom != nil && b.SyncedBlock.SyncedFrom.BlockID.String() == otherID
+			},
+		},
+		{
+			name:  "template",
+			json:  `{"type":"template","template":{` + blockText + `}}`,
+			check: func(b *notion.Block) bool { return len(b.Text) == 1 },
+		},
+		{
+			name: "table",
+			json: `{"type":"table","table":{"table_width":2,"has_column_header":true,"has_row_header":false}}`,
+			check: func(b *notion.Block) bool {
+				return b.Table != nil && b.Table.TableWidth == 2 && b.Table.HasColumnHeader
+			},
+		},
+		{
+			name: "table_row",
+			json: `{"type":"table_row","table_row":{"cells":[[` + blockText[8:] + `,[]]}}`,
+			check: func(b *notion.Block) bool {
+				return b.TableRow != nil && len(b.TableRow.Cells) == 2 && len(b.TableRow.Cells[0]) == 1
+			},
+		},
+		{
+			name:  "unsupported",
+			json:  `{"type":"unsupported","unsupported":{}}`,
+			check: func(b *notion.Block) bool { return b.Type == notion.BlockTypeEnumUnsupported },
+		},
+		{
+			name:  "unknown type",
+			json:  `{"type":"ai_block","ai_block":{"prompt":"Summarize","text":[]}}`,
+			check: func(b *notion.Block) bool { return b.Type == "ai_block" && b.Raw != nil },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := `{` + blockFields + `,` + tt.json[1:]
+			b := new(notion.Block)
+			if err := json.Unmarshal([]byte(data), b); err != nil {
+				t.Fatal(err)
+			}
+			if !tt.check(b) {
+				t.Errorf("got block %+v, which is missing fields of %s", b, data)
+			}
+
+			got, err := json.Marshal(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !jsonEqual(t, got, []byte(data)) {
+				t.Errorf("got %s, want %s", got, data)
+			}
+		})
+	}
+}
+
+func jsonEqual(t *testing.T, a, b []byte) bool {
+	t.Helper()
+	var av, bv interface{}
+	if err := json.Unmarshal(a, &av); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(b, &bv); err != nil {
+		t.Fatal(err)
+	}
+	return reflect.DeepEqual(av, bv)
+}
@@ -11,7 +11,7 @@ const (
 	DatabasePropertyTypeEnumMultiSelect    = "multi_select"
 	DatabasePropertyTypeEnumDate           = "date"
 	DatabasePropertyTypeEnumPeople         = "people"
-	DatabasePropertyTypeEnumFile           = "file"
+	DatabasePropertyTypeEnumFile           = "files"
 	DatabasePropertyTypeEnumCheckbox       = "checkbox"
 	DatabasePropertyTypeEnumURL            = "url"
 	DatabasePropertyTypeEnumEmail          = "email"
@@ -21,6 +21,7 @@ const (
 	DatabasePropertyTypeEnumRollup         = "rollup"
 	DatabasePropertyTypeEnumCreatedTime    = "created_time"
 	DatabasePropertyTypeEnumLastEditedTime = "last_edited_time"
+	DatabasePropertyTypeEnumCreatedBy      = "created_by"
 	DatabasePropertyTypeEnumLastEditedBy   = "last_edited_by"
 
 	SelectColorEnumDefault = "default"
@@ -91,11 +92,24 @@ func (dbte *DatabasePropertyTypeEnum) IsValidEnum() bool {
 		DatabasePropertyTypeEnumRollup,
 		DatabasePropertyTypeEnumCreatedTime,
 		DatabasePropertyTypeEnumLastEditedTime,
+		DatabasePropertyTypeEnumCreatedBy,
 		DatabasePropertyTypeEnumLastEditedBy,
 	)
 }
 
-// UnmarshalJSON verifies that the string is a valid DatabasePropertyTypeEnum for the Notion API.
+// IsReadOnly returns true if the property type is computed by the Notion API, so that its values cannot be set.
+func (dbte *DatabasePropertyTypeEnum) IsReadOnly() bool {
+	return dbte != nil && isValidEnum(string(*dbte),
+		DatabasePropertyTypeEnumFormula,
+		DatabasePropertyTypeEnumRollup,
+		DatabasePropertyTypeEnumCreatedTime,
+		DatabasePropertyTypeEnumLastEditedTime,
+		DatabasePropertyTypeEnumCreatedBy,
+		DatabasePropertyTypeEnumLastEditedBy,
+	)
+}
+
+// UnmarshalJSON sets the DatabasePropertyTypeEnum to the string, even if it is not valid in the Notion API. Use IsValidEnum to check it.
 func (dbte *DatabasePropertyTypeEnum) UnmarshalJSON(b []byte) error {
 	return unmarshalEnum(b, dbte)
 }
@@ -125,7 +139,7 @@ func (sce *SelectColorEnum) IsValidEnum() bool {
 	)
 }
 
-// UnmarshalJSON verifies that the string is a valid DatabasePropertyTypeEnum for the Notion API.
+// UnmarshalJSON sets the SelectColorEnum to the string, even if it is not valid in the Notion API. Use IsValidEnum to check it.
 func (sce *SelectColorEnum) UnmarshalJSON(b []byte) error {
 	return unmarshalEnum(b, sce)
 }
@@ -159,7 +173,7 @@ func (rfet *RollupFunctionEnumType) IsValidEnum() bool {
 	)
 }
 
-// UnmarshalJSON verifies that the string is a valid RollupFunctionEnumType for the Notion API.
+// UnmarshalJSON sets the RollupFunctionEnumType to the string, even if it is not valid in the Notion API. Use IsValidEnum to check it.
 func (rfet *RollupFunctionEnumType) UnmarshalJSON(b []byte) error {
 	return unmarshalEnum(b, rfet)
 }
@@ -190,7 +204,7 @@ func (ncte *NumberConfigurationTypeEnum) IsValidEnum() bool {
 	)
 }
 
-// UnmarshalJSON verifies that the string is a valid NumberConfigurationTypeEnum  in the Notion API
+// UnmarshalJSON sets the NumberConfigurationTypeEnum to the string, even if it is not valid in the Notion API. Use IsValidEnum to check it.
 func (ncte *NumberConfigurationTypeEnum) UnmarshalJSON(b []byte) error {
 	return unmarshalEnum(b, ncte)
 }
@@ -198,8 +212,8 @@ func (ncte *NumberConfigurationTypeEnum) UnmarshalJSON(b []byte) error {
 // SelectOption represents the (multi)select options for database properties in the Notion API.
 type SelectOption struct {
 	Name  string          `json:"name"`
-	ID    string          `json:"id"`
-	Color SelectColorEnum `json:"color"`
+	ID    string          `json:"id,omitempty"`
+	Color SelectColorEnum `json:"color,omitempty"`
 }
 
 // Relation represents a database relation in the Notion API.
@@ -276,6 +290,9 @@ type DatabaseProperty struct {
 	FormulaExpression *string                      `json:"expression,omitempty"`
 	Relation          *Relation                    `json:"relation,omitempty"`
 	Rollup            *Rollup                      `json:"rollup_configuration,omitempty"`
+
+	// Raw holds the JSON of a property with a type that is not known to this package, so that it is marshaled unchanged.
+	Raw json.RawMessage `json:"-"`
 }
 
 type databaseProperty DatabaseProperty
@@ -307,6 +324,9 @@ func (dp *DatabaseProperty) UnmarshalJSON(bt []byte) error {
 	if err := unmarshalJSONFlattenByType(bt, d); err != nil {
 		return err
 	}
+	if !d.Type.IsValidEnum() {
+		d.Raw = append(json.RawMessage(nil), bt...)
+	}
 
 	*dp = DatabaseProperty(*d)
 	return nil
@@ -317,6 +337,9 @@ func (dp *DatabaseProperty) MarshalJSON() ([]byte, error) {
 	if dp == nil {
 		return nil, nil
 	}
+	if dp.Raw != nil && !dp.Type.IsValidEnum() {
+		return dp.Raw, nil
+	}
 	d := databaseProperty(*dp)
 	return marshalJSONExpandByType(&d)
 }
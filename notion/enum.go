@@ -3,9 +3,12 @@ package notion
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
 )
 
 // StringEnum interface are the methods needed to unmarshal string enums.
+// Enums unmarshal any string, so that values that are new in the Notion API don't make unmarshaling fail.
+// Use IsValidEnum, or ValidateEnums for a whole object, to find values that are not known to this package.
 type StringEnum interface {
 	SetValue(v string)
 	IsValidEnum() bool
@@ -14,10 +17,15 @@ type StringEnum interface {
 // InvalidEnumError represents an invalid enum value for a given enum
 type InvalidEnumError struct {
 	Enum, InvalidValue string
+	// Path is the location of the value in the object it was found in, like "Properties[2].Type", if it is known.
+	Path string
 }
 
 // Error turns an InvalidEnumError into an error
 func (i *InvalidEnumError) Error() string {
+	if i.Path != "" {
+		return fmt.Sprintf("%s is not a valid value for an enum %s at %s", i.InvalidValue, i.Enum, i.Path)
+	}
 	return fmt.Sprintf("%s is not a valid value for an enum %s", i.InvalidValue, i.Enum)
 }
 
@@ -39,6 +47,8 @@ func isValidEnum(s string, validValues ...string) bool {
 	return false
 }
 
+// unmarshalEnum sets the enum to the unmarshaled string. Values that are not valid are kept as they are,
+// so that new values in the Notion API don't break unmarshaling. Use ValidateEnums to find them.
 func unmarshalEnum(b []byte, e StringEnum) error {
 	var s string
 	if err := json.Unmarshal(b, &s); err != nil {
@@ -46,9 +56,61 @@ func unmarshalEnum(b []byte, e StringEnum) error {
 	}
 
 	e.SetValue(s)
-	if !e.IsValidEnum() {
-		return fmt.Errorf("%s is not a valid %T", s, e)
-	}
-
 	return nil
 }
+
+var stringEnumType = reflect.TypeOf((*StringEnum)(nil)).Elem()
+
+// ValidateEnums returns an error for every enum in v, and in the values it refers to, that is set to a value
+// that is not valid in the Notion API. Empty enums are not reported, since they are left out by the Notion API.
+func ValidateEnums(v interface{}) []*InvalidEnumError {
+	var errs []*InvalidEnumError
+	validateEnums(reflect.ValueOf(v), "", &errs)
+	return errs
+}
+
+func validateEnums(v reflect.Value, path string, errs *[]*InvalidEnumError) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			validateEnums(v.Elem(), path, errs)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			if f := t.Field(i); f.PkgPath == "" {
+				fieldPath := f.Name
+				if path != "" && !f.Anonymous {
+					fieldPath = path + "." + f.Name
+				} else if f.Anonymous {
+					fieldPath = path
+				}
+				validateEnums(v.Field(i), fieldPath, errs)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			validateEnums(v.Index(i), fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			validateEnums(iter.Value(), fmt.Sprintf("%s[%v]", path, iter.Key()), errs)
+		}
+	case reflect.String:
+		if v.Len() == 0 || !reflect.PtrTo(v.Type()).Implements(stringEnumType) {
+			return
+		}
+		// Copy the value so that the check works for values that are not addressable, like those in maps.
+		e := reflect.New(v.Type())
+		e.Elem().SetString(v.String())
+		if !e.Interface().(StringEnum).IsValidEnum() {
+			err := NewInvalidEnumError(v.Type().Name(), v.String())
+			err.Path = path
+			*errs = append(*errs, err)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package notion_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/thedadams/gotion/notion"
+)
+
+func TestUnmarshalUnknownEnum(t *testing.T) {
+	var rt notion.RichText
+	data := `{"type":"text","text":{"content":"hi"},"plain_text":"hi","annotations":{"color":"teal"}}`
+	if err := json.Unmarshal([]byte(data), &rt); err != nil {
+		t.Fatalf("got error %v, want unknown enum values to be kept", err)
+	}
+	if rt.Annotations.Color != "teal" || rt.Annotations.Color.IsValidEnum() {
+		t.Errorf("got color %q, want the unknown value kept", rt.Annotations.Color)
+	}
+
+	errs := notion.ValidateEnums(&rt)
+	if len(errs) != 1 || errs[0].InvalidValue != "teal" || errs[0].Path != "Annotations.Color" {
+		t.Errorf("got %v, want an error for the color", errs)
+	}
+}
+
+func TestIsReadOnly(t *testing.T) {
+	for _, tt := range []struct {
+		t    notion.DatabasePropertyTypeEnum
+		want bool
+	}{
+		{t: notion.DatabasePropertyTypeEnumFormula, want: true},
+		{t: notion.DatabasePropertyTypeEnumLastEditedBy, want: true},
+		{t: notion.DatabasePropertyTypeEnumTitle},
+		{t: notion.DatabasePropertyTypeEnumRelation},
+		{t: "unknown"},
+	} {
+		if got := tt.t.IsReadOnly(); got != tt.want {
+			t.Errorf("got IsReadOnly %v for %s, want %v", got, tt.t, tt.want)
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package notion
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 // These constants represent the error codes one can get from the Notion API.
 const (
@@ -17,22 +21,63 @@ const (
 	ErrorCodeServiceUnavailable = "service_unavailable"
 )
 
-// APIError is an error from the Notion API
+// ErrorCode is an error code from the Notion API. Each code has a sentinel error value below, so that
+// errors returned by the gotion client can be checked with errors.Is, for example errors.Is(err, notion.ErrObjectNotFound).
+type ErrorCode string
+
+// Error implements the error interface for ErrorCode
+func (e ErrorCode) Error() string {
+	return fmt.Sprintf("Notion API Error: %s", string(e))
+}
+
+// These are the sentinel errors for the error codes one can get from the Notion API.
+var (
+	ErrInvalidJSON        error = ErrorCode(ErrorCodeInvalidJSON)
+	ErrInvalidRequestURL  error = ErrorCode(ErrorCodeInvalidRequestURL)
+	ErrInvalidRequest     error = ErrorCode(ErrorCodeInvalidRequest)
+	ErrValidation         error = ErrorCode(ErrorCodeValidationError)
+	ErrUnauthorized       error = ErrorCode(ErrorCodeUnauthorized)
+	ErrRestrictedResource error = ErrorCode(ErrorCodeRestrictedResource)
+	ErrObjectNotFound     error = ErrorCode(ErrorCodeObjectNotFound)
+	ErrConflict           error = ErrorCode(ErrorCodeConflict)
+	ErrRateLimited        error = ErrorCode(ErrorCodeRateLimited)
+	ErrInternalError      error = ErrorCode(ErrorCodeInternalError)
+	ErrServiceUnavailable error = ErrorCode(ErrorCodeServiceUnavailable)
+)
+
+// APIError is an error from the Notion API.
+// Errors returned by the gotion client can be unwrapped to an *APIError with errors.As.
 type APIError struct {
-	Status  int    `json:"status"`
-	Code    string `json:"code"`
-	Message string `json:"message"`
+	// Status is the HTTP status code of the response.
+	Status    int    `json:"status"`
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id,omitempty"`
+	// Method and URL describe the request that caused the error.
+	Method string `json:"-"`
+	URL    string `json:"-"`
+	// RetryAfter is the time to wait before retrying, from the Retry-After header. It is zero if the header was not set.
+	RetryAfter time.Duration `json:"-"`
 }
 
 // Error implements the error interface for APIError
-func (a APIError) Error() string {
-	return fmt.Sprintf("Notion API Error: %s - %s", a.Code, a.Message)
+func (a *APIError) Error() string {
+	if a.Method == "" {
+		return fmt.Sprintf("Notion API Error: %s - %s", a.Code, a.Message)
+	}
+	return fmt.Sprintf("%s request to %s with status %d: Notion API Error: %s - %s", a.Method, a.URL, a.Status, a.Code, a.Message)
+}
+
+// Is returns true if the target is the ErrorCode of the APIError, so that errors.Is can be used with the sentinel errors.
+func (a *APIError) Is(target error) bool {
+	code, ok := target.(ErrorCode)
+	return ok && a.Code == string(code)
 }
 
-// IsAPIErrorWithCode returns true if the error is a Notion APIError and has the given code
+// IsAPIErrorWithCode returns true if the error is, or wraps, a Notion APIError and has the given code
 func IsAPIErrorWithCode(err error, code string) bool {
-	apiErr, ok := err.(APIError)
-	return ok && apiErr.Code == code
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Code == code
 }
 
 // IsNotFound returns true if the error is an APIError and has the "object_not_found" code
@@ -2,6 +2,7 @@ package notion
 
 import (
 	"encoding/json"
+	"fmt"
 	"time"
 )
 
@@ -108,10 +109,11 @@ func (fcte *FilterConditionTypeEnum) IsValidEnum() bool {
 		FilterConditionTypeEnumFiles,
 		FilterConditionTypeEnumRelation,
 		FilterConditionTypeEnumFormula,
+		FilterConditionTypeEnumObject,
 	)
 }
 
-// UnmarshalJSON returns an error if the string is not a valid FilterConditionTypeEnum in the Notion API.
+// UnmarshalJSON sets the FilterConditionTypeEnum to the string, even if it is not valid in the Notion API. Use IsValidEnum to check it.
 func (fcte *FilterConditionTypeEnum) UnmarshalJSON(b []byte) error {
 	return unmarshalEnum(b, fcte)
 }
@@ -139,7 +141,7 @@ func (ftce *FilterTextConditionEnum) IsValidEnum() bool {
 	)
 }
 
-// UnmarshalJSON returns an error if the string is not a valid FilterTextConditionEnum in the Notion API.
+// UnmarshalJSON sets the FilterTextConditionEnum to the string, even if it is not valid in the Notion API. Use IsValidEnum to check it.
 func (ftce *FilterTextConditionEnum) UnmarshalJSON(b []byte) error {
 	return unmarshalEnum(b, ftce)
 }
@@ -167,7 +169,7 @@ func (fnce *FilterNumberConditionEnum) IsValidEnum() bool {
 	)
 }
 
-// UnmarshalJSON returns an error if the string is not a valid FilterNumberConditionEnum in the Notion API.
+// UnmarshalJSON sets the FilterNumberConditionEnum to the string, even if it is not valid in the Notion API. Use IsValidEnum to check it.
 func (fnce *FilterNumberConditionEnum) UnmarshalJSON(b []byte) error {
 	return unmarshalEnum(b, fnce)
 }
@@ -187,7 +189,7 @@ func (fcce *FilterCheckboxConditionEnum) IsValidEnum() bool {
 	return fcce != nil && isValidEnum(string(*fcce), FilterCheckboxConditionEnumEquals, FilterCheckboxConditionEnumDoesNotEqual)
 }
 
-// UnmarshalJSON returns an error if the string is not a valid FilterCheckboxConditionEnum in the Notion API.
+// UnmarshalJSON sets the FilterCheckboxConditionEnum to the string, even if it is not valid in the Notion API. Use IsValidEnum to check it.
 func (fcce *FilterCheckboxConditionEnum) UnmarshalJSON(b []byte) error {
 	return unmarshalEnum(b, fcce)
 }
@@ -211,7 +213,7 @@ func (fsce *FilterSelectConditionEnum) IsValidEnum() bool {
 	)
 }
 
-// UnmarshalJSON returns an error if the string is not a valid FilterSelectConditionEnum in the Notion API.
+// UnmarshalJSON sets the FilterSelectConditionEnum to the string, even if it is not valid in the Notion API. Use IsValidEnum to check it.
 func (fsce *FilterSelectConditionEnum) UnmarshalJSON(b []byte) error {
 	return unmarshalEnum(b, fsce)
 }
@@ -235,7 +237,7 @@ func (fmce *FilterMultiSelectConditionEnum) IsValidEnum() bool {
 	)
 }
 
-// UnmarshalJSON returns an error if the string is not a valid FilterMultiSelectConditionEnum in the Notion API.
+// UnmarshalJSON sets the FilterMultiSelectConditionEnum to the string, even if it is not valid in the Notion API. Use IsValidEnum to check it.
 func (fmce *FilterMultiSelectConditionEnum) UnmarshalJSON(b []byte) error {
 	return unmarshalEnum(b, fmce)
 }
@@ -268,7 +270,7 @@ func (fdce *FilterDateConditionEnum) IsValidEnum() bool {
 	)
 }
 
-// UnmarshalJSON returns an error if the string is not a valid FilterDateConditionEnum in the Notion API.
+// UnmarshalJSON sets the FilterDateConditionEnum to the string, even if it is not valid in the Notion API. Use IsValidEnum to check it.
 func (fdce *FilterDateConditionEnum) UnmarshalJSON(b []byte) error {
 	return unmarshalEnum(b, fdce)
 }
@@ -292,7 +294,7 @@ func (fpce *FilterPeopleConditionEnum) IsValidEnum() bool {
 	)
 }
 
-// UnmarshalJSON returns an error if the string is not a valid FilterPeopleConditionEnum in the Notion API.
+// UnmarshalJSON sets the FilterPeopleConditionEnum to the string, even if it is not valid in the Notion API. Use IsValidEnum to check it.
 func (fpce *FilterPeopleConditionEnum) UnmarshalJSON(b []byte) error {
 	return unmarshalEnum(b, fpce)
 }
@@ -309,14 +311,12 @@ func (ffce *FilterFilesConditionEnum) SetValue(s string) {
 
 // IsValidEnum returns true if the string represents a valid FilterFilesConditionEnum in the Notion API.
 func (ffce *FilterFilesConditionEnum) IsValidEnum() bool {
-	return ffce != nil && isValidEnum(string(*ffce), FilterFormulaConditionEnumText,
-		FilterFormulaConditionEnumCheckbox,
-		FilterFormulaConditionEnumNumber,
-		FilterFormulaConditionEnumDate,
+	return ffce != nil && isValidEnum(string(*ffce), FilterFilesConditionEnumIsEmpty,
+		FilterFilesConditionEnumIsNotEmpty,
 	)
 }
 
-// UnmarshalJSON returns an error if the string is not a valid FilterFilesConditionEnum in the Notion API.
+// UnmarshalJSON sets the FilterFilesConditionEnum to the string, even if it is not valid in the Notion API. Use IsValidEnum to check it.
 func (ffce *FilterFilesConditionEnum) UnmarshalJSON(b []byte) error {
 	return unmarshalEnum(b, ffce)
 }
@@ -340,7 +340,7 @@ func (frce *FilterRelationConditionEnum) IsValidEnum() bool {
 	)
 }
 
-// UnmarshalJSON returns an error if the string is not a valid FilterRelationConditionEnum in the Notion API.
+// UnmarshalJSON sets the FilterRelationConditionEnum to the string, even if it is not valid in the Notion API. Use IsValidEnum to check it.
 func (frce *FilterRelationConditionEnum) UnmarshalJSON(b []byte) error {
 	return unmarshalEnum(b, frce)
 }
@@ -357,12 +357,14 @@ func (ffce *FilterFormulaConditionEnum) SetValue(s string) {
 
 // IsValidEnum returns true if the string represents a valid FilterFormulaConditionEnum in the Notion API.
 func (ffce *FilterFormulaConditionEnum) IsValidEnum() bool {
-	return ffce != nil && isValidEnum(string(*ffce), FilterFilesConditionEnumIsEmpty,
-		FilterFilesConditionEnumIsNotEmpty,
+	return ffce != nil && isValidEnum(string(*ffce), FilterFormulaConditionEnumText,
+		FilterFormulaConditionEnumCheckbox,
+		FilterFormulaConditionEnumNumber,
+		FilterFormulaConditionEnumDate,
 	)
 }
 
-// UnmarshalJSON returns an error if the string is not a valid FilterFormulaConditionEnum in the Notion API.
+// UnmarshalJSON sets the FilterFormulaConditionEnum to the string, even if it is not valid in the Notion API. Use IsValidEnum to check it.
 func (ffce *FilterFormulaConditionEnum) UnmarshalJSON(b []byte) error {
 	return unmarshalEnum(b, ffce)
 }
@@ -384,7 +386,7 @@ func (ofce *FilterObjectConditionEnum) IsValidEnum() bool {
 	)
 }
 
-// UnmarshalJSON returns an error if the string is not a valid FilterObjectConditionEnum in the Notion API.
+// UnmarshalJSON sets the FilterObjectConditionEnum to the string, even if it is not valid in the Notion API. Use IsValidEnum to check it.
 func (ofce *FilterObjectConditionEnum) UnmarshalJSON(b []byte) error {
 	return unmarshalEnum(b, ofce)
 }
@@ -396,13 +398,47 @@ type EmptyFilter struct {
 }
 
 func (e *EmptyFilter) getValue() interface{} {
-	return e != nil && e.IsEmpty && e.IsNotEmpty
+	return e != nil && (e.IsEmpty || e.IsNotEmpty)
+}
+
+// emptyCondition returns the condition set by the EmptyFilter, if any.
+func (e *EmptyFilter) emptyCondition() string {
+	switch {
+	case e == nil:
+		return ""
+	case e.IsEmpty:
+		return FilterTextConditionEnumIsEmpty
+	case e.IsNotEmpty:
+		return FilterTextConditionEnumIsNotEmpty
+	}
+	return ""
+}
+
+// A QueryFilter represents a filter with which to query a database in the Notion API.
+// It is either a single *Filter or a *CompoundFilter, which can be nested, or a RawFilter.
+type QueryFilter interface {
+	isQueryFilter()
+}
+
+// A RawFilter is the JSON of a filter object in the Notion API, which is sent as is.
+// It is useful for filters that are read from a file or the command line.
+type RawFilter json.RawMessage
+
+func (RawFilter) isQueryFilter() {}
+
+// MarshalJSON returns the JSON of the filter, after checking that it is valid JSON.
+func (rf RawFilter) MarshalJSON() ([]byte, error) {
+	if !json.Valid(rf) {
+		return nil, fmt.Errorf("invalid JSON filter: %q", string(rf))
+	}
+	return rf, nil
 }
 
 // A Filter represents a filter object with which to query a database in the Notion API.
+// Only the condition for the Type of the filter is sent to the Notion API.
 type Filter struct {
 	// All that is required for the "property" field is the name or id of the property,
-	// but allowing to pass the whole property is more flexible. Just requires custom (un)marshaling.
+	// but allowing to pass the whole property is more flexible. The name is used if it is set.
 	Property    DatabaseProperty
 	Type        FilterConditionTypeEnum `json:"type"`
 	Text        *TextFilter             `json:"text,omitempty"`
@@ -415,30 +451,66 @@ type Filter struct {
 	Files       *FilesFilter            `json:"files,omitempty"`
 	Relation    *RelationFilter         `json:"relation,omitempty"`
 	Formula     *FormulaFilter          `json:"formula,omitempty"`
+	// Object is only used when searching the Notion API, to filter on the type of object.
+	Object *ObjectFilter `json:"object,omitempty"`
 }
 
-type filter Filter
+func (*Filter) isQueryFilter() {}
 
-// GetType returns the Type of a text filter.
-func (f *filter) getType() string {
-	if f == nil {
-		return ""
-	}
-	return string(f.Type)
+// And returns a CompoundFilter that matches when this filter and all the given filters match.
+func (f *Filter) And(filters ...QueryFilter) *CompoundFilter {
+	return &CompoundFilter{And: append([]QueryFilter{f}, filters...)}
+}
+
+// Or returns a CompoundFilter that matches when this filter or any of the given filters match.
+func (f *Filter) Or(filters ...QueryFilter) *CompoundFilter {
+	return &CompoundFilter{Or: append([]QueryFilter{f}, filters...)}
 }
 
-// FieldsToExpand implements the expander interface for filter.
-func (f *filter) fieldsToExpand() []string {
-	return []string{}
+// condition returns the condition of the filter for its Type.
+func (f *Filter) condition() interface{} {
+	switch f.Type {
+	case FilterConditionTypeEnumText:
+		return f.Text
+	case FilterConditionTypeEnumNumber:
+		return f.Number
+	case FilterConditionTypeEnumCheckbox:
+		return f.Checkbox
+	case FilterConditionTypeEnumSelect:
+		return f.Select
+	case FilterConditionTypeEnumMultiSelect:
+		return f.MultiSelect
+	case FilterConditionTypeEnumDate:
+		return f.Date
+	case FilterConditionTypeEnumPeople:
+		return f.People
+	case FilterConditionTypeEnumFiles:
+		return f.Files
+	case FilterConditionTypeEnumRelation:
+		return f.Relation
+	case FilterConditionTypeEnumFormula:
+		return f.Formula
+	}
+	return nil
 }
 
 // MarshalJSON prepares the filter to be compatible with the Notion API.
 func (f *Filter) MarshalJSON() ([]byte, error) {
 	if f == nil {
-		return nil, nil
+		return []byte("null"), nil
 	}
-	ff := filter(*f)
-	return marshalJSONExpandByType(&ff)
+	if f.Type == FilterConditionTypeEnumObject {
+		return json.Marshal(f.Object)
+	}
+	if !f.Type.IsValidEnum() {
+		return nil, NewInvalidEnumError("FilterConditionTypeEnum", string(f.Type))
+	}
+
+	property := f.Property.Name
+	if property == "" {
+		property = f.Property.ID
+	}
+	return json.Marshal(map[string]interface{}{"property": property, string(f.Type): f.condition()})
 }
 
 // A TextFilter represents a filter object with which to query a database in the Notion API.
@@ -625,6 +697,13 @@ func (df *DateFilter) getValue() interface{} {
 		return true
 	}
 
+	switch df.Type {
+	case FilterDateConditionEnumPastWeek, FilterDateConditionEnumPastMonth, FilterDateConditionEnumPastYear,
+		FilterDateConditionEnumNextWeek, FilterDateConditionEnumNextMonth, FilterDateConditionEnumNextYear:
+		// The relative date conditions take an empty object in the Notion API.
+		return struct{}{}
+	}
+
 	return df.Value
 }
 
@@ -715,31 +794,17 @@ func (rf *RelationFilter) MarshalJSON() ([]byte, error) {
 	return marshalJSONFilter(rf)
 }
 
-// A ObjectFilter represents a filter object with which to query a database in the Notion API.
+// A ObjectFilter represents a filter object with which to search the Notion API.
 type ObjectFilter struct {
-	Type  FilterObjectConditionEnum
-	Value *string
-}
-
-// GetType returns the Type of an object filter.
-func (of *ObjectFilter) getType() string {
-	if of == nil {
-		return ""
-	}
-	return string(of.Type)
-}
-
-func (of *ObjectFilter) getValue() interface{} {
-	if of == nil {
-		return nil
-	}
-
-	return of.Value
+	Type FilterObjectConditionEnum
 }
 
 // MarshalJSON prepares the filter to be compatible with the Notion API.
 func (of *ObjectFilter) MarshalJSON() ([]byte, error) {
-	return marshalJSONFilter(of)
+	if !of.Type.IsValidEnum() {
+		return nil, NewInvalidEnumError("FilterObjectConditionEnum", string(of.Type))
+	}
+	return json.Marshal(map[string]interface{}{"property": FilterConditionTypeEnumObject, "value": of.Type})
 }
 
 // A FormulaFilter represents a filter object with which to query a database in the Notion API.
@@ -752,17 +817,33 @@ type FormulaFilter struct {
 }
 
 // A CompoundFilter represents a compound filter object with which to query a database in the Notion API.
-// TODO: nested compound filters are not yet implemented.
+// Only one of Or and And should be set. Compound filters can be nested to express filters like (A and B) or C.
+// The filter package provides a builder for nested filters.
 type CompoundFilter struct {
-	Or  []*Filter `json:"or,omitempty"`
-	And []*Filter `json:"and,omitempty"`
+	Or  []QueryFilter `json:"or,omitempty"`
+	And []QueryFilter `json:"and,omitempty"`
 }
 
+func (*CompoundFilter) isQueryFilter() {}
+
 type valued interface {
 	typed
 	getValue() interface{}
 }
 
+// emptiable is implemented by the filters that embed an EmptyFilter.
+type emptiable interface {
+	emptyCondition() string
+}
+
 func marshalJSONFilter(v valued) ([]byte, error) {
-	return json.Marshal(map[string]interface{}{v.getType(): v.getValue()})
+	condition, value := v.getType(), v.getValue()
+	if e, ok := v.(emptiable); ok && condition == "" {
+		condition = e.emptyCondition()
+	}
+	if condition == FilterTextConditionEnumIsEmpty || condition == FilterTextConditionEnumIsNotEmpty {
+		// The empty conditions only accept true in the Notion API.
+		value = true
+	}
+	return json.Marshal(map[string]interface{}{condition: value})
 }
@@ -0,0 +1,119 @@
+package notion_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/thedadams/gotion/notion"
+)
+
+func TestFilterJSON(t *testing.T) {
+	done, due := true, 3.5
+	name := "Launch"
+	status := &notion.Filter{
+		Property: notion.DatabaseProperty{Name: "Done"},
+		Type:     notion.FilterConditionTypeEnumCheckbox,
+		Checkbox: &notion.CheckboxFilter{Type: notion.FilterCheckboxConditionEnumEquals, Value: &done},
+	}
+
+	tests := []struct {
+		name   string
+		filter notion.QueryFilter
+		want   string
+	}{
+		{
+			name: "text",
+			filter: &notion.Filter{
+				Property: notion.DatabaseProperty{Name: "Name"},
+				Type:     notion.FilterConditionTypeEnumText,
+				Text:     &notion.TextFilter{Type: notion.FilterTextConditionEnumContains, Value: &name},
+			},
+			want: `{"property":"Name","text":{"contains":"Launch"}}`,
+		},
+		{
+			name: "property id",
+			filter: &notion.Filter{
+				Property: notion.DatabaseProperty{ID: "a%3Bc"},
+				Type:     notion.FilterConditionTypeEnumNumber,
+				Number:   &notion.NumberFilter{Type: notion.FilterNumberConditionEnumGreaterThan, Value: &due},
+			},
+			want: `{"number":{"greater_than":3.5},"property":"a%3Bc"}`,
+		},
+		{
+			name: "empty",
+			filter: &notion.Filter{
+				Property: notion.DatabaseProperty{Name: "Points"},
+				Type:     notion.FilterConditionTypeEnumNumber,
+				Number:   &notion.NumberFilter{EmptyFilter: notion.EmptyFilter{IsEmpty: true}},
+			},
+			want: `{"number":{"is_empty":true},"property":"Points"}`,
+		},
+		{
+			name: "date",
+			filter: &notion.Filter{
+				Property: notion.DatabaseProperty{Name: "Due"},
+				Type:     notion.FilterConditionTypeEnumDate,
+				Date:     &notion.DateFilter{Type: notion.FilterDateConditionEnumBefore, Value: time.Date(2021, 5, 10, 0, 0, 0, 0, time.UTC)},
+			},
+			want: `{"date":{"before":"2021-05-10T00:00:00Z"},"property":"Due"}`,
+		},
+		{
+			name: "relative date",
+			filter: &notion.Filter{
+				Property: notion.DatabaseProperty{Name: "Due"},
+				Type:     notion.FilterConditionTypeEnumDate,
+				Date:     &notion.DateFilter{Type: notion.FilterDateConditionEnumPastWeek},
+			},
+			want: `{"date":{"past_week":{}},"property":"Due"}`,
+		},
+		{
+			name: "compound",
+			filter: status.And(&notion.CompoundFilter{Or: []notion.QueryFilter{
+				&notion.Filter{
+					Property: notion.DatabaseProperty{Name: "Name"},
+					Type:     notion.FilterConditionTypeEnumText,
+					Text:     &notion.TextFilter{EmptyFilter: notion.EmptyFilter{IsNotEmpty: true}},
+				},
+				notion.RawFilter(`{"property":"Tags","multi_select":{"contains":"urgent"}}`),
+			}}),
+			want: `{"and":[{"checkbox":{"equals":true},"property":"Done"},` +
+				`{"or":[{"property":"Name","text":{"is_not_empty":true}},{"property":"Tags","multi_select":{"contains":"urgent"}}]}]}`,
+		},
+		{
+			name:   "nil filter",
+			filter: (*notion.Filter)(nil),
+			want:   `null`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := json.Marshal(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != tt.want {
+				t.Errorf("got %s, want %s", b, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterJSONErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter notion.QueryFilter
+	}{
+		{name: "invalid type", filter: &notion.Filter{Property: notion.DatabaseProperty{Name: "Name"}, Type: "color"}},
+		{name: "invalid raw filter", filter: notion.RawFilter(`{"property":`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if b, err := json.Marshal(tt.filter); err == nil {
+				t.Errorf("got %s, want an error", b)
+			}
+		})
+	}
+}
@@ -0,0 +1,705 @@
+package notion
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	timeType         = reflect.TypeOf(time.Time{})
+	dateType         = reflect.TypeOf(Date{})
+	urlType          = reflect.TypeOf(url.URL{})
+	selectOptionType = reflect.TypeOf(SelectOption{})
+	uuidType         = reflect.TypeOf(UUID4{})
+	richTextsType    = reflect.TypeOf([]RichText{})
+	usersType        = reflect.TypeOf([]*User{})
+	filesType        = reflect.TypeOf([]*File{})
+	relationsType    = reflect.TypeOf(Relations{})
+)
+
+// A mappedField is a field of a struct that is mapped to a page property with a "notion" tag.
+type mappedField struct {
+	name      string
+	fieldName string
+	typ       DatabasePropertyTypeEnum
+	omitEmpty bool
+	index     []int
+}
+
+// mappedFields returns the fields of the struct type that are mapped to page properties.
+// Like encoding/json, unexported fields are ignored and the fields of embedded structs are included.
+func mappedFields(t reflect.Type) ([]mappedField, error) {
+	var fields []mappedField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, tagged := sf.Tag.Lookup("notion")
+		if tag == "-" {
+			continue
+		}
+		if sf.Anonymous && !tagged && sf.Type.Kind() == reflect.Struct {
+			embedded, err := mappedFields(sf.Type)
+			if err != nil {
+				return nil, err
+			}
+			for _, f := range embedded {
+				f.index = append([]int{i}, f.index...)
+				fields = append(fields, f)
+			}
+			continue
+		}
+		if sf.PkgPath != "" {
+			continue
+		}
+
+		parts := strings.Split(tag, ",")
+		f := mappedField{name: parts[0], fieldName: sf.Name, index: []int{i}}
+		if f.name == "" {
+			f.name = sf.Name
+		}
+		for _, opt := range parts[1:] {
+			switch opt {
+			case "":
+			case "omitempty":
+				f.omitEmpty = true
+			default:
+				f.typ = DatabasePropertyTypeEnum(opt)
+				if !f.typ.IsValidEnum() {
+					return nil, fmt.Errorf("field %s: %w", sf.Name, NewInvalidEnumError("DatabasePropertyTypeEnum", opt))
+				}
+			}
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// structValue returns the struct that v is or points to.
+func structValue(v interface{}, mustPoint bool) (reflect.Value, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	} else if mustPoint {
+		return reflect.Value{}, fmt.Errorf("a non-nil pointer to a struct is required, got %T", v)
+	}
+	if rv.Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("a struct is required, got %T", v)
+	}
+	return rv, nil
+}
+
+// UnmarshalPage decodes the properties of the page into the struct pointed to by v.
+// Like encoding/json, the page property of each field is given by a "notion" tag with the name and type of the property:
+//
+//	type Task struct {
+//		Name     string         `notion:"Name,title"`
+//		Status   string         `notion:"Status,select"`
+//		Due      *time.Time     `notion:"Due,date,omitempty"`
+//		Assignee []notion.UUID4 `notion:"Assignee,people"`
+//	}
+//
+// A field without a tag uses its name as the property name, and a tag of "-" skips the field.
+// If the type is in the tag, then it must match the type of the page property.
+// Text is decoded into strings or []RichText, selects into strings or SelectOptions, dates into time.Time or Date,
+// and people and relations into []UUID4, []string of ids, or their notion types.
+// A property that is not set is decoded as the zero value, so pointers are nil.
+func UnmarshalPage(p *Page, v interface{}) error {
+	if p == nil {
+		return errors.New("cannot unmarshal a nil page")
+	}
+	rv, err := structValue(v, true)
+	if err != nil {
+		return err
+	}
+	fields, err := mappedFields(rv.Type())
+	if err != nil {
+		return err
+	}
+
+	props := make(map[string]*PageProperty, len(p.Properties))
+	for _, prop := range p.Properties {
+		if prop != nil {
+			props[prop.Name] = prop
+		}
+	}
+
+	for _, f := range fields {
+		prop := props[f.name]
+		if prop == nil {
+			continue
+		}
+		if f.typ != "" && f.typ != prop.Type {
+			return fmt.Errorf("field %s: property %q has type %s, not %s", f.fieldName, f.name, prop.Type, f.typ)
+		}
+		if err := decodeProperty(prop, rv.FieldByIndex(f.index)); err != nil {
+			return fmt.Errorf("field %s: %w", f.fieldName, err)
+		}
+	}
+	return nil
+}
+
+// decodeProperty sets v to the first Go value of the page property that can be assigned to it.
+func decodeProperty(prop *PageProperty, v reflect.Value) error {
+	values := propertyValues(prop)
+	if len(values) == 0 {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	target := v
+	if v.Kind() == reflect.Ptr {
+		target = reflect.New(v.Type().Elem()).Elem()
+	}
+	for _, value := range values {
+		if assign(target, value) {
+			if v.Kind() == reflect.Ptr {
+				v.Set(target.Addr())
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot decode a %s property into a Go value of type %s", prop.Type, v.Type())
+}
+
+// propertyValues returns the value of the page property as Go values, from the most to the least convenient.
+// No values are returned for a property that is not set.
+func propertyValues(prop *PageProperty) []interface{} {
+	switch prop.Type {
+	case DatabasePropertyTypeEnumTitle:
+		return richTextValues(prop.Title)
+	case DatabasePropertyTypeEnumRichText:
+		return richTextValues(prop.RichText)
+	case DatabasePropertyTypeEnumNumber:
+		if prop.Number != nil {
+			return []interface{}{*prop.Number}
+		}
+	case DatabasePropertyTypeEnumSelect:
+		if prop.Select != nil {
+			return []interface{}{prop.Select.Name, *prop.Select}
+		}
+	case DatabasePropertyTypeEnumMultiSelect:
+		if len(prop.MultiSelect) != 0 {
+			names := make([]string, 0, len(prop.MultiSelect))
+			for _, option := range prop.MultiSelect {
+				names = append(names, option.Name)
+			}
+			return []interface{}{names, prop.MultiSelect}
+		}
+	case DatabasePropertyTypeEnumDate:
+		return dateValues(prop.Date)
+	case DatabasePropertyTypeEnumPeople:
+		if len(prop.People) != 0 {
+			ids, strs, users := make([]UUID4, 0, len(prop.People)), make([]string, 0, len(prop.People)), make([]User, 0, len(prop.People))
+			for _, u := range prop.People {
+				if u != nil {
+					ids, strs, users = append(ids, u.ID), append(strs, u.ID.String()), append(users, *u)
+				}
+			}
+			return []interface{}{ids, strs, prop.People, users}
+		}
+	case DatabasePropertyTypeEnumFile:
+		if len(prop.Files) != 0 {
+			urls, files := make([]string, 0, len(prop.Files)), make([]File, 0, len(prop.Files))
+			for _, f := range prop.Files {
+				if u := f.GetURL(); u != nil {
+					urls, files = append(urls, u.String()), append(files, *f)
+				}
+			}
+			return []interface{}{urls, prop.Files, files}
+		}
+	case DatabasePropertyTypeEnumCheckbox:
+		if prop.Checkbox != nil {
+			return []interface{}{*prop.Checkbox}
+		}
+	case DatabasePropertyTypeEnumURL:
+		if u := (*url.URL)(prop.URL); u != nil {
+			return []interface{}{u.String(), *u}
+		}
+	case DatabasePropertyTypeEnumEmail:
+		if prop.Email != nil {
+			return []interface{}{prop.Email.Address}
+		}
+	case DatabasePropertyTypeEnumPhoneNumber:
+		if prop.PhoneNumber != nil {
+			return []interface{}{*prop.PhoneNumber}
+		}
+	case DatabasePropertyTypeEnumFormula:
+		return formulaValues(prop.Formula)
+	case DatabasePropertyTypeEnumRelation:
+		if len(prop.Relations) != 0 {
+			ids, strs := make([]UUID4, 0, len(prop.Relations)), make([]string, 0, len(prop.Relations))
+			for _, rid := range prop.Relations {
+				if rid != nil {
+					id := UUID4(*rid)
+					ids, strs = append(ids, id), append(strs, id.String())
+				}
+			}
+			return []interface{}{ids, strs, prop.Relations}
+		}
+	case DatabasePropertyTypeEnumRollup:
+		return rollupValues(prop.Rollup)
+	case DatabasePropertyTypeEnumCreatedTime:
+		if !prop.CreatedTime.IsZero() {
+			return []interface{}{prop.CreatedTime}
+		}
+	case DatabasePropertyTypeEnumLastEditedTime:
+		if !prop.LastEditedTime.IsZero() {
+			return []interface{}{prop.LastEditedTime}
+		}
+	case DatabasePropertyTypeEnumCreatedBy:
+		return userValues(prop.CreatedBy)
+	case DatabasePropertyTypeEnumLastEditedBy:
+		return userValues(prop.LastEditedBy)
+	}
+	return nil
+}
+
+func richTextValues(rts []RichText) []interface{} {
+	if len(rts) == 0 {
+		return nil
+	}
+	return []interface{}{PlainText(rts), rts}
+}
+
+func dateValues(d *Date) []interface{} {
+	if d == nil || d.Start.IsZero() {
+		return nil
+	}
+	return []interface{}{d.Start, *d}
+}
+
+func userValues(u *User) []interface{} {
+	if u == nil {
+		return nil
+	}
+	return []interface{}{u.ID, u.ID.String(), *u}
+}
+
+func formulaValues(f *Formula) []interface{} {
+	if f == nil {
+		return nil
+	}
+
+	var values []interface{}
+	switch f.Type {
+	case FormulaTypeEnumString:
+		if f.String != nil {
+			values = []interface{}{*f.String}
+		}
+	case FormulaTypeEnumNumber:
+		if f.Number != nil {
+			values = []interface{}{*f.Number}
+		}
+	case FormulaTypeEnumBoolean:
+		if f.Boolean != nil {
+			values = []interface{}{*f.Boolean}
+		}
+	case FormulaTypeEnumDate:
+		values = dateValues(f.Date)
+	}
+	if values == nil {
+		return nil
+	}
+	return append(values, *f)
+}
+
+func rollupValues(r *RollupValue) []interface{} {
+	if r == nil {
+		return nil
+	}
+
+	var values []interface{}
+	switch r.Type {
+	case RollupValueTypeEnumNumber:
+		if r.Number != nil {
+			values = []interface{}{*r.Number}
+		}
+	case RollupValueTypeEnumDate:
+		values = dateValues(r.Date)
+	case RollupValueTypeEnumArray:
+		if len(r.Array) != 0 {
+			values = []interface{}{r.Array}
+		}
+	}
+	if values == nil {
+		return nil
+	}
+	return append(values, *r)
+}
+
+// assign sets v to the given value if it can be assigned or converted without changing its meaning.
+func assign(v reflect.Value, value interface{}) bool {
+	rv := reflect.ValueOf(value)
+	switch {
+	case rv.Type().AssignableTo(v.Type()):
+		v.Set(rv)
+	case rv.Kind() == v.Kind() && rv.Type().ConvertibleTo(v.Type()):
+		v.Set(rv.Convert(v.Type()))
+	case isNumber(rv.Kind()) && isNumber(v.Kind()):
+		return assignNumber(v, rv)
+	case rv.Kind() == reflect.Slice && v.Kind() == reflect.Slice:
+		// Slices of named types, like []Status for a multi-select, are converted one element at a time.
+		elems := reflect.MakeSlice(v.Type(), rv.Len(), rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			if !assign(elems.Index(i), rv.Index(i).Interface()) {
+				return false
+			}
+		}
+		v.Set(elems)
+	default:
+		return false
+	}
+	return true
+}
+
+func isNumber(k reflect.Kind) bool {
+	return reflect.Int <= k && k <= reflect.Float64
+}
+
+// assignNumber sets the number v to the number rv, and returns false if rv cannot be represented by the type of v:
+// if it has a fraction and v is an integer, if it is negative and v is unsigned, or if it overflows v.
+func assignNumber(v, rv reflect.Value) bool {
+	switch k := v.Kind(); {
+	case k >= reflect.Float32:
+		var f float64
+		switch {
+		case rv.Kind() >= reflect.Float32:
+			f = rv.Float()
+		case rv.Kind() >= reflect.Uint:
+			f = float64(rv.Uint())
+		default:
+			f = float64(rv.Int())
+		}
+		if v.OverflowFloat(f) {
+			return false
+		}
+		v.SetFloat(f)
+	case k >= reflect.Uint:
+		var n uint64
+		switch {
+		case rv.Kind() >= reflect.Float32:
+			f := rv.Float()
+			if f != math.Trunc(f) || f < 0 || f >= 1<<64 {
+				return false
+			}
+			n = uint64(f)
+		case rv.Kind() >= reflect.Uint:
+			n = rv.Uint()
+		default:
+			if rv.Int() < 0 {
+				return false
+			}
+			n = uint64(rv.Int())
+		}
+		if v.OverflowUint(n) {
+			return false
+		}
+		v.SetUint(n)
+	default:
+		var n int64
+		switch {
+		case rv.Kind() >= reflect.Float32:
+			f := rv.Float()
+			if f != math.Trunc(f) || f < -1<<63 || f >= 1<<63 {
+				return false
+			}
+			n = int64(f)
+		case rv.Kind() >= reflect.Uint:
+			if rv.Uint() > math.MaxInt64 {
+				return false
+			}
+			n = int64(rv.Uint())
+		default:
+			n = rv.Int()
+		}
+		if v.OverflowInt(n) {
+			return false
+		}
+		v.SetInt(n)
+	}
+	return true
+}
+
+// MarshalPageProperties encodes the struct v, or the struct it points to, into the properties of a page,
+// to be used with CreatePage or UpdatePageProperties. It uses the same "notion" tags as UnmarshalPage.
+//
+// If the type of a property is not in the tag, then it is inferred from the type of the field:
+// strings are rich text, numbers are numbers, bools are checkboxes, times are dates, and string slices are multi-selects.
+// Nil pointers, and the empty values of fields with the "omitempty" option, are skipped.
+// Properties that are computed by the Notion API, like formulas, rollups, and created times, are always skipped.
+func MarshalPageProperties(v interface{}) (PageProperties, error) {
+	rv, err := structValue(v, false)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := mappedFields(rv.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	props := make(PageProperties, 0, len(fields))
+	for _, f := range fields {
+		fv := rv.FieldByIndex(f.index)
+		if fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				continue
+			}
+			fv = fv.Elem()
+		}
+		if f.omitEmpty && isEmptyValue(fv) {
+			continue
+		}
+
+		t := f.typ
+		if t == "" {
+			if t = inferPropertyType(fv.Type()); t == "" {
+				return nil, fmt.Errorf("field %s: the property type of Go type %s cannot be inferred and must be in the notion tag", f.fieldName, fv.Type())
+			}
+		}
+		if t.IsReadOnly() {
+			continue
+		}
+
+		prop := &PageProperty{Name: f.name, Type: t}
+		if err := encodeProperty(prop, fv); err != nil {
+			return nil, fmt.Errorf("field %s: %w", f.fieldName, err)
+		}
+		props = append(props, prop)
+	}
+	return props, nil
+}
+
+// inferPropertyType returns the type of property for the Go type, or the empty string if there isn't an obvious one.
+func inferPropertyType(t reflect.Type) DatabasePropertyTypeEnum {
+	switch {
+	case t.Kind() == reflect.String:
+		return DatabasePropertyTypeEnumRichText
+	case isNumber(t.Kind()):
+		return DatabasePropertyTypeEnumNumber
+	case t.Kind() == reflect.Bool:
+		return DatabasePropertyTypeEnumCheckbox
+	case t == timeType || t == dateType:
+		return DatabasePropertyTypeEnumDate
+	case t == selectOptionType:
+		return DatabasePropertyTypeEnumSelect
+	case t == urlType:
+		return DatabasePropertyTypeEnumURL
+	case t == richTextsType:
+		return DatabasePropertyTypeEnumRichText
+	case t == usersType:
+		return DatabasePropertyTypeEnumPeople
+	case t == filesType:
+		return DatabasePropertyTypeEnumFile
+	case t == relationsType:
+		return DatabasePropertyTypeEnumRelation
+	case t.Kind() == reflect.Slice && (t.Elem().Kind() == reflect.String || t.Elem() == selectOptionType):
+		return DatabasePropertyTypeEnumMultiSelect
+	}
+	return ""
+}
+
+// encodeProperty sets the value of the page property, which already has its type, from v.
+func encodeProperty(prop *PageProperty, v reflect.Value) error {
+	ok := true
+	switch prop.Type {
+	case DatabasePropertyTypeEnumTitle:
+		prop.Title, ok = toRichText(v)
+	case DatabasePropertyTypeEnumRichText:
+		prop.RichText, ok = toRichText(v)
+	case DatabasePropertyTypeEnumNumber:
+		if ok = isNumber(v.Kind()); ok {
+			n := v.Convert(reflect.TypeOf(float64(0))).Float()
+			prop.Number = &n
+		}
+	case DatabasePropertyTypeEnumSelect:
+		if v.Kind() == reflect.String {
+			prop.Select = &SelectOption{Name: v.String()}
+		} else if ok = v.Type() == selectOptionType; ok {
+			option := v.Interface().(SelectOption)
+			prop.Select = &option
+		}
+	case DatabasePropertyTypeEnumMultiSelect:
+		if ok = v.Kind() == reflect.Slice; !ok {
+			break
+		}
+		prop.MultiSelect = make([]SelectOption, 0, v.Len())
+		for i := 0; i < v.Len() && ok; i++ {
+			switch e := v.Index(i); {
+			case e.Kind() == reflect.String:
+				prop.MultiSelect = append(prop.MultiSelect, SelectOption{Name: e.String()})
+			case e.Type() == selectOptionType:
+				prop.MultiSelect = append(prop.MultiSelect, e.Interface().(SelectOption))
+			default:
+				ok = false
+			}
+		}
+	case DatabasePropertyTypeEnumDate:
+		prop.Date, ok = toDate(v)
+	case DatabasePropertyTypeEnumPeople:
+		if ok = v.Type() == usersType; ok {
+			prop.People = v.Interface().([]*User)
+			break
+		}
+		var ids []UUID4
+		if ids, ok = toIDs(v); ok {
+			prop.People = make([]*User, 0, len(ids))
+			for _, id := range ids {
+				prop.People = append(prop.People, &User{Object: Object{ID: id, Object: "user"}})
+			}
+		}
+	case DatabasePropertyTypeEnumFile:
+		prop.Files, ok = toFiles(v)
+	case DatabasePropertyTypeEnumCheckbox:
+		if ok = v.Kind() == reflect.Bool; ok {
+			b := v.Bool()
+			prop.Checkbox = &b
+		}
+	case DatabasePropertyTypeEnumURL:
+		if v.Kind() == reflect.String {
+			u, err := url.Parse(v.String())
+			if err != nil {
+				return err
+			}
+			prop.URL = (*jsonURL)(u)
+		} else if ok = v.Type() == urlType; ok {
+			u := v.Interface().(url.URL)
+			prop.URL = (*jsonURL)(&u)
+		}
+	case DatabasePropertyTypeEnumEmail:
+		if ok = v.Kind() == reflect.String; ok {
+			prop.Email = &jsonEmail{Address: v.String()}
+		}
+	case DatabasePropertyTypeEnumPhoneNumber:
+		if ok = v.Kind() == reflect.String; ok {
+			s := v.String()
+			prop.PhoneNumber = &s
+		}
+	case DatabasePropertyTypeEnumRelation:
+		if ok = v.Type() == relationsType; ok {
+			prop.Relations = v.Interface().(Relations)
+			break
+		}
+		var ids []UUID4
+		if ids, ok = toIDs(v); ok {
+			prop.Relations = make(Relations, 0, len(ids))
+			for _, id := range ids {
+				rid := RelationID(id)
+				prop.Relations = append(prop.Relations, &rid)
+			}
+		}
+	default:
+		ok = false
+	}
+
+	if !ok {
+		return fmt.Errorf("cannot encode a Go value of type %s into a %s property", v.Type(), prop.Type)
+	}
+	return nil
+}
+
+func toRichText(v reflect.Value) ([]RichText, bool) {
+	switch {
+	case v.Kind() == reflect.String:
+		return FromPlainText(v.String()), true
+	case v.Type().ConvertibleTo(richTextsType) && v.Kind() == reflect.Slice:
+		return v.Convert(richTextsType).Interface().([]RichText), true
+	}
+	return nil, false
+}
+
+func toDate(v reflect.Value) (*Date, bool) {
+	switch v.Type() {
+	case timeType:
+		t := v.Interface().(time.Time)
+		// Times at midnight are sent as dates without a time.
+		hasTime := t.Hour() != 0 || t.Minute() != 0 || t.Second() != 0 || t.Nanosecond() != 0
+		return &Date{Start: t, HasTime: hasTime}, true
+	case dateType:
+		d := v.Interface().(Date)
+		return &d, true
+	}
+	return nil, false
+}
+
+// toIDs returns the ids in v, which is a slice of UUID4s or strings.
+func toIDs(v reflect.Value) ([]UUID4, bool) {
+	if v.Kind() != reflect.Slice {
+		return nil, false
+	}
+
+	ids := make([]UUID4, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		switch e := v.Index(i); {
+		case e.Type() == uuidType:
+			ids = append(ids, e.Interface().(UUID4))
+		case e.Kind() == reflect.String:
+			id, err := uuid.Parse(e.String())
+			if err != nil {
+				return nil, false
+			}
+			ids = append(ids, UUID4(id))
+		default:
+			return nil, false
+		}
+	}
+	return ids, true
+}
+
+// toFiles returns the files in v, which is a slice of Files, *Files, or URL strings for external files.
+func toFiles(v reflect.Value) ([]*File, bool) {
+	if v.Type() == filesType {
+		return v.Interface().([]*File), true
+	}
+	if v.Kind() != reflect.Slice {
+		return nil, false
+	}
+
+	files := make([]*File, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		switch e := v.Index(i); {
+		case e.Type() == reflect.TypeOf(File{}):
+			f := e.Interface().(File)
+			files = append(files, &f)
+		case e.Kind() == reflect.String:
+			u, err := url.Parse(e.String())
+			if err != nil {
+				return nil, false
+			}
+			f := NewExternalFile(u)
+			f.Name = e.String()
+			files = append(files, &f)
+		default:
+			return nil, false
+		}
+	}
+	return files, true
+}
+
+// isEmptyValue reports whether v is empty in the same way as the omitempty option of encoding/json.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	case reflect.Struct:
+		if v.Type() == timeType {
+			return v.Interface().(time.Time).IsZero()
+		}
+	}
+	return false
+}
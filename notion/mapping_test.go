@@ -0,0 +1,165 @@
+package notion_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/thedadams/gotion/notion"
+)
+
+type task struct {
+	Name     string     `notion:"Name,title"`
+	Notes    string     `notion:"Notes"`
+	Status   string     `notion:"Status,select"`
+	Tags     []string   `notion:"Tags,multi_select"`
+	Points   int        `notion:"Points,number"`
+	Estimate float64    `notion:"Estimate"`
+	Done     bool       `notion:"Done"`
+	Due      *time.Time `notion:"Due,date,omitempty"`
+	Ignored  string     `notion:"-"`
+}
+
+// roundTrip marshals the properties of v into a page, and sends the page through JSON as the Notion API would.
+func roundTrip(t *testing.T, v interface{}) *notion.Page {
+	t.Helper()
+	props, err := notion.MarshalPageProperties(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(&notion.Page{Properties: props})
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := new(notion.Page)
+	if err := json.Unmarshal(b, page); err != nil {
+		t.Fatal(err)
+	}
+	return page
+}
+
+func TestMapping(t *testing.T) {
+	due := time.Date(2021, 5, 10, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		task task
+		want task
+	}{
+		{name: "zero", task: task{}, want: task{}},
+		{
+			name: "all fields",
+			task: task{
+				Name:     "Launch",
+				Notes:    "Before the end of the quarter",
+				Status:   "In progress",
+				Tags:     []string{"urgent", "q2"},
+				Points:   8,
+				Estimate: 2.5,
+				Done:     true,
+				Due:      &due,
+			},
+			want: task{
+				Name:     "Launch",
+				Notes:    "Before the end of the quarter",
+				Status:   "In progress",
+				Tags:     []string{"urgent", "q2"},
+				Points:   8,
+				Estimate: 2.5,
+				Done:     true,
+				Due:      &due,
+			},
+		},
+		{name: "skipped field", task: task{Name: "Launch", Ignored: "secret"}, want: task{Name: "Launch"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := roundTrip(t, &tt.task)
+			if page.Properties.Get("Ignored") != nil {
+				t.Error(`got the property "Ignored", which is skipped with "-"`)
+			}
+
+			var got task
+			if err := notion.UnmarshalPage(page, &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnmarshalPageNumbers(t *testing.T) {
+	type number struct {
+		Value float64 `notion:"Value,number"`
+	}
+
+	tests := []struct {
+		name    string
+		value   float64
+		into    interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{name: "int", value: 42, into: new(struct{ Value int }), want: &struct{ Value int }{42}},
+		{name: "negative int", value: -42, into: new(struct{ Value int8 }), want: &struct{ Value int8 }{-42}},
+		{name: "uint", value: 255, into: new(struct{ Value uint8 }), want: &struct{ Value uint8 }{255}},
+		{name: "float32", value: 0.5, into: new(struct{ Value float32 }), want: &struct{ Value float32 }{0.5}},
+		{name: "fraction into int", value: 1.5, into: new(struct{ Value int }), wantErr: true},
+		{name: "too large for int8", value: 300, into: new(struct{ Value int8 }), wantErr: true},
+		{name: "negative into uint", value: -1, into: new(struct{ Value uint }), wantErr: true},
+		{name: "too large for uint8", value: 256, into: new(struct{ Value uint8 }), wantErr: true},
+		{name: "too large for int64", value: 1e19, into: new(struct{ Value int64 }), wantErr: true},
+		{name: "too large for float32", value: 1e39, into: new(struct{ Value float32 }), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := roundTrip(t, &number{Value: tt.value})
+			err := notion.UnmarshalPage(page, tt.into)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("got %+v, want an error", tt.into)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(tt.into, tt.want) {
+				t.Errorf("got %+v, want %+v", tt.into, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnmarshalPageErrors(t *testing.T) {
+	page := roundTrip(t, &task{Name: "Launch"})
+
+	tests := []struct {
+		name string
+		page *notion.Page
+		into interface{}
+	}{
+		{name: "nil page", page: nil, into: new(task)},
+		{name: "not a pointer", page: page, into: task{}},
+		{name: "nil pointer", page: page, into: (*task)(nil)},
+		{name: "wrong type in tag", page: page, into: new(struct {
+			Name string `notion:"Name,rich_text"`
+		})},
+		{name: "invalid type in tag", page: page, into: new(struct {
+			Name string `notion:"Name,heading"`
+		})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := notion.UnmarshalPage(tt.page, tt.into); err == nil {
+				t.Error("got no error")
+			}
+		})
+	}
+}
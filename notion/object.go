@@ -1,6 +1,7 @@
 package notion
 
 import (
+	"bytes"
 	"encoding/json"
 	"net/mail"
 	"net/url"
@@ -13,8 +14,9 @@ import (
 const (
 	noTimeDateLayout = "2006-01-02"
 
-	IconTypeEnumFile  = "file"
-	IconTypeEnumEmoji = "emoji"
+	IconTypeEnumFile     = "file"
+	IconTypeEnumExternal = "external"
+	IconTypeEnumEmoji    = "emoji"
 )
 
 // typed is an interface that returns the type of an object as returned by the Notion API.
@@ -57,8 +59,8 @@ func (u *UUID4) UnmarshalJSON(b []byte) error {
 }
 
 // MarshalJSON marshals the UUID as a string
-func (u *UUID4) MarshalJSON() ([]byte, error) {
-	return json.Marshal(u.String())
+func (u UUID4) MarshalJSON() ([]byte, error) {
+	return json.Marshal((&u).String())
 }
 
 // An Object represents all the fields that are common to all objects in the Notion API.
@@ -73,18 +75,6 @@ type Editable struct {
 	LastEditedTime time.Time `json:"last_edited_time,omitempty"`
 }
 
-// MarshalJSON marshals the date and time to one compatible with the Notion API.
-func (e *Editable) MarshalJSON() ([]byte, error) {
-	m := map[string]string{}
-	if !e.CreatedTime.IsZero() {
-		m["created_time"] = e.CreatedTime.Format(time.RFC3339)
-	}
-	if !e.LastEditedTime.IsZero() {
-		m["last_edited_time"] = e.LastEditedTime.Format(time.RFC3339)
-	}
-	return json.Marshal(m)
-}
-
 // A Date object represents a date with a start and end date/time in the Notion API.
 type Date struct {
 	Start   time.Time `json:"start"`
@@ -161,57 +151,82 @@ func (ite *IconTypeEnum) SetValue(s string) {
 
 // IsValidEnum returns true if the string represents a valid IconTypeEnum in the Notion API.
 func (ite *IconTypeEnum) IsValidEnum() bool {
-	return ite != nil && isValidEnum(string(*ite), IconTypeEnumFile, IconTypeEnumEmoji)
+	return ite != nil && isValidEnum(string(*ite), IconTypeEnumFile, IconTypeEnumExternal, IconTypeEnumEmoji)
 }
 
-// UnmarshalJSON returns an error if the type is not a valid enum in the Notion API.
+// UnmarshalJSON sets the IconTypeEnum to the string, even if it is not valid in the Notion API. Use IsValidEnum to check it.
 func (ite *IconTypeEnum) UnmarshalJSON(b []byte) error {
 	return unmarshalEnum(b, ite)
 }
 
-// Icons represents the icon and cover icon for pages and databases in the Notion API
+// Icons represents the icon and cover of pages and databases in the Notion API.
+// A nil Icon or Cover is not sent to the Notion API, so it is left as it is when updating.
+// A non-nil Icon or Cover without a type removes the icon or cover.
 type Icons struct {
-	Cover File `json:"cover,omitempty"`
-	Icon  Icon `json:"icon,omitempty"`
+	Cover *File `json:"cover,omitempty"`
+	Icon  *Icon `json:"icon,omitempty"`
 }
 
-// An Icon represents a page or database icon in the Notion API
+// An Icon represents a page, database, or callout icon in the Notion API: an emoji, or an external or uploaded file.
 type Icon struct {
 	Type  IconTypeEnum `json:"type"`
 	Emoji string       `json:"emoji,omitempty"`
-	File  File         `json:"file,omitempty"`
-}
+	// File is the file of an external or file icon, whose type is the type of the icon.
+	File File `json:"-"`
 
-type icon Icon
+	// Raw holds the JSON of an icon with a type that is not known to this package, so that it is marshaled unchanged.
+	Raw json.RawMessage `json:"-"`
+}
 
-// GetType returns the type of the page property
-func (i *icon) getType() string {
-	if i == nil {
-		return ""
-	}
-	return string(i.Type)
+// NewEmojiIcon returns an Icon with the given emoji.
+func NewEmojiIcon(emoji string) *Icon {
+	return &Icon{Type: IconTypeEnumEmoji, Emoji: emoji}
 }
 
-// FieldsToExpand implements the expander interface for Icon
-func (i *icon) fieldsToExpand() []string {
-	return []string{"emoji", "file"}
+// NewExternalIcon returns an Icon with the image at the given URL.
+func NewExternalIcon(u *url.URL) *Icon {
+	return &Icon{Type: IconTypeEnumExternal, File: NewExternalFile(u)}
 }
 
-// UnmarshalJSON flattens the icon by its type
+// UnmarshalJSON unmarshals the emoji of an emoji icon, or the file of an external or file icon.
 func (i *Icon) UnmarshalJSON(b []byte) error {
-	ii := new(icon)
-	if err := unmarshalJSONFlattenByType(b, ii); err != nil {
+	ii := struct {
+		Type  IconTypeEnum `json:"type"`
+		Emoji string       `json:"emoji"`
+	}{}
+	if err := json.Unmarshal(b, &ii); err != nil {
 		return err
 	}
 
-	*i = Icon(*ii)
+	*i = Icon{Type: ii.Type, Emoji: ii.Emoji}
+	switch {
+	case ii.Type == IconTypeEnumExternal || ii.Type == IconTypeEnumFile:
+		return json.Unmarshal(b, &i.File)
+	case ii.Type != "" && !ii.Type.IsValidEnum():
+		i.Raw = append(json.RawMessage(nil), b...)
+	}
 	return nil
 }
 
 // MarshalJSON marshals the Icon to be compatible with the Notion API.
+// An Icon without a type is marshaled as null, which removes the icon, and an Icon with an invalid type is an error,
+// unless it was unmarshaled with that type.
 func (i *Icon) MarshalJSON() ([]byte, error) {
-	ii := icon(*i)
-	return marshalJSONExpandByType(&ii)
+	switch {
+	case i != nil && i.Raw != nil && !i.Type.IsValidEnum():
+		return i.Raw, nil
+	case i == nil || i.Type == "":
+		return []byte("null"), nil
+	case !i.Type.IsValidEnum():
+		return nil, NewInvalidEnumError("IconTypeEnum", string(i.Type))
+	case i.Type == IconTypeEnumEmoji:
+		return json.Marshal(map[string]string{"type": string(i.Type), "emoji": i.Emoji})
+	}
+
+	// The icon has the same form as the file, without a name.
+	f := i.File
+	f.Type, f.Name = FileTypeEnum(i.Type), ""
+	return json.Marshal(&f)
 }
 
 type jsonURL url.URL
@@ -258,9 +273,8 @@ func (je *jsonEmail) MarshalJSON() ([]byte, error) {
 	if je == nil {
 		return nil, nil
 	}
-	a := mail.Address(*je)
-	a.Name = ""
-	return json.Marshal((&a).String())
+	// Only the address is sent: the String method of mail.Address would wrap it in angle brackets.
+	return json.Marshal(je.Address)
 }
 
 func unmarshalJSONFlattenByType(bt []byte, b typed) error {
@@ -268,17 +282,14 @@ func unmarshalJSONFlattenByType(bt []byte, b typed) error {
 		return err
 	}
 
-	m := make(map[string]interface{})
+	m := make(map[string]json.RawMessage)
 	if err := json.Unmarshal(bt, &m); err != nil {
 		return err
 	}
 
-	if bt, err := json.Marshal(m[b.getType()]); err == nil {
-		if err := json.Unmarshal(bt, b); err != nil { //nolint:govet
-			return err
-		}
-	} else {
-		return err
+	// Only objects can be flattened. Other values, like the emoji of an Icon, are set by the first unmarshal.
+	if raw := bytes.TrimSpace(m[b.getType()]); len(raw) != 0 && raw[0] == '{' {
+		return json.Unmarshal(raw, b)
 	}
 
 	return nil
@@ -297,9 +308,15 @@ func marshalJSONExpandByType(v expander) ([]byte, error) {
 	if err := json.Unmarshal(b, &m); err != nil {
 		return nil, err
 	}
+	removeUnsetFields(m)
 
+	t := v.getType()
 	typeMap := make(map[string]interface{})
 	for _, field := range v.fieldsToExpand() {
+		// The field with the same name as the type is already where it belongs.
+		if field == t {
+			continue
+		}
 		// Need to check the existence of a key because not all expanded fields will exist for all types.
 		if v, ok := m[field]; ok {
 			typeMap[field] = v
@@ -307,5 +324,28 @@ func marshalJSONExpandByType(v expander) ([]byte, error) {
 		}
 	}
 
-	return json.Marshal(typeMap)
+	if t != "" {
+		switch typed := m[t].(type) {
+		case nil:
+			if len(v.fieldsToExpand()) != 0 {
+				m[t] = typeMap
+			}
+		case map[string]interface{}:
+			for field, v := range typeMap {
+				typed[field] = v
+			}
+		}
+	}
+
+	return json.Marshal(m)
+}
+
+// removeUnsetFields removes the fields that cannot be omitted with struct tags: zero times and zero ids.
+func removeUnsetFields(m map[string]interface{}) {
+	zeroTime, zeroID := time.Time{}.Format(time.RFC3339Nano), uuid.UUID{}.String()
+	for field, v := range m {
+		if s, ok := v.(string); ok && (s == zeroTime || (field == "id" && s == zeroID)) {
+			delete(m, field)
+		}
+	}
 }
@@ -0,0 +1,102 @@
+package notion_test
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/thedadams/gotion/notion"
+)
+
+func TestIconJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		json  string
+		check func(*notion.Icon) bool
+	}{
+		{
+			name:  "emoji",
+			json:  `{"type":"emoji","emoji":"🚀"}`,
+			check: func(i *notion.Icon) bool { return i.Type == notion.IconTypeEnumEmoji && i.Emoji == "🚀" },
+		},
+		{
+			name: "external",
+			json: `{"type":"external","external":{"url":"https://example.com/icon.png"}}`,
+			check: func(i *notion.Icon) bool {
+				return i.Type == notion.IconTypeEnumExternal && i.File.GetURL().String() == "https://example.com/icon.png"
+			},
+		},
+		{
+			name: "file",
+			json: `{"type":"file","file":{"url":"https://example.com/icon.png","expiry_time":"2022-01-02T03:04:05Z"}}`,
+			check: func(i *notion.Icon) bool {
+				return i.Type == notion.IconTypeEnumFile && i.File.GetURL() != nil && i.Emoji == ""
+			},
+		},
+		{
+			name:  "unknown type",
+			json:  `{"type":"custom_emoji","custom_emoji":{"id":"1","name":"party"}}`,
+			check: func(i *notion.Icon) bool { return i.Type == "custom_emoji" && i.Raw != nil },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			icon := new(notion.Icon)
+			if err := json.Unmarshal([]byte(tt.json), icon); err != nil {
+				t.Fatal(err)
+			}
+			if !tt.check(icon) {
+				t.Errorf("got icon %+v, which is missing fields of %s", icon, tt.json)
+			}
+
+			got, err := json.Marshal(icon)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !jsonEqual(t, got, []byte(tt.json)) {
+				t.Errorf("got %s, want %s", got, tt.json)
+			}
+		})
+	}
+}
+
+func TestIconMarshal(t *testing.T) {
+	u, err := url.Parse("https://example.com/icon.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		v       interface{}
+		want    string
+		wantErr bool
+	}{
+		{name: "emoji", v: notion.NewEmojiIcon("🚀"), want: `{"type":"emoji","emoji":"🚀"}`},
+		{name: "external", v: notion.NewExternalIcon(u), want: `{"type":"external","external":{"url":"https://example.com/icon.png"}}`},
+		{name: "no type", v: &notion.Icon{Emoji: "🚀"}, want: `null`},
+		{name: "nil", v: (*notion.Icon)(nil), want: `null`},
+		{name: "invalid type", v: &notion.Icon{Type: "custom_emoji"}, wantErr: true},
+		{name: "removed icon", v: &notion.Icons{Icon: &notion.Icon{}}, want: `{"icon":null}`},
+		{name: "unchanged icon", v: &notion.Icons{}, want: `{}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.v)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("got %s, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !jsonEqual(t, got, []byte(tt.want)) {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
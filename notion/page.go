@@ -3,6 +3,7 @@ package notion
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"time"
 
 	"github.com/google/uuid"
@@ -42,7 +43,7 @@ func (pte *ParentTypeEnum) IsValidEnum() bool {
 	return pte != nil && isValidEnum(string(*pte), ParentTypeEnumWorkspace, ParentTypeEnumPage, ParentTypeEnumDatabase)
 }
 
-// UnmarshalJSON returns an error if the type is not a valid enum in the Notion API.
+// UnmarshalJSON sets the ParentTypeEnum to the string, even if it is not valid in the Notion API. Use IsValidEnum to check it.
 func (pte *ParentTypeEnum) UnmarshalJSON(b []byte) error {
 	return unmarshalEnum(b, pte)
 }
@@ -62,7 +63,7 @@ func (fte *FormulaTypeEnum) IsValidEnum() bool {
 	return fte != nil && isValidEnum(string(*fte), FormulaTypeEnumString, FormulaTypeEnumNumber, FormulaTypeEnumBoolean, FormulaTypeEnumDate)
 }
 
-// UnmarshalJSON returns an error if the type is not a valid enum in the Notion API.
+// UnmarshalJSON sets the FormulaTypeEnum to the string, even if it is not valid in the Notion API. Use IsValidEnum to check it.
 func (fte *FormulaTypeEnum) UnmarshalJSON(b []byte) error {
 	return unmarshalEnum(b, fte)
 }
@@ -82,7 +83,7 @@ func (rvte *RollupValueTypeEnum) IsValidEnum() bool {
 	return rvte != nil && isValidEnum(string(*rvte), RollupValueTypeEnumNumber, RollupValueTypeEnumDate, RollupValueTypeEnumArray)
 }
 
-// UnmarshalJSON returns an error if the type is not a valid enum in the Notion API.
+// UnmarshalJSON sets the RollupValueTypeEnum to the string, even if it is not valid in the Notion API. Use IsValidEnum to check it.
 func (rvte *RollupValueTypeEnum) UnmarshalJSON(b []byte) error {
 	return unmarshalEnum(b, rvte)
 }
@@ -102,7 +103,7 @@ func (pte *FileTypeEnum) IsValidEnum() bool {
 	return pte != nil && isValidEnum(string(*pte), FileTypeEnumFile, FileTypeEnumExternal)
 }
 
-// UnmarshalJSON returns an error if the type is not a valid enum in the Notion API.
+// UnmarshalJSON sets the FileTypeEnum to the string, even if it is not valid in the Notion API. Use IsValidEnum to check it.
 func (pte *FileTypeEnum) UnmarshalJSON(b []byte) error {
 	return unmarshalEnum(b, pte)
 }
@@ -111,6 +112,9 @@ func (pte *FileTypeEnum) UnmarshalJSON(b []byte) error {
 type Parent struct {
 	Type ParentTypeEnum `json:"type"`
 	ID   string
+
+	// Raw holds the JSON of a parent with a type that is not known to this package, so that it is marshaled unchanged.
+	Raw json.RawMessage `json:"-"`
 }
 
 // UnmarshalJSON sets the ID field based on the parent type
@@ -128,6 +132,9 @@ func (p *Parent) UnmarshalJSON(b []byte) error {
 	if id, ok := m[string(p.Type)].(string); ok {
 		p.ID = id
 	}
+	if p.Type != "" && !p.Type.IsValidEnum() {
+		p.Raw = append(json.RawMessage(nil), b...)
+	}
 
 	return nil
 }
@@ -137,6 +144,9 @@ func (p *Parent) MarshalJSON() ([]byte, error) {
 	if p == nil {
 		return nil, nil
 	}
+	if p.Raw != nil && !p.Type.IsValidEnum() {
+		return p.Raw, nil
+	}
 
 	m := map[string]interface{}{"type": p.Type}
 	if string(p.Type) != ParentTypeEnumWorkspace {
@@ -152,6 +162,9 @@ type Formula struct {
 	Number  *float64        `json:"number,omitempty"`
 	Boolean *bool           `json:"boolean,omitempty"`
 	Date    *Date           `json:"date,omitempty"`
+
+	// Raw holds the JSON of a formula with a type that is not known to this package, so that it is marshaled unchanged.
+	Raw json.RawMessage `json:"-"`
 }
 
 type formula Formula
@@ -169,8 +182,25 @@ func (f *formula) fieldsToExpand() []string {
 	return []string{"string", "number", "boolean", "date"}
 }
 
+// UnmarshalJSON unmarshals the formula, keeping the JSON of a formula with an unknown type.
+func (f *Formula) UnmarshalJSON(b []byte) error {
+	ff := new(formula)
+	if err := json.Unmarshal(b, ff); err != nil {
+		return err
+	}
+	if ff.Type != "" && !ff.Type.IsValidEnum() {
+		ff.Raw = append(json.RawMessage(nil), b...)
+	}
+
+	*f = Formula(*ff)
+	return nil
+}
+
 // MarshalJSON expands the formula by its type to be compatible with the Notion API.
 func (f *Formula) MarshalJSON() ([]byte, error) {
+	if f.Raw != nil && !f.Type.IsValidEnum() {
+		return f.Raw, nil
+	}
 	ff := formula(*f)
 	return marshalJSONExpandByType(&ff)
 }
@@ -178,21 +208,16 @@ func (f *Formula) MarshalJSON() ([]byte, error) {
 // A RelationID represents a single relation in a page in a database in the Notion API.
 type RelationID UUID4
 
-type relationID RelationID
-
-// GetType is a hack to use the unmarshaler functions. It has not meaning in the context of a RelationID.
-func (rid *relationID) getType() string {
-	return "id"
-}
-
 // UnmarshalJSON flattens a relation reference for a page in a database in the Notion API.
 func (rid *RelationID) UnmarshalJSON(b []byte) error {
-	r := new(relationID)
-	if err := unmarshalJSONFlattenByType(b, r); err != nil {
+	r := struct {
+		ID UUID4 `json:"id"`
+	}{}
+	if err := json.Unmarshal(b, &r); err != nil {
 		return err
 	}
 
-	*rid = RelationID(*r)
+	*rid = RelationID(r.ID)
 	return nil
 }
 
@@ -213,14 +238,44 @@ type RollupValue struct {
 	Number *float64            `json:"number,omitempty"`
 	Date   *Date               `json:"date,omitempty"`
 	Array  []*PageProperty     `json:"array,omitempty"`
+
+	// Raw holds the JSON of a rollup value with a type that is not known to this package, so that it is marshaled unchanged.
+	Raw json.RawMessage `json:"-"`
+}
+
+type rollupValue RollupValue
+
+// UnmarshalJSON unmarshals the rollup value, keeping the JSON of a rollup value with an unknown type.
+func (rv *RollupValue) UnmarshalJSON(b []byte) error {
+	r := new(rollupValue)
+	if err := json.Unmarshal(b, r); err != nil {
+		return err
+	}
+	if r.Type != "" && !r.Type.IsValidEnum() {
+		r.Raw = append(json.RawMessage(nil), b...)
+	}
+
+	*rv = RollupValue(*r)
+	return nil
+}
+
+// MarshalJSON marshals the rollup value, using the original JSON for a rollup value with an unknown type.
+func (rv *RollupValue) MarshalJSON() ([]byte, error) {
+	if rv.Raw != nil && !rv.Type.IsValidEnum() {
+		return rv.Raw, nil
+	}
+	return json.Marshal((*rollupValue)(rv))
 }
 
 // A File represents a file property of a page in a database in the Notion API.
 type File struct {
-	Name       string       `json:"name"`
+	Name       string       `json:"name,omitempty"`
 	Type       FileTypeEnum `json:"type"`
 	URL        *jsonURL     `json:"url"`
 	ExpiryTime time.Time    `json:"expiry_time,omitempty"`
+
+	// Raw holds the JSON of a file with a type that is not known to this package, so that it is marshaled unchanged.
+	Raw json.RawMessage `json:"-"`
 }
 
 type file File
@@ -244,17 +299,50 @@ func (f *File) UnmarshalJSON(b []byte) error {
 	if err := unmarshalJSONFlattenByType(b, ff); err != nil {
 		return err
 	}
+	if ff.Type != "" && !ff.Type.IsValidEnum() {
+		ff.Raw = append(json.RawMessage(nil), b...)
+	}
 
 	*f = File(*ff)
 	return nil
 }
 
 // MarshalJSON marshals the File to be compatible with the Notion API.
+// A File without a type is marshaled as null, which removes a cover, and a File with an invalid type is an error,
+// unless it was unmarshaled with that type.
 func (f *File) MarshalJSON() ([]byte, error) {
+	if f != nil && f.Raw != nil && !f.Type.IsValidEnum() {
+		return f.Raw, nil
+	}
+	if f == nil || f.Type == "" {
+		return []byte("null"), nil
+	}
+	if !f.Type.IsValidEnum() {
+		return nil, NewInvalidEnumError("FileTypeEnum", string(f.Type))
+	}
 	ff := file(*f)
 	return marshalJSONExpandByType(&ff)
 }
 
+// GetURL returns a pointer to the URL of the File, if it exists, and returns nil otherwise.
+func (f *File) GetURL() *url.URL {
+	if f == nil || f.URL == nil {
+		return nil
+	}
+
+	u := url.URL(*f.URL)
+	return &u
+}
+
+// NewExternalFile returns an external File with the given URL, to be used in media blocks and covers.
+func NewExternalFile(u *url.URL) File {
+	f := File{Type: FileTypeEnumExternal}
+	if u != nil {
+		f.URL = (*jsonURL)(u)
+	}
+	return f
+}
+
 // PageProperty represents a property of a page in a database in the Notion API.
 type PageProperty struct {
 	Editable
@@ -269,6 +357,7 @@ type PageProperty struct {
 	Date         *Date                    `json:"date,omitempty"`
 	Formula      *Formula                 `json:"formula,omitempty"`
 	Relations    Relations                `json:"relation,omitempty"`
+	Rollup       *RollupValue             `json:"rollup,omitempty"`
 	People       []*User                  `json:"people,omitempty"`
 	Files        []*File                  `json:"files,omitempty"`
 	Checkbox     *bool                    `json:"checkbox,omitempty"`
@@ -277,6 +366,9 @@ type PageProperty struct {
 	PhoneNumber  *string                  `json:"phone_number,omitempty"`
 	CreatedBy    *User                    `json:"created_by,omitempty"`
 	LastEditedBy *User                    `json:"last_edited_by,omitempty"`
+
+	// Raw holds the JSON of a property with a type that is not known to this package, so that it is marshaled unchanged.
+	Raw json.RawMessage `json:"-"`
 }
 
 type pageProperty PageProperty
@@ -291,14 +383,15 @@ func (pp *pageProperty) getType() string {
 
 // FieldsToExpand implements the expander interface for PageProperty
 func (pp *pageProperty) fieldsToExpand() []string {
-	return []string{"title", "rich_text", "number", "select", "multi_select", "date", "formula", "relation",
+	return []string{"title", "rich_text", "number", "select", "multi_select", "date", "formula", "relation", "rollup",
 		"people", "files", "checkbox", "url", "email", "phone_number", "created_by", "last_edited_by", "created_time", "last_edited_time"}
 }
 
-// UnmarshalJSON flattens the page property by its type
+// UnmarshalJSON unmarshals the page property. The value of the property is not flattened, since each type has its own
+// field, and flattening would overwrite the type and id of the property with those of a formula, rollup, or select option.
 func (pp *PageProperty) UnmarshalJSON(b []byte) error {
 	ppp := new(pageProperty)
-	if err := unmarshalJSONFlattenByType(b, ppp); err != nil {
+	if err := json.Unmarshal(b, ppp); err != nil {
 		return err
 	}
 
@@ -306,10 +399,36 @@ func (pp *PageProperty) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// listPropertyTypes are the types of the page properties with a list as their value.
+var listPropertyTypes = map[DatabasePropertyTypeEnum]bool{
+	DatabasePropertyTypeEnumTitle:       true,
+	DatabasePropertyTypeEnumRichText:    true,
+	DatabasePropertyTypeEnumMultiSelect: true,
+	DatabasePropertyTypeEnumPeople:      true,
+	DatabasePropertyTypeEnumFile:        true,
+	DatabasePropertyTypeEnumRelation:    true,
+}
+
 // MarshalJSON marshals the page property to be compatible with the Notion API.
 func (pp *PageProperty) MarshalJSON() ([]byte, error) {
+	if pp.Raw != nil && !pp.Type.IsValidEnum() {
+		return pp.Raw, nil
+	}
 	ppp := pageProperty(*pp)
-	return marshalJSONExpandByType(&ppp)
+	b, err := marshalJSONExpandByType(&ppp)
+	if err != nil || !listPropertyTypes[pp.Type] {
+		return b, err
+	}
+
+	// An empty list is omitted when marshaling, but it is needed to clear the property in the Notion API.
+	m := make(map[string]interface{})
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	if v, ok := m[string(pp.Type)].(map[string]interface{}); ok && len(v) == 0 {
+		m[string(pp.Type)] = []interface{}{}
+	}
+	return json.Marshal(m)
 }
 
 // PageProperties represents the properties of a page in a database in the Notion API.
@@ -317,14 +436,21 @@ type PageProperties []*PageProperty
 
 // UnmarshalJSON unmarshals the page properties from a "javascript" style to a more "go" style
 func (pps *PageProperties) UnmarshalJSON(b []byte) error {
-	var m map[string]*pageProperty
+	var m map[string]json.RawMessage
 	if err := json.Unmarshal(b, &m); err != nil {
 		return err
 	}
 
 	*pps = make([]*PageProperty, 0, len(m))
-	for name, prop := range m {
+	for name, raw := range m {
+		prop := new(pageProperty)
+		if err := json.Unmarshal(raw, prop); err != nil {
+			return err
+		}
 		prop.Name = name
+		if !prop.Type.IsValidEnum() {
+			prop.Raw = raw
+		}
 		p := PageProperty(*prop)
 		*pps = append(*pps, &p)
 	}
@@ -0,0 +1,287 @@
+package notion
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+)
+
+// A PropertyError is returned by the typed getters and setters of PageProperties
+// when the property doesn't exist or has a different type.
+type PropertyError struct {
+	Name string
+	// Type is the type of the property, and is empty if the property doesn't exist.
+	Type     DatabasePropertyTypeEnum
+	Expected []DatabasePropertyTypeEnum
+}
+
+// Error implements the error interface for PropertyError
+func (pe *PropertyError) Error() string {
+	if pe.Type == "" {
+		return fmt.Sprintf("page property %q does not exist", pe.Name)
+	}
+
+	expected := make([]string, 0, len(pe.Expected))
+	for _, t := range pe.Expected {
+		expected = append(expected, string(t))
+	}
+	return fmt.Sprintf("page property %q has type %s, not %s", pe.Name, pe.Type, strings.Join(expected, " or "))
+}
+
+// Get returns the property with the given name, or nil if there isn't one.
+func (pps PageProperties) Get(name string) *PageProperty {
+	for _, prop := range pps {
+		if prop != nil && prop.Name == name {
+			return prop
+		}
+	}
+	return nil
+}
+
+// GetByID returns the property with the given id, or nil if there isn't one.
+func (pps PageProperties) GetByID(id string) *PageProperty {
+	for _, prop := range pps {
+		if prop != nil && prop.ID == id {
+			return prop
+		}
+	}
+	return nil
+}
+
+// typed returns the property with the given name, or an error if it doesn't exist or doesn't have one of the types.
+func (pps PageProperties) typed(name string, types ...DatabasePropertyTypeEnum) (*PageProperty, error) {
+	prop := pps.Get(name)
+	if prop == nil {
+		return nil, &PropertyError{Name: name, Expected: types}
+	}
+	for _, t := range types {
+		if prop.Type == t {
+			return prop, nil
+		}
+	}
+	return nil, &PropertyError{Name: name, Type: prop.Type, Expected: types}
+}
+
+// Title returns the plain text of the title property.
+func (pps PageProperties) Title() (string, error) {
+	for _, prop := range pps {
+		if prop != nil && prop.Type == DatabasePropertyTypeEnumTitle {
+			return PlainText(prop.Title), nil
+		}
+	}
+	return "", &PropertyError{Name: DatabasePropertyTypeEnumTitle, Expected: []DatabasePropertyTypeEnum{DatabasePropertyTypeEnumTitle}}
+}
+
+// Text returns the plain text of the title or rich text property with the given name.
+func (pps PageProperties) Text(name string) (string, error) {
+	prop, err := pps.typed(name, DatabasePropertyTypeEnumRichText, DatabasePropertyTypeEnumTitle)
+	if err != nil {
+		return "", err
+	}
+	if prop.Type == DatabasePropertyTypeEnumTitle {
+		return PlainText(prop.Title), nil
+	}
+	return PlainText(prop.RichText), nil
+}
+
+// Number returns the value of the number property with the given name, which is nil if it is not set.
+func (pps PageProperties) Number(name string) (*float64, error) {
+	prop, err := pps.typed(name, DatabasePropertyTypeEnumNumber)
+	if err != nil {
+		return nil, err
+	}
+	return prop.Number, nil
+}
+
+// Date returns the value of the date property with the given name, which is nil if it is not set.
+func (pps PageProperties) Date(name string) (*Date, error) {
+	prop, err := pps.typed(name, DatabasePropertyTypeEnumDate)
+	if err != nil {
+		return nil, err
+	}
+	return prop.Date, nil
+}
+
+// Select returns the name of the selected option of the select property with the given name,
+// which is empty if no option is selected.
+func (pps PageProperties) Select(name string) (string, error) {
+	prop, err := pps.typed(name, DatabasePropertyTypeEnumSelect)
+	if err != nil || prop.Select == nil {
+		return "", err
+	}
+	return prop.Select.Name, nil
+}
+
+// Checkbox returns whether the checkbox property with the given name is checked.
+func (pps PageProperties) Checkbox(name string) (bool, error) {
+	prop, err := pps.typed(name, DatabasePropertyTypeEnumCheckbox)
+	if err != nil || prop.Checkbox == nil {
+		return false, err
+	}
+	return *prop.Checkbox, nil
+}
+
+// Relations returns the ids of the pages related by the relation property with the given name.
+func (pps PageProperties) Relations(name string) ([]UUID4, error) {
+	prop, err := pps.typed(name, DatabasePropertyTypeEnumRelation)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]UUID4, 0, len(prop.Relations))
+	for _, rid := range prop.Relations {
+		if rid != nil {
+			ids = append(ids, UUID4(*rid))
+		}
+	}
+	return ids, nil
+}
+
+// set sets the value of the property with the given name, adding the property if it doesn't exist.
+// An error is returned if the property exists with another type.
+func (pps *PageProperties) set(name string, t DatabasePropertyTypeEnum, setValue func(*PageProperty)) error {
+	prop := pps.Get(name)
+	if prop == nil {
+		prop = &PageProperty{Name: name}
+		*pps = append(*pps, prop)
+	} else if prop.Type != "" && prop.Type != t {
+		return &PropertyError{Name: name, Type: prop.Type, Expected: []DatabasePropertyTypeEnum{t}}
+	}
+
+	prop.Type = t
+	setValue(prop)
+	return nil
+}
+
+// SetTitle sets the title property to the given plain text.
+// If there is no title property, then one is added with the name "title", which the Notion API accepts for the title of any page.
+func (pps *PageProperties) SetTitle(text string) error {
+	name := DatabasePropertyTypeEnumTitle
+	for _, prop := range *pps {
+		if prop != nil && prop.Type == DatabasePropertyTypeEnumTitle {
+			name = prop.Name
+			break
+		}
+	}
+	return pps.set(name, DatabasePropertyTypeEnumTitle, func(prop *PageProperty) {
+		prop.Title = FromPlainText(text)
+	})
+}
+
+// SetText sets the rich text property with the given name to the given plain text.
+func (pps *PageProperties) SetText(name, text string) error {
+	return pps.set(name, DatabasePropertyTypeEnumRichText, func(prop *PageProperty) {
+		prop.RichText = FromPlainText(text)
+	})
+}
+
+// SetNumber sets the number property with the given name.
+func (pps *PageProperties) SetNumber(name string, n float64) error {
+	return pps.set(name, DatabasePropertyTypeEnumNumber, func(prop *PageProperty) {
+		prop.Number = &n
+	})
+}
+
+// SetDate sets the date property with the given name.
+func (pps *PageProperties) SetDate(name string, d Date) error {
+	return pps.set(name, DatabasePropertyTypeEnumDate, func(prop *PageProperty) {
+		prop.Date = &d
+	})
+}
+
+// SetSelect sets the select property with the given name to the option with the given name.
+func (pps *PageProperties) SetSelect(name, option string) error {
+	return pps.set(name, DatabasePropertyTypeEnumSelect, func(prop *PageProperty) {
+		prop.Select = &SelectOption{Name: option}
+	})
+}
+
+// SetCheckbox sets the checkbox property with the given name.
+func (pps *PageProperties) SetCheckbox(name string, checked bool) error {
+	return pps.set(name, DatabasePropertyTypeEnumCheckbox, func(prop *PageProperty) {
+		prop.Checkbox = &checked
+	})
+}
+
+// SetRelations sets the relation property with the given name to the pages with the given ids.
+func (pps *PageProperties) SetRelations(name string, ids ...UUID4) error {
+	return pps.set(name, DatabasePropertyTypeEnumRelation, func(prop *PageProperty) {
+		prop.Relations = make(Relations, 0, len(ids))
+		for _, id := range ids {
+			rid := RelationID(id)
+			prop.Relations = append(prop.Relations, &rid)
+		}
+	})
+}
+
+// SetMultiSelect sets the multi-select property with the given name to the options with the given names.
+func (pps *PageProperties) SetMultiSelect(name string, options ...string) error {
+	return pps.set(name, DatabasePropertyTypeEnumMultiSelect, func(prop *PageProperty) {
+		prop.MultiSelect = make([]SelectOption, 0, len(options))
+		for _, option := range options {
+			prop.MultiSelect = append(prop.MultiSelect, SelectOption{Name: option})
+		}
+	})
+}
+
+// SetPeople sets the people property with the given name to the users with the given ids.
+func (pps *PageProperties) SetPeople(name string, ids ...UUID4) error {
+	return pps.set(name, DatabasePropertyTypeEnumPeople, func(prop *PageProperty) {
+		prop.People = make([]*User, 0, len(ids))
+		for _, id := range ids {
+			u := &User{Object: Object{ID: id, Object: "user"}}
+			prop.People = append(prop.People, u)
+		}
+	})
+}
+
+// SetFiles sets the files property with the given name.
+func (pps *PageProperties) SetFiles(name string, files ...File) error {
+	return pps.set(name, DatabasePropertyTypeEnumFile, func(prop *PageProperty) {
+		prop.Files = make([]*File, 0, len(files))
+		for i := range files {
+			prop.Files = append(prop.Files, &files[i])
+		}
+	})
+}
+
+// SetURL sets the URL property with the given name.
+func (pps *PageProperties) SetURL(name string, u *url.URL) error {
+	return pps.set(name, DatabasePropertyTypeEnumURL, func(prop *PageProperty) {
+		prop.URL = (*jsonURL)(u)
+	})
+}
+
+// SetEmail sets the email property with the given name. An error is returned if the address is not valid.
+func (pps *PageProperties) SetEmail(name, address string) error {
+	a, err := mail.ParseAddress(address)
+	if err != nil {
+		return err
+	}
+	return pps.set(name, DatabasePropertyTypeEnumEmail, func(prop *PageProperty) {
+		prop.Email = (*jsonEmail)(a)
+	})
+}
+
+// SetPhoneNumber sets the phone number property with the given name.
+func (pps *PageProperties) SetPhoneNumber(name, number string) error {
+	return pps.set(name, DatabasePropertyTypeEnumPhoneNumber, func(prop *PageProperty) {
+		prop.PhoneNumber = &number
+	})
+}
+
+// Get returns the property of the page with the given name, or nil if there isn't one.
+func (p *Page) Get(name string) *PageProperty {
+	return p.Properties.Get(name)
+}
+
+// GetByID returns the property of the page with the given id, or nil if there isn't one.
+func (p *Page) GetByID(id string) *PageProperty {
+	return p.Properties.GetByID(id)
+}
+
+// Title returns the plain text of the title of the page.
+func (p *Page) Title() (string, error) {
+	return p.Properties.Title()
+}
@@ -0,0 +1,147 @@
+package notion_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/thedadams/gotion/notion"
+)
+
+// jsonPage sends a page with the properties through JSON as the Notion API would.
+func jsonPage(t *testing.T, props notion.PageProperties) *notion.Page {
+	t.Helper()
+	b, err := json.Marshal(&notion.Page{Properties: props})
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := new(notion.Page)
+	if err := json.Unmarshal(b, page); err != nil {
+		t.Fatal(err)
+	}
+	return page
+}
+
+func TestPropertySetters(t *testing.T) {
+	id := notion.UUID4(uuid.MustParse("0b9c3b0a-5f5e-4a4c-9d2f-8d3b1f9e2c11"))
+	due := notion.Date{Start: time.Date(2022, 3, 4, 0, 0, 0, 0, time.UTC)}
+
+	var props notion.PageProperties
+	for _, err := range []error{
+		props.SetTitle("Launch"),
+		props.SetText("Notes", "Ship it"),
+		props.SetNumber("Points", 3),
+		props.SetDate("Due", due),
+		props.SetSelect("Status", "Done"),
+		props.SetCheckbox("Done", true),
+		props.SetRelations("Blocked by", id),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	page := jsonPage(t, props)
+
+	if title, err := page.Title(); err != nil || title != "Launch" {
+		t.Errorf("got title %q and error %v, want %q", title, err, "Launch")
+	}
+	if text, err := page.Properties.Text("Notes"); err != nil || text != "Ship it" {
+		t.Errorf("got text %q and error %v, want %q", text, err, "Ship it")
+	}
+	if n, err := page.Properties.Number("Points"); err != nil || n == nil || *n != 3 {
+		t.Errorf("got number %v and error %v, want 3", n, err)
+	}
+	if d, err := page.Properties.Date("Due"); err != nil || d == nil || !d.Start.Equal(due.Start) {
+		t.Errorf("got date %v and error %v, want %v", d, err, due)
+	}
+	if option, err := page.Properties.Select("Status"); err != nil || option != "Done" {
+		t.Errorf("got option %q and error %v, want %q", option, err, "Done")
+	}
+	if checked, err := page.Properties.Checkbox("Done"); err != nil || !checked {
+		t.Errorf("got checkbox %v and error %v, want it checked", checked, err)
+	}
+	if ids, err := page.Properties.Relations("Blocked by"); err != nil || len(ids) != 1 || ids[0] != id {
+		t.Errorf("got relations %v and error %v, want %v", ids, err, id)
+	}
+}
+
+func TestPropertySetterReplaces(t *testing.T) {
+	props := notion.PageProperties{{Name: "Name", Type: notion.DatabasePropertyTypeEnumTitle}}
+	if err := props.SetTitle("First"); err != nil {
+		t.Fatal(err)
+	}
+	if err := props.SetTitle("Second"); err != nil {
+		t.Fatal(err)
+	}
+	if len(props) != 1 || props[0].Name != "Name" {
+		t.Fatalf("got %d properties, want the title property to be set in place", len(props))
+	}
+	if title, _ := props.Title(); title != "Second" {
+		t.Errorf("got title %q, want %q", title, "Second")
+	}
+
+	if err := props.SetTitle(""); err != nil {
+		t.Fatal(err)
+	}
+	if props[0].Title == nil || len(props[0].Title) != 0 {
+		t.Errorf("got title %v, want empty rich text that clears the title", props[0].Title)
+	}
+}
+
+func TestPropertySetTextSplits(t *testing.T) {
+	long := strings.Repeat("word ", 1000)
+	var props notion.PageProperties
+	if err := props.SetText("Notes", long); err != nil {
+		t.Fatal(err)
+	}
+
+	rts := props.Get("Notes").RichText
+	if len(rts) != 3 {
+		t.Errorf("got %d runs, want the text split into 3", len(rts))
+	}
+	for _, rt := range rts {
+		if notion.TextLength(rt.Text.Content) > notion.MaxTextLength {
+			t.Errorf("got a run of length %d, want at most %d", notion.TextLength(rt.Text.Content), notion.MaxTextLength)
+		}
+	}
+	if got := notion.PlainText(rts); got != long {
+		t.Errorf("got plain text of length %d, want the whole text of length %d", len(got), len(long))
+	}
+}
+
+func TestPropertyErrors(t *testing.T) {
+	props := notion.PageProperties{{Name: "Points", Type: notion.DatabasePropertyTypeEnumNumber}}
+
+	tests := []struct {
+		name     string
+		err      error
+		wantType notion.DatabasePropertyTypeEnum
+	}{
+		{name: "missing", err: func() error { _, err := props.Select("Status"); return err }()},
+		{name: "wrong type", err: func() error { _, err := props.Text("Points"); return err }(), wantType: notion.DatabasePropertyTypeEnumNumber},
+		{name: "set wrong type", err: props.SetCheckbox("Points", true), wantType: notion.DatabasePropertyTypeEnumNumber},
+		{name: "no title", err: func() error { _, err := props.Title(); return err }()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.err == nil {
+				t.Fatal("got no error")
+			}
+			var propErr *notion.PropertyError
+			if !errors.As(tt.err, &propErr) || propErr.Type != tt.wantType {
+				t.Errorf("got %v, want a PropertyError for a property of type %q", tt.err, tt.wantType)
+			}
+		})
+	}
+	if err := props.SetEmail("Email", "not an address"); err == nil || props.Get("Email") != nil {
+		t.Errorf("got error %v, want an error and no property for an invalid address", err)
+	}
+	if n, _ := props.Number("Points"); n != nil {
+		t.Errorf("got %v, want the number to not be set by the failed setter", *n)
+	}
+}
@@ -3,6 +3,7 @@ package notion
 import (
 	"encoding/json"
 	"net/url"
+	"strings"
 )
 
 // These constants represent the enums in the Notion API for rich_text objects
@@ -52,7 +53,7 @@ func (rtte *RichTextTypeEnum) IsValidEnum() bool {
 	return rtte != nil && isValidEnum(string(*rtte), RichTextTypeEnumText, RichTextTypeEnumMention, RichTextTypeEnumEquation)
 }
 
-// UnmarshalJSON returns an error if the provided string is not a valid RichTextTypeEnum
+// UnmarshalJSON sets the RichTextTypeEnum to the string, even if it is not valid in the Notion API. Use IsValidEnum to check it.
 func (rtte *RichTextTypeEnum) UnmarshalJSON(b []byte) error {
 	return unmarshalEnum(b, rtte)
 }
@@ -66,6 +67,33 @@ type RichText struct {
 	Text        *Text            `json:"text,omitempty"`
 	Mention     *Mention         `json:"mention,omitempty"`
 	Equation    *Equation        `json:"equation,omitempty"`
+
+	// Raw holds the JSON of rich text with a type that is not known to this package, so that it is marshaled unchanged.
+	Raw json.RawMessage `json:"-"`
+}
+
+type richText RichText
+
+// UnmarshalJSON unmarshals the rich text, keeping the JSON of rich text with an unknown type.
+func (rt *RichText) UnmarshalJSON(b []byte) error {
+	r := new(richText)
+	if err := json.Unmarshal(b, r); err != nil {
+		return err
+	}
+	if !r.Type.IsValidEnum() {
+		r.Raw = append(json.RawMessage(nil), b...)
+	}
+
+	*rt = RichText(*r)
+	return nil
+}
+
+// MarshalJSON marshals the rich text, using the original JSON for rich text with an unknown type.
+func (rt RichText) MarshalJSON() ([]byte, error) {
+	if rt.Raw != nil && !rt.Type.IsValidEnum() {
+		return rt.Raw, nil
+	}
+	return json.Marshal(richText(rt))
 }
 
 // AnnotationColorEnum represents the colors of a rich_text object in the Notion API.
@@ -102,7 +130,7 @@ func (ace *AnnotationColorEnum) IsValidEnum() bool {
 	)
 }
 
-// UnmarshalJSON returns an error if the provided string is not a valid AnnotationColorEnum
+// UnmarshalJSON sets the AnnotationColorEnum to the string, even if it is not valid in the Notion API. Use IsValidEnum to check it.
 func (ace *AnnotationColorEnum) UnmarshalJSON(b []byte) error {
 	return unmarshalEnum(b, ace)
 }
@@ -114,7 +142,7 @@ type Annotations struct {
 	Strikethrough bool                `json:"strikethrough"`
 	Underline     bool                `json:"underline"`
 	Code          bool                `json:"code"`
-	Color         AnnotationColorEnum `json:"color"`
+	Color         AnnotationColorEnum `json:"color,omitempty"`
 }
 
 // Text type represents a text rich_text object in the Notion API.
@@ -146,10 +174,18 @@ func (l *Link) MarshalJSON() ([]byte, error) {
 	}
 	return json.Marshal(map[string]interface{}{
 		"type": "url",
-		"url":  l.URL,
+		"url":  &l.URL,
 	})
 }
 
+// NewLink returns a Link to the given URL, to be used in a Text object.
+func NewLink(u *url.URL) *Link {
+	if u == nil {
+		return nil
+	}
+	return &Link{URL: jsonURL(*u)}
+}
+
 // MentionTypeEnum represents the valid mention types in the Notion API.
 type MentionTypeEnum string
 
@@ -165,7 +201,7 @@ func (mte *MentionTypeEnum) IsValidEnum() bool {
 	return mte != nil && isValidEnum(string(*mte), MentionTypeEnumUser, MentionTypeEnumPage, MentionTypeEnumDatabase, MentionTypeEnumData)
 }
 
-// UnmarshalJSON returns an error if the provided string is not a valid MentionTypeEnum
+// UnmarshalJSON sets the MentionTypeEnum to the string, even if it is not valid in the Notion API. Use IsValidEnum to check it.
 func (mte *MentionTypeEnum) UnmarshalJSON(b []byte) error {
 	return unmarshalEnum(b, mte)
 }
@@ -176,6 +212,9 @@ type Mention struct {
 	User *User           `json:"user,omitempty"`
 	Ref  *UUID4          `json:"id,omitempty"`
 	Date *Date           `json:"date,omitempty"`
+
+	// Raw holds the JSON of a mention with a type that is not known to this package, so that it is marshaled unchanged.
+	Raw json.RawMessage `json:"-"`
 }
 
 type mention Mention
@@ -199,6 +238,9 @@ func (m *Mention) UnmarshalJSON(b []byte) error {
 	if err := unmarshalJSONFlattenByType(b, mm); err != nil {
 		return err
 	}
+	if mm.Type != "" && !mm.Type.IsValidEnum() {
+		mm.Raw = append(json.RawMessage(nil), b...)
+	}
 
 	*m = Mention(*mm)
 	return nil
@@ -206,6 +248,9 @@ func (m *Mention) UnmarshalJSON(b []byte) error {
 
 // MarshalJSON marshals the Mention object to be compatible with the Notion API.
 func (m *Mention) MarshalJSON() ([]byte, error) {
+	if m.Raw != nil && !m.Type.IsValidEnum() {
+		return m.Raw, nil
+	}
 	mm := mention(*m)
 	return marshalJSONExpandByType(&mm)
 }
@@ -214,3 +259,73 @@ func (m *Mention) MarshalJSON() ([]byte, error) {
 type Equation struct {
 	Expression string `json:"expression"`
 }
+
+// MaxTextLength is the maximum length of the content of a text object in the Notion API.
+// Like in Javascript, the length is the number of UTF-16 code units.
+const MaxTextLength = 2000
+
+// PlainText returns the concatenated plain text of the rich text.
+func PlainText(rts []RichText) string {
+	var sb strings.Builder
+	for _, rt := range rts {
+		sb.WriteString(rt.PlainText)
+	}
+	return sb.String()
+}
+
+// FromPlainText returns the rich text for the plain text, split so that each run is at most MaxTextLength long.
+// The rich text of an empty string is empty, so that it clears a property.
+func FromPlainText(s string) []RichText {
+	if s == "" {
+		return []RichText{}
+	}
+	parts := SplitText(s)
+	rts := make([]RichText, 0, len(parts))
+	for _, part := range parts {
+		rts = append(rts, RichText{Type: RichTextTypeEnumText, PlainText: part, Text: &Text{Content: part}})
+	}
+	return rts
+}
+
+// TextLength returns the length of the string in the Notion API, in UTF-16 code units.
+func TextLength(s string) int {
+	n := 0
+	for _, r := range s {
+		n += runeLength(r)
+	}
+	return n
+}
+
+// runeLength returns the length of the rune in UTF-16 code units.
+// Runes outside of the Basic Multilingual Plane are encoded as a surrogate pair.
+func runeLength(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
+
+// SplitText splits the string into parts that are at most MaxTextLength long, without splitting a rune.
+// Parts end after whitespace where possible, so that words are not split.
+func SplitText(s string) []string {
+	var parts []string
+	for TextLength(s) > MaxTextLength {
+		end, n, lastSpace := 0, 0, -1
+		for i, r := range s {
+			if n+runeLength(r) > MaxTextLength {
+				break
+			}
+			n += runeLength(r)
+			end = i + len(string(r))
+			if r == ' ' || r == '\n' || r == '\t' {
+				lastSpace = end
+			}
+		}
+		if lastSpace > 0 {
+			end = lastSpace
+		}
+		parts = append(parts, s[:end])
+		s = s[end:]
+	}
+	return append(parts, s)
+}
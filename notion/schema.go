@@ -0,0 +1,321 @@
+package notion
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// A PropertyChange is a property that is in both schemas of a SchemaDiff, as it is in each.
+type PropertyChange struct {
+	From *DatabaseProperty
+	To   *DatabaseProperty
+}
+
+// An OptionsChange is a select or multi-select property whose options differ between the schemas of a SchemaDiff.
+// Options are matched by name, because their ids are different in each workspace.
+type OptionsChange struct {
+	PropertyChange
+	Added     []SelectOption
+	Removed   []SelectOption
+	Recolored []SelectOption
+}
+
+// A SchemaDiff holds the differences between the properties of two databases in the Notion API.
+//
+// Properties are matched by id, then by the rename hints of DiffSchemaWithRenames, then by name. The title properties are
+// always matched, because every database has exactly one.
+// A property that is matched by id or by a rename hint, but has a different name, is renamed.
+// The ids of properties differ between workspaces, so a property that has another name in another workspace is removed
+// and added, unless there is a rename hint for it. Removing a property removes its values from every page.
+// A matched property can be both renamed and retyped.
+type SchemaDiff struct {
+	Added   DatabaseProperties
+	Removed DatabaseProperties
+	Renamed []PropertyChange
+	Retyped []PropertyChange
+	// Reconfigured are the properties with the same type, but a different number format, formula expression, relation, or rollup.
+	Reconfigured []PropertyChange
+	Options      []OptionsChange
+}
+
+// DiffSchema returns the changes that turn the properties from into the properties to.
+func DiffSchema(from, to DatabaseProperties) *SchemaDiff {
+	return DiffSchemaWithRenames(from, to, nil)
+}
+
+// DiffSchemaWithRenames returns the changes that turn the properties from into the properties to, like DiffSchema.
+// The renames map the names of properties in from to the names of the same properties in to,
+// so that they are renamed instead of being removed and added.
+func DiffSchemaWithRenames(from, to DatabaseProperties, renames map[string]string) *SchemaDiff {
+	matches := make(map[*DatabaseProperty]*DatabaseProperty)
+	matched := make(map[*DatabaseProperty]bool)
+	match := func(find func(f, t *DatabaseProperty) bool) {
+		for _, t := range to {
+			if t == nil || matches[t] != nil {
+				continue
+			}
+			for _, f := range from {
+				if f != nil && !matched[f] && find(f, t) {
+					matches[t], matched[f] = f, true
+					break
+				}
+			}
+		}
+	}
+	match(func(f, t *DatabaseProperty) bool {
+		return f.Type == DatabasePropertyTypeEnumTitle && t.Type == DatabasePropertyTypeEnumTitle
+	})
+	match(func(f, t *DatabaseProperty) bool { return f.ID != "" && f.ID == t.ID })
+	match(func(f, t *DatabaseProperty) bool {
+		name, ok := renames[f.Name]
+		return ok && name == t.Name
+	})
+	match(func(f, t *DatabaseProperty) bool { return f.Name == t.Name })
+
+	sd := new(SchemaDiff)
+	for _, t := range to {
+		f := matches[t]
+		switch {
+		case t == nil:
+			continue
+		case f == nil:
+			sd.Added = append(sd.Added, t)
+			continue
+		case f.Name != t.Name:
+			sd.Renamed = append(sd.Renamed, PropertyChange{From: f, To: t})
+		}
+
+		if f.Type != t.Type {
+			sd.Retyped = append(sd.Retyped, PropertyChange{From: f, To: t})
+		} else if !sameConfiguration(f, t) {
+			sd.Reconfigured = append(sd.Reconfigured, PropertyChange{From: f, To: t})
+		} else if oc := diffOptions(f, t); oc != nil {
+			sd.Options = append(sd.Options, *oc)
+		}
+	}
+	for _, f := range from {
+		if f != nil && !matched[f] {
+			sd.Removed = append(sd.Removed, f)
+		}
+	}
+
+	// The properties of a database are unordered in the Notion API, so the changes are sorted by name.
+	sortProperties(sd.Added)
+	sortProperties(sd.Removed)
+	for _, pcs := range [][]PropertyChange{sd.Renamed, sd.Retyped, sd.Reconfigured} {
+		sortChanges(pcs)
+	}
+	sort.Slice(sd.Options, func(i, j int) bool {
+		return sd.Options[i].To.Name < sd.Options[j].To.Name
+	})
+	return sd
+}
+
+// IsEmpty returns true if there are no differences between the schemas.
+func (sd *SchemaDiff) IsEmpty() bool {
+	return sd == nil || len(sd.Added)+len(sd.Removed)+len(sd.Renamed)+len(sd.Retyped)+len(sd.Reconfigured)+len(sd.Options) == 0
+}
+
+// String returns the changes one per line, in a form that is meant to be read by people, for instance for a dry run.
+func (sd *SchemaDiff) String() string {
+	if sd == nil {
+		return ""
+	}
+
+	var lines []string
+	for _, p := range sd.Removed {
+		lines = append(lines, fmt.Sprintf("- %q (%s)", p.Name, p.Type))
+	}
+	for _, pc := range sd.Renamed {
+		lines = append(lines, fmt.Sprintf("~ %q renamed to %q", pc.From.Name, pc.To.Name))
+	}
+	for _, pc := range sd.Retyped {
+		lines = append(lines, fmt.Sprintf("~ %q retyped from %s to %s", pc.To.Name, pc.From.Type, pc.To.Type))
+	}
+	for _, pc := range sd.Reconfigured {
+		lines = append(lines, fmt.Sprintf("~ %q (%s) reconfigured", pc.To.Name, pc.To.Type))
+	}
+	for _, oc := range sd.Options {
+		var changes []string
+		for _, o := range oc.Added {
+			changes = append(changes, fmt.Sprintf("+%q", o.Name))
+		}
+		for _, o := range oc.Removed {
+			changes = append(changes, fmt.Sprintf("-%q", o.Name))
+		}
+		for _, o := range oc.Recolored {
+			changes = append(changes, fmt.Sprintf("%q is %s", o.Name, o.Color))
+		}
+		lines = append(lines, fmt.Sprintf("~ %q options: %s", oc.To.Name, strings.Join(changes, ", ")))
+	}
+	for _, p := range sd.Added {
+		lines = append(lines, fmt.Sprintf("+ %q (%s)", p.Name, p.Type))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// A SchemaUpdate holds the properties of a request to update a database in the Notion API, by the name or id of the property.
+// A nil value removes the property.
+type SchemaUpdate map[string]interface{}
+
+// Updates returns the updates to apply the diff to the database with the properties that the diff is from.
+//
+// Properties are removed and renamed in the first update, and changed or added in the second,
+// so that a property can be added with the name of a property that is removed or renamed.
+// The options and relations of added and retyped properties are those of the database that the diff is to,
+// and relations refer to databases by id, which differ between workspaces.
+func (sd *SchemaDiff) Updates() ([]SchemaUpdate, error) {
+	if sd.IsEmpty() {
+		return nil, nil
+	}
+
+	first, second := SchemaUpdate{}, SchemaUpdate{}
+	for _, p := range sd.Removed {
+		first[propertyKey(p, p.Name)] = nil
+	}
+	for _, pc := range sd.Renamed {
+		first[propertyKey(pc.From, pc.From.Name)] = map[string]interface{}{"name": pc.To.Name}
+	}
+
+	changed := append(append([]PropertyChange{}, sd.Retyped...), sd.Reconfigured...)
+	for _, pc := range changed {
+		schema, err := propertySchema(pc.To, pc.From.SelectOptions)
+		if err != nil {
+			return nil, err
+		}
+		second[propertyKey(pc.From, pc.To.Name)] = schema
+	}
+	for _, oc := range sd.Options {
+		schema, err := propertySchema(oc.To, oc.From.SelectOptions)
+		if err != nil {
+			return nil, err
+		}
+		second[propertyKey(oc.From, oc.To.Name)] = schema
+	}
+	for _, p := range sd.Added {
+		schema, err := propertySchema(p, nil)
+		if err != nil {
+			return nil, err
+		}
+		second[p.Name] = schema
+	}
+
+	var updates []SchemaUpdate
+	for _, u := range []SchemaUpdate{first, second} {
+		if len(u) != 0 {
+			updates = append(updates, u)
+		}
+	}
+	return updates, nil
+}
+
+// propertyKey returns the key of the property in an update: its id, if it has one, and the name otherwise.
+func propertyKey(p *DatabaseProperty, name string) string {
+	if p.ID != "" {
+		return p.ID
+	}
+	return name
+}
+
+// propertySchema returns the schema of the property for an update, keyed by its type.
+// The ids of the options are taken from the existing options with the same name, because the ids of other databases are not valid.
+func propertySchema(p *DatabaseProperty, existing []SelectOption) (map[string]interface{}, error) {
+	c := *p
+	c.ID = ""
+	c.SelectOptions = make([]SelectOption, 0, len(p.SelectOptions))
+	for _, o := range p.SelectOptions {
+		o.ID = ""
+		for _, e := range existing {
+			if e.Name == o.Name {
+				o.ID = e.ID
+				break
+			}
+		}
+		c.SelectOptions = append(c.SelectOptions, o)
+	}
+
+	b, err := json.Marshal(&c)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]interface{})
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{string(p.Type): m[string(p.Type)]}, nil
+}
+
+// sameConfiguration returns true if the configuration of the property to, where it is set, is the same as that of from.
+// Relations and rollups are compared by the names of properties, because the ids are different in each workspace.
+func sameConfiguration(from, to *DatabaseProperty) bool {
+	switch {
+	case to.NumberFormat != nil && (from.NumberFormat == nil || *from.NumberFormat != *to.NumberFormat):
+		return false
+	case to.FormulaExpression != nil && (from.FormulaExpression == nil || *from.FormulaExpression != *to.FormulaExpression):
+		return false
+	case to.Relation != nil && (from.Relation == nil || stringValue(from.Relation.SyncedPropertyName) != stringValue(to.Relation.SyncedPropertyName)):
+		return false
+	case to.Rollup != nil && (from.Rollup == nil ||
+		from.Rollup.RelationPropertyName != to.Rollup.RelationPropertyName ||
+		from.Rollup.RollupPropertyName != to.Rollup.RollupPropertyName ||
+		from.Rollup.Function != to.Rollup.Function):
+		return false
+	}
+	return true
+}
+
+// diffOptions returns the changes to the options of a select or multi-select property, or nil if there are none.
+// An option without a color in to is not recolored.
+func diffOptions(from, to *DatabaseProperty) *OptionsChange {
+	if to.Type != DatabasePropertyTypeEnumSelect && to.Type != DatabasePropertyTypeEnumMultiSelect {
+		return nil
+	}
+
+	oc := &OptionsChange{PropertyChange: PropertyChange{From: from, To: to}}
+	fromOptions := make(map[string]SelectOption, len(from.SelectOptions))
+	for _, o := range from.SelectOptions {
+		fromOptions[o.Name] = o
+	}
+	toOptions := make(map[string]bool, len(to.SelectOptions))
+	for _, o := range to.SelectOptions {
+		toOptions[o.Name] = true
+		f, ok := fromOptions[o.Name]
+		switch {
+		case !ok:
+			oc.Added = append(oc.Added, o)
+		case o.Color != "" && o.Color != f.Color:
+			oc.Recolored = append(oc.Recolored, o)
+		}
+	}
+	for _, o := range from.SelectOptions {
+		if !toOptions[o.Name] {
+			oc.Removed = append(oc.Removed, o)
+		}
+	}
+
+	if len(oc.Added)+len(oc.Removed)+len(oc.Recolored) == 0 {
+		return nil
+	}
+	return oc
+}
+
+func sortProperties(dbps DatabaseProperties) {
+	sort.Slice(dbps, func(i, j int) bool {
+		return dbps[i].Name < dbps[j].Name
+	})
+}
+
+func sortChanges(pcs []PropertyChange) {
+	sort.Slice(pcs, func(i, j int) bool {
+		return pcs[i].To.Name < pcs[j].To.Name
+	})
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,209 @@
+package notion_test
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/thedadams/gotion/notion"
+)
+
+// names returns the names of the properties of each change, as "from>to" for changes of matched properties.
+func names(sd *notion.SchemaDiff) map[string][]string {
+	got := make(map[string][]string)
+	for _, p := range sd.Added {
+		got["added"] = append(got["added"], p.Name)
+	}
+	for _, p := range sd.Removed {
+		got["removed"] = append(got["removed"], p.Name)
+	}
+	for kind, pcs := range map[string][]notion.PropertyChange{"renamed": sd.Renamed, "retyped": sd.Retyped, "reconfigured": sd.Reconfigured} {
+		for _, pc := range pcs {
+			got[kind] = append(got[kind], pc.From.Name+">"+pc.To.Name)
+		}
+	}
+	for _, oc := range sd.Options {
+		got["options"] = append(got["options"], oc.From.Name+">"+oc.To.Name)
+	}
+	return got
+}
+
+func TestDiffSchema(t *testing.T) {
+	percent := notion.NumberConfigurationTypeEnum("percent")
+	tests := []struct {
+		name    string
+		from    notion.DatabaseProperties
+		to      notion.DatabaseProperties
+		renames map[string]string
+		want    map[string][]string
+	}{
+		{
+			name: "same",
+			from: notion.DatabaseProperties{{Name: "Name", Type: notion.DatabasePropertyTypeEnumTitle}},
+			to:   notion.DatabaseProperties{{Name: "Name", Type: notion.DatabasePropertyTypeEnumTitle}},
+			want: map[string][]string{},
+		},
+		{
+			name: "title by type",
+			from: notion.DatabaseProperties{{Name: "Name", ID: "title", Type: notion.DatabasePropertyTypeEnumTitle}},
+			to:   notion.DatabaseProperties{{Name: "Task", Type: notion.DatabasePropertyTypeEnumTitle}},
+			want: map[string][]string{"renamed": {"Name>Task"}},
+		},
+		{
+			name: "by id",
+			from: notion.DatabaseProperties{{Name: "Owner", ID: "a1", Type: notion.DatabasePropertyTypeEnumPeople}},
+			to:   notion.DatabaseProperties{{Name: "Assignee", ID: "a1", Type: notion.DatabasePropertyTypeEnumPeople}},
+			want: map[string][]string{"renamed": {"Owner>Assignee"}},
+		},
+		{
+			name: "by name across workspaces",
+			from: notion.DatabaseProperties{{Name: "Owner", ID: "a1", Type: notion.DatabasePropertyTypeEnumPeople}},
+			to:   notion.DatabaseProperties{{Name: "Owner", ID: "b2", Type: notion.DatabasePropertyTypeEnumPeople}},
+			want: map[string][]string{},
+		},
+		{
+			name: "other name without hint",
+			from: notion.DatabaseProperties{{Name: "Owner", ID: "a1", Type: notion.DatabasePropertyTypeEnumPeople}},
+			to:   notion.DatabaseProperties{{Name: "Assignee", ID: "b2", Type: notion.DatabasePropertyTypeEnumPeople}},
+			want: map[string][]string{"added": {"Assignee"}, "removed": {"Owner"}},
+		},
+		{
+			name:    "rename hint",
+			from:    notion.DatabaseProperties{{Name: "Owner", ID: "a1", Type: notion.DatabasePropertyTypeEnumPeople}},
+			to:      notion.DatabaseProperties{{Name: "Assignee", ID: "b2", Type: notion.DatabasePropertyTypeEnumPeople}},
+			renames: map[string]string{"Owner": "Assignee"},
+			want:    map[string][]string{"renamed": {"Owner>Assignee"}},
+		},
+		{
+			name: "id before name",
+			from: notion.DatabaseProperties{
+				{Name: "Notes", ID: "a1", Type: notion.DatabasePropertyTypeEnumRichText},
+				{Name: "Summary", ID: "a2", Type: notion.DatabasePropertyTypeEnumRichText},
+			},
+			to: notion.DatabaseProperties{
+				{Name: "Summary", ID: "a1", Type: notion.DatabasePropertyTypeEnumRichText},
+				{Name: "Notes", ID: "a2", Type: notion.DatabasePropertyTypeEnumRichText},
+			},
+			want: map[string][]string{"renamed": {"Summary>Notes", "Notes>Summary"}},
+		},
+		{
+			name: "renamed and retyped",
+			from: notion.DatabaseProperties{{Name: "Points", ID: "a1", Type: notion.DatabasePropertyTypeEnumRichText}},
+			to:   notion.DatabaseProperties{{Name: "Estimate", ID: "a1", Type: notion.DatabasePropertyTypeEnumNumber}},
+			want: map[string][]string{"renamed": {"Points>Estimate"}, "retyped": {"Points>Estimate"}},
+		},
+		{
+			name: "reconfigured",
+			from: notion.DatabaseProperties{{Name: "Done", Type: notion.DatabasePropertyTypeEnumNumber}},
+			to:   notion.DatabaseProperties{{Name: "Done", Type: notion.DatabasePropertyTypeEnumNumber, NumberFormat: &percent}},
+			want: map[string][]string{"reconfigured": {"Done>Done"}},
+		},
+		{
+			name: "options",
+			from: notion.DatabaseProperties{{Name: "Status", Type: notion.DatabasePropertyTypeEnumSelect, SelectOptions: []notion.SelectOption{
+				{Name: "To do", Color: "red"}, {Name: "Done", Color: "green"},
+			}}},
+			to: notion.DatabaseProperties{{Name: "Status", Type: notion.DatabasePropertyTypeEnumSelect, SelectOptions: []notion.SelectOption{
+				{Name: "To do"}, {Name: "Done", Color: "blue"}, {Name: "Doing"},
+			}}},
+			want: map[string][]string{"options": {"Status>Status"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sd := notion.DiffSchemaWithRenames(tt.from, tt.to, tt.renames)
+			got := names(sd)
+			for _, v := range got {
+				sort.Strings(v)
+			}
+			for _, v := range tt.want {
+				sort.Strings(v)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v\n%s", got, tt.want, sd)
+			}
+			if sd.IsEmpty() != (len(tt.want) == 0) {
+				t.Errorf("got IsEmpty %v for changes %v", sd.IsEmpty(), got)
+			}
+		})
+	}
+}
+
+func TestDiffSchemaOptions(t *testing.T) {
+	from := notion.DatabaseProperties{{Name: "Status", Type: notion.DatabasePropertyTypeEnumSelect, SelectOptions: []notion.SelectOption{
+		{Name: "To do", Color: "red"}, {Name: "Done", Color: "green"},
+	}}}
+	to := notion.DatabaseProperties{{Name: "Status", Type: notion.DatabasePropertyTypeEnumSelect, SelectOptions: []notion.SelectOption{
+		{Name: "To do"}, {Name: "Done", Color: "blue"}, {Name: "Doing"},
+	}}}
+
+	sd := notion.DiffSchema(from, to)
+	if len(sd.Options) != 1 {
+		t.Fatalf("got %d options changes, want 1", len(sd.Options))
+	}
+	oc := sd.Options[0]
+	if len(oc.Added) != 1 || oc.Added[0].Name != "Doing" {
+		t.Errorf("got added options %v, want Doing", oc.Added)
+	}
+	if len(oc.Removed) != 0 {
+		t.Errorf("got removed options %v, want none", oc.Removed)
+	}
+	// An option without a color is not recolored.
+	if len(oc.Recolored) != 1 || oc.Recolored[0].Name != "Done" {
+		t.Errorf("got recolored options %v, want Done", oc.Recolored)
+	}
+}
+
+func TestSchemaUpdates(t *testing.T) {
+	from := notion.DatabaseProperties{
+		{Name: "Name", ID: "title", Type: notion.DatabasePropertyTypeEnumTitle},
+		{Name: "Owner", ID: "a1", Type: notion.DatabasePropertyTypeEnumPeople},
+		{Name: "Notes", ID: "a2", Type: notion.DatabasePropertyTypeEnumRichText},
+		{Name: "Status", ID: "a3", Type: notion.DatabasePropertyTypeEnumSelect, SelectOptions: []notion.SelectOption{
+			{Name: "To do", ID: "o1", Color: "red"}, {Name: "Done", ID: "o2", Color: "green"},
+		}},
+	}
+	// The properties come from another workspace, so their ids and the ids of their options are different.
+	to := notion.DatabaseProperties{
+		{Name: "Name", ID: "title", Type: notion.DatabasePropertyTypeEnumTitle},
+		{Name: "Assignee", ID: "b1", Type: notion.DatabasePropertyTypeEnumPeople},
+		{Name: "Owner", ID: "b2", Type: notion.DatabasePropertyTypeEnumRichText},
+		{Name: "Status", ID: "b3", Type: notion.DatabasePropertyTypeEnumSelect, SelectOptions: []notion.SelectOption{
+			{Name: "To do", ID: "x1", Color: "red"}, {Name: "Done", ID: "x2", Color: "blue"},
+		}},
+	}
+
+	updates, err := notion.DiffSchemaWithRenames(from, to, map[string]string{"Owner": "Assignee"}).Updates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updates) != 2 {
+		t.Fatalf("got %d updates, want 2: %v", len(updates), updates)
+	}
+
+	// The first update frees the name Owner, which the second update adds again.
+	want := notion.SchemaUpdate{"a1": map[string]interface{}{"name": "Assignee"}, "a2": nil}
+	if !reflect.DeepEqual(updates[0], want) {
+		t.Errorf("got first update %v, want %v", updates[0], want)
+	}
+	if len(updates[1]) != 2 || updates[1]["Owner"] == nil {
+		t.Errorf("got second update %v, want Owner added and Status changed", updates[1])
+	}
+
+	status, ok := updates[1]["a3"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("got %v for Status, want it changed by its id", updates[1]["a3"])
+	}
+	want = notion.SchemaUpdate{"select": map[string]interface{}{"options": []interface{}{
+		map[string]interface{}{"name": "To do", "id": "o1", "color": "red"},
+		map[string]interface{}{"name": "Done", "id": "o2", "color": "blue"},
+	}}}
+	if !reflect.DeepEqual(notion.SchemaUpdate(status), want) {
+		t.Errorf("got %v for Status, want the ids of the existing options", status)
+	}
+
+	if updates, err := notion.DiffSchema(from, from).Updates(); err != nil || updates != nil {
+		t.Errorf("got %v and error %v for an empty diff, want no updates", updates, err)
+	}
+}
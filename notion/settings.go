@@ -22,11 +22,13 @@ func LatestWithAPIKey(apiKey string) *Settings {
 
 // ToHeaders attaches the appropriate header information to the request.
 func (s *Settings) ToHeaders(req *http.Request) {
-	if s.Version == "" {
-		s.Version = LatestWithAPIKey("").Version
+	// The settings are shared by concurrent requests, so the default version is not written back to them.
+	v := s.Version
+	if v == "" {
+		v = LatestWithAPIKey("").Version
 	}
 	req.Header.Set("Authorization", "Bearer "+s.APIKey)
-	req.Header.Set("Notion-Version", s.Version)
+	req.Header.Set("Notion-Version", v)
 	if s.UserAgent != "" {
 		req.Header.Set("User-Agent", s.UserAgent)
 	}
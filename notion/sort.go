@@ -27,7 +27,7 @@ func (ste *SortTimestampEnum) IsValidEnum() bool {
 	return isValidEnum(string(*ste), SortTimestampEnumCreatedTime, SortTimestampEnumLastEditedTime)
 }
 
-// UnmarshalJSON returns an error if the string does not represent a valid SortTimestampEnum in the Notion API.
+// UnmarshalJSON sets the SortTimestampEnum to the string, even if it is not valid in the Notion API. Use IsValidEnum to check it.
 func (ste *SortTimestampEnum) UnmarshalJSON(b []byte) error {
 	return unmarshalEnum(b, ste)
 }
@@ -47,7 +47,7 @@ func (sde *SortDirectionEnum) IsValidEnum() bool {
 	return sde != nil && isValidEnum(string(*sde), SortDirectionEnumAscending, SortDirectionEnumDescending)
 }
 
-// UnmarshalJSON returns an error if the string does not represent a valid SortDirectionEnum in the Notion API.
+// UnmarshalJSON sets the SortDirectionEnum to the string, even if it is not valid in the Notion API. Use IsValidEnum to check it.
 func (sde *SortDirectionEnum) UnmarshalJSON(b []byte) error {
 	return unmarshalEnum(b, sde)
 }
@@ -78,3 +78,56 @@ func NewTimestampSort(t SortTimestampEnum, d SortDirectionEnum) (*Sort, error) {
 
 	return &Sort{Timestamp: &t, Direction: &d}, nil
 }
+
+// A SortBuilder builds the ordered list of sorts used to query a database in the Notion API.
+// Sorts are applied in the order they are added, so earlier sorts take precedence.
+// The first invalid sort stops the building, and its error is returned by Build.
+type SortBuilder struct {
+	sorts []*Sort
+	err   error
+}
+
+// NewSortBuilder returns an empty SortBuilder.
+func NewSortBuilder() *SortBuilder {
+	return &SortBuilder{}
+}
+
+// Property adds a sort on the property with the given name and direction.
+func (sb *SortBuilder) Property(name string, d SortDirectionEnum) *SortBuilder {
+	if sb.err == nil {
+		var s *Sort
+		if s, sb.err = NewPropertySort(name, d); sb.err == nil {
+			sb.sorts = append(sb.sorts, s)
+		}
+	}
+	return sb
+}
+
+// Timestamp adds a sort on the given timestamp, created_time or last_edited_time, and direction.
+func (sb *SortBuilder) Timestamp(t SortTimestampEnum, d SortDirectionEnum) *SortBuilder {
+	if sb.err == nil {
+		var s *Sort
+		if s, sb.err = NewTimestampSort(t, d); sb.err == nil {
+			sb.sorts = append(sb.sorts, s)
+		}
+	}
+	return sb
+}
+
+// Ascending adds an ascending sort on the property with the given name.
+func (sb *SortBuilder) Ascending(name string) *SortBuilder {
+	return sb.Property(name, SortDirectionEnumAscending)
+}
+
+// Descending adds a descending sort on the property with the given name.
+func (sb *SortBuilder) Descending(name string) *SortBuilder {
+	return sb.Property(name, SortDirectionEnumDescending)
+}
+
+// Build returns the sorts in the order they were added, or the error of the first invalid sort.
+func (sb *SortBuilder) Build() ([]*Sort, error) {
+	if sb.err != nil {
+		return nil, sb.err
+	}
+	return append([]*Sort{}, sb.sorts...), nil
+}
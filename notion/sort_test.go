@@ -0,0 +1,53 @@
+package notion_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/thedadams/gotion/notion"
+)
+
+func TestSortJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder *notion.SortBuilder
+		want    string
+		wantErr bool
+	}{
+		{name: "none", builder: notion.NewSortBuilder(), want: `[]`},
+		{
+			name: "in order",
+			builder: notion.NewSortBuilder().
+				Descending("Priority").
+				Timestamp(notion.SortTimestampEnumCreatedTime, notion.SortDirectionEnumAscending).
+				Ascending("Name"),
+			want: `[{"property":"Priority","direction":"descending"},{"timestamp":"created_time","direction":"ascending"},` +
+				`{"property":"Name","direction":"ascending"}]`,
+		},
+		{name: "invalid direction", builder: notion.NewSortBuilder().Ascending("Name").Property("Priority", "sideways"), wantErr: true},
+		{name: "invalid timestamp", builder: notion.NewSortBuilder().Timestamp("edited", notion.SortDirectionEnumAscending), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sorts, err := tt.builder.Build()
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("got %d sorts, want an error", len(sorts))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			b, err := json.Marshal(sorts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != tt.want {
+				t.Errorf("got %s, want %s", b, tt.want)
+			}
+		})
+	}
+}
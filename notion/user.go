@@ -23,7 +23,7 @@ func (ute *UserTypeEnum) IsValidEnum() bool {
 	return ute != nil && isValidEnum(string(*ute), UserTypeEnumPerson, UserTypeEnumBot)
 }
 
-// UnmarshalJSON returns an error if the provided string is not a valid UserTypeEnum in the Notion API.
+// UnmarshalJSON sets the UserTypeEnum to the string, even if it is not valid in the Notion API. Use IsValidEnum to check it.
 func (ute *UserTypeEnum) UnmarshalJSON(b []byte) error {
 	return unmarshalEnum(b, ute)
 }
@@ -35,7 +35,10 @@ type User struct {
 	Name      string       `json:"name,omitempty"`
 	AvatarURL jsonURL      `json:"avatar_url,omitempty"`
 	// Only set if the Type is "person"
-	Email string `json:"email"`
+	Email string `json:"email,omitempty"`
+
+	// Raw holds the JSON of a user with a type that is not known to this package, so that it is marshaled unchanged.
+	Raw json.RawMessage `json:"-"`
 }
 
 type user User
@@ -59,6 +62,9 @@ func (u *User) UnmarshalJSON(b []byte) error {
 	if err := unmarshalJSONFlattenByType(b, uu); err != nil {
 		return err
 	}
+	if uu.Type != "" && !uu.Type.IsValidEnum() {
+		uu.Raw = append(json.RawMessage(nil), b...)
+	}
 
 	*u = User(*uu)
 	return nil
@@ -69,6 +75,9 @@ func (u *User) MarshalJSON() ([]byte, error) {
 	if u == nil {
 		return nil, nil
 	}
+	if u.Raw != nil && !u.Type.IsValidEnum() {
+		return u.Raw, nil
+	}
 	uu := user(*u)
 	return marshalJSONExpandByType(&uu)
 }
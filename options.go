@@ -1,6 +1,8 @@
 package gotion
 
 import (
+	"net/http"
+	"strings"
 	"time"
 
 	"github.com/sethgrid/pester"
@@ -13,16 +15,55 @@ const (
 	defaultMaxRetries = 8
 )
 
-var defaultRateLimiter = rate.NewLimiter(rate.Every(time.Second), 3)
-
 // An Option is a way of customizing the gotion client
 type Option func(*Client)
 
-// WithPesterClient uses the given pester client for the gotion client
+// WithPesterClient uses the settings of the given pester client for the gotion client.
+// The settings are copied, so that the given client is not changed by this or other options.
+// Retries on 429 errors are handled by the gotion client, so RetryOnHTTP429 is turned off on the copy.
+// The http.Client of a client from pester.NewExtendedClient is not copied; use WithHTTPClient for that instead.
 func WithPesterClient(pesterClient *pester.Client) Option {
+	return func(c *Client) {
+		if c != nil && pesterClient != nil {
+			pc := pester.New()
+			pc.Transport = pesterClient.Transport
+			pc.CheckRedirect = pesterClient.CheckRedirect
+			pc.Jar = pesterClient.Jar
+			pc.Timeout = pesterClient.Timeout
+			pc.Concurrency = pesterClient.Concurrency
+			pc.MaxRetries = pesterClient.MaxRetries
+			pc.Backoff = pesterClient.Backoff
+			pc.KeepLog = pesterClient.KeepLog
+			pc.LogHook = pesterClient.LogHook
+			pc.ContextLogHook = pesterClient.ContextLogHook
+			pc.RetryOnHTTP429 = false
+			c.httpClient = pc
+		}
+	}
+}
+
+// WithHTTPClient sends the requests of the gotion client through the given http.Client.
+// The transport, redirect policy, and cookie jar of the given client are used, as well as its timeout if it is set.
+// Retries are still handled by the gotion client, so the given client should not retry requests itself.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		if c != nil && hc != nil {
+			c.httpClient.Transport = hc.Transport
+			c.httpClient.CheckRedirect = hc.CheckRedirect
+			c.httpClient.Jar = hc.Jar
+			if hc.Timeout != 0 {
+				c.httpClient.Timeout = hc.Timeout
+			}
+		}
+	}
+}
+
+// WithTransport sends the requests of the gotion client through the given http.RoundTripper.
+// This is useful for proxies, recording requests, or mocking the Notion API.
+func WithTransport(rt http.RoundTripper) Option {
 	return func(c *Client) {
 		if c != nil {
-			c.httpClient = pesterClient
+			c.httpClient.Transport = rt
 		}
 	}
 }
@@ -45,6 +86,16 @@ func WithToken(t string) Option {
 	}
 }
 
+// WithBaseURL sends the requests of the gotion client to the given URL instead of https://api.notion.com.
+// This is useful for proxies and for testing, for example with the gotiontest package.
+func WithBaseURL(u string) Option {
+	return func(c *Client) {
+		if c != nil {
+			c.baseURL = strings.TrimSuffix(u, "/")
+		}
+	}
+}
+
 // WithUserAgent uses the given user agent string with the gotion client.
 func WithUserAgent(u string) Option {
 	return func(c *Client) {
@@ -70,11 +121,13 @@ func WithAPIVersion(v string) Option {
 	}
 }
 
-// WithRateLimiter users the given rate limiter with the gotion client.
+// WithRateLimiter uses the given rate limiter with the gotion client.
+// The limit of the rate limiter is lowered when the Notion API rate limits the client, and raised back up as requests succeed,
+// so the rate limiter should not be shared with other clients.
 func WithRateLimiter(r *rate.Limiter) Option {
 	return func(c *Client) {
 		if c != nil {
-			c.rateLimiter = r
+			c.limiter = newAdaptiveLimiter(r)
 		}
 	}
 }
@@ -88,22 +141,45 @@ func WithBackoffStrategy(b pester.BackoffStrategy) Option {
 	}
 }
 
-// WithRetryOnHTTP429 sets the gotion client to retry on 429 errors.
-// The rate limiter should help with this as well.
+// WithRetryOnHTTP429 sets the gotion client to retry on 429 errors, up to the maximum number of retries.
+// Before retrying, all requests of the client wait for the time given by the Retry-After header.
 func WithRetryOnHTTP429() Option {
 	return func(c *Client) {
 		if c != nil {
-			c.httpClient.RetryOnHTTP429 = true
+			c.retryOnHTTP429 = true
 		}
 	}
 }
 
 // WithoutRetryOnHTTP429 sets the gotion client to NOT retry on 429 errors.
-// The rate limiter should help with this as well.
+// Other requests of the client still wait for the time given by the Retry-After header.
 func WithoutRetryOnHTTP429() Option {
 	return func(c *Client) {
 		if c != nil {
-			c.httpClient.RetryOnHTTP429 = false
+			c.retryOnHTTP429 = false
+		}
+	}
+}
+
+// WithStrictDecoding sets the gotion client to return an error when a response from the Notion API has an enum value
+// that is not known to the notion package, like a new color or property type. This is the default.
+func WithStrictDecoding() Option {
+	return func(c *Client) {
+		if c != nil {
+			c.lenientDecoding = false
+		}
+	}
+}
+
+// WithLenientDecoding sets the gotion client to keep enum values that are not known to the notion package, instead of
+// returning an error, so that new values in the Notion API don't break the client. Objects with an unknown type,
+// like blocks, properties, mentions, and files, keep their JSON in their Raw field so that they are marshaled unchanged.
+// Each unknown value is reported to warn, if it is not nil.
+func WithLenientDecoding(warn func(*notion.InvalidEnumError)) Option {
+	return func(c *Client) {
+		if c != nil {
+			c.lenientDecoding = true
+			c.onUnknownEnum = warn
 		}
 	}
 }
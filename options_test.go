@@ -0,0 +1,88 @@
+package gotion_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/thedadams/gotion"
+	"github.com/thedadams/gotion/gotiontest"
+	"github.com/thedadams/gotion/notion"
+)
+
+func TestDecoding(t *testing.T) {
+	const icon = `{"type":"custom_emoji","custom_emoji":{"id":"45ce454c-d427-4f53-9489-e5d0f3d1db6b","name":"party"}}`
+
+	tests := []struct {
+		name     string
+		options  []gotion.Option
+		wantErr  bool
+		wantWarn int
+	}{
+		{name: "strict by default", wantErr: true},
+		{name: "strict", options: []gotion.Option{gotion.WithStrictDecoding()}, wantErr: true},
+		{name: "lenient", options: []gotion.Option{gotion.WithLenientDecoding(nil)}},
+		{name: "lenient with warnings", wantWarn: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := gotiontest.NewServer()
+			defer s.Close()
+			page, err := s.AddPage(&notion.Page{
+				Parent: notion.Parent{Type: notion.ParentTypeEnumWorkspace},
+				Icons:  notion.Icons{Icon: &notion.Icon{Type: "custom_emoji", Raw: json.RawMessage(icon)}},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var warnings []*notion.InvalidEnumError
+			options := tt.options
+			if tt.wantWarn != 0 {
+				options = append(options, gotion.WithLenientDecoding(func(err *notion.InvalidEnumError) {
+					warnings = append(warnings, err)
+				}))
+			}
+
+			got, err := s.NewClient(options...).GetPage(context.Background(), page.ID.String())
+			if tt.wantErr {
+				var enumErr *notion.InvalidEnumError
+				if !errors.As(err, &enumErr) {
+					t.Fatalf("got error %v, want an *notion.InvalidEnumError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(warnings) != tt.wantWarn {
+				t.Errorf("got %d warnings, want %d", len(warnings), tt.wantWarn)
+			}
+
+			// The icon of the unknown type is marshaled unchanged.
+			b, err := json.Marshal(got.Icon)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !jsonEqual(t, b, []byte(icon)) {
+				t.Errorf("got icon %s, want %s", b, icon)
+			}
+		})
+	}
+}
+
+func jsonEqual(t *testing.T, a, b []byte) bool {
+	t.Helper()
+	var va, vb interface{}
+	if err := json.Unmarshal(a, &va); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(b, &vb); err != nil {
+		t.Fatal(err)
+	}
+	ja, _ := json.Marshal(va)
+	jb, _ := json.Marshal(vb)
+	return string(ja) == string(jb)
+}
@@ -12,7 +12,7 @@ import (
 // To get the contents of a page, use `GetPageWithChildren` with the page id.
 func (c *Client) GetPage(ctx context.Context, id string) (*notion.Page, error) {
 	page := &notion.Page{}
-	err := c.makeRequest(ctx, http.MethodGet, fmt.Sprintf("%s/v1/pages/%s", apiBaseURL, id), nil, page)
+	err := c.makeRequest(ctx, http.MethodGet, fmt.Sprintf("%s/v1/pages/%s", c.baseURL, id), nil, page)
 	if err != nil {
 		page = nil
 	}
@@ -32,8 +32,21 @@ func (c *Client) GetPageAndChildren(ctx context.Context, id string, maxResults i
 	return page, err
 }
 
+// GetPageTree gets a page with the given id from the Notion API, as well as its whole tree of children.
+// See `GetBlockTree` for the meaning of `maxDepth` and `workers`.
+func (c *Client) GetPageTree(ctx context.Context, id string, maxDepth, workers int) (*notion.Page, error) {
+	page, err := c.GetPage(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	page.Children, err = c.GetBlockTree(ctx, id, maxDepth, workers)
+	return page, err
+}
+
 // CreatePage will send a request to create the given page in the Notion API.
 // All that is needed in the notion.Page object are the Parent, Properties, and Children.
+// Optionally, an Icon and Cover can be set.
 // No IDs need to be given.
 // On success, the notion.Page returned will be the complete page from the Notion API.
 // On error, the notion.Page returned is the original one.
@@ -42,8 +55,12 @@ func (c *Client) CreatePage(ctx context.Context, page *notion.Page) (*notion.Pag
 		"parent":     &page.Parent,
 		"properties": &page.Properties,
 	}
+	if len(page.Children) != 0 {
+		body["children"] = &page.Children
+	}
+	addIcons(body, &page.Icons)
 
-	return page, c.createObject(ctx, fmt.Sprintf("%s/v1/pages", apiBaseURL), body, page)
+	return page, c.createObject(ctx, fmt.Sprintf("%s/v1/pages", c.baseURL), body, page)
 }
 
 // UpdatePageProperties updates the page properties in the Notion API.
@@ -53,7 +70,109 @@ func (c *Client) CreatePage(ctx context.Context, page *notion.Page) (*notion.Pag
 // On success, the notion.Page will be the complete page from the Notion API.
 // On error, the notion.Page will be changed.
 func (c *Client) UpdatePageProperties(ctx context.Context, page *notion.Page) error {
-	body := map[string]interface{}{"properties": page.Properties}
+	body := map[string]interface{}{"properties": &page.Properties}
+
+	return c.updateObject(ctx, fmt.Sprintf("%s/v1/pages/%s", c.baseURL, page.ID.String()), body, page)
+}
 
-	return c.updateObject(ctx, fmt.Sprintf("%s/v1/pages/%s", apiBaseURL, page.ID), body, page)
+// An UpdatePageOption customizes how UpdatePage updates a page.
+type UpdatePageOption func(*updatePageOptions)
+
+type updatePageOptions struct {
+	archived *bool
+}
+
+// SetArchived lets UpdatePage archive the page, or restore it if archived is false, along with the other updates.
+func SetArchived(archived bool) UpdatePageOption {
+	return func(o *updatePageOptions) {
+		o.archived = &archived
+	}
+}
+
+// UpdatePage updates the page in the Notion API: its properties, if any are given, and its icon and cover, if they are not nil.
+// An icon or cover without a type is removed.
+// The Archived field of the page is not sent, so updating an archived page leaves it archived,
+// unless the SetArchived option is given.
+// On success, the notion.Page will be the complete page from the Notion API.
+// On error, the notion.Page will not be changed.
+func (c *Client) UpdatePage(ctx context.Context, page *notion.Page, options ...UpdatePageOption) error {
+	opts := new(updatePageOptions)
+	for _, o := range options {
+		o(opts)
+	}
+
+	body := make(map[string]interface{})
+	if len(page.Properties) != 0 {
+		body["properties"] = &page.Properties
+	}
+	addIcons(body, &page.Icons)
+	if opts.archived != nil {
+		body["archived"] = *opts.archived
+	}
+
+	return c.updateObject(ctx, fmt.Sprintf("%s/v1/pages/%s", c.baseURL, page.ID.String()), body, page)
+}
+
+// ArchivePage archives the page with the given id in the Notion API, and returns the archived page.
+func (c *Client) ArchivePage(ctx context.Context, id string) (*notion.Page, error) {
+	return c.setArchived(ctx, id, true)
+}
+
+// RestorePage restores the archived page with the given id in the Notion API, and returns the restored page.
+func (c *Client) RestorePage(ctx context.Context, id string) (*notion.Page, error) {
+	return c.setArchived(ctx, id, false)
+}
+
+func (c *Client) setArchived(ctx context.Context, id string, archived bool) (*notion.Page, error) {
+	page := &notion.Page{}
+	err := c.updateObject(ctx, fmt.Sprintf("%s/v1/pages/%s", c.baseURL, id), map[string]interface{}{"archived": archived}, page)
+	if err != nil {
+		page = nil
+	}
+
+	return page, err
+}
+
+// ArchivePageTree archives the page with the given id in the Notion API, along with its child pages and their child pages.
+// Child pages are found anywhere in the blocks of a page, like in toggles and columns. Each page is archived after its
+// child pages, so that a page whose child pages failed to be archived is not archived either.
+// The pages of child databases are not archived.
+// The ids of the archived pages are returned, in the order they were archived, even if an error is returned.
+func (c *Client) ArchivePageTree(ctx context.Context, id string) ([]string, error) {
+	var archived []string
+	var archive func(id string) error
+	archive = func(id string) error {
+		blocks, err := c.GetBlockTree(ctx, id, -1, 0)
+		if err != nil {
+			return err
+		}
+		for _, childID := range childPageIDs(blocks) {
+			if err := archive(childID); err != nil {
+				return err
+			}
+		}
+
+		page, err := c.ArchivePage(ctx, id)
+		if err != nil {
+			return err
+		}
+		archived = append(archived, page.ID.String())
+		return nil
+	}
+
+	err := archive(id)
+	return archived, err
+}
+
+// childPageIDs returns the ids of the child pages in the tree of blocks, in order.
+func childPageIDs(blocks []*notion.Block) []string {
+	var ids []string
+	for _, b := range blocks {
+		if b.Type == notion.BlockTypeEnumChildPage {
+			ids = append(ids, b.ID.String())
+		} else {
+			ids = append(ids, childPageIDs(b.Children)...)
+		}
+	}
+	return ids
 }
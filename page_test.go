@@ -0,0 +1,145 @@
+package gotion_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/thedadams/gotion"
+	"github.com/thedadams/gotion/gotiontest"
+	"github.com/thedadams/gotion/notion"
+)
+
+func TestUpdatePageArchived(t *testing.T) {
+	s := gotiontest.NewServer()
+	defer s.Close()
+	ctx := context.Background()
+	c := s.NewClient()
+
+	added, err := s.AddPage(&notion.Page{Parent: notion.Parent{Type: notion.ParentTypeEnumWorkspace}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := added.ID.String()
+	if _, err := c.ArchivePage(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		options []gotion.UpdatePageOption
+		want    bool
+	}{
+		{name: "not set", want: true},
+		{name: "restore", options: []gotion.UpdatePageOption{gotion.SetArchived(false)}, want: false},
+		{name: "still not set", want: false},
+		{name: "archive", options: []gotion.UpdatePageOption{gotion.SetArchived(true)}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The page that is updated is not archived, which must not restore the archived page.
+			page := &notion.Page{Icons: notion.Icons{Icon: notion.NewEmojiIcon("🚀")}}
+			page.ID = added.ID
+			if err := c.UpdatePage(ctx, page, tt.options...); err != nil {
+				t.Fatal(err)
+			}
+			if page.Archived != tt.want {
+				t.Errorf("got archived %v from the update, want %v", page.Archived, tt.want)
+			}
+
+			got, err := c.GetPage(ctx, id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Archived != tt.want {
+				t.Errorf("got archived %v, want %v", got.Archived, tt.want)
+			}
+		})
+	}
+}
+
+// archiveFailingTransport responds to the request that archives one page with an error.
+type archiveFailingTransport struct {
+	failID string
+}
+
+func (ft *archiveFailingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodPatch || req.URL.Path != "/v1/pages/"+ft.failID {
+		return http.DefaultTransport.RoundTrip(req)
+	}
+	body := fmt.Sprintf(`{"object":"error","status":409,"code":%q,"message":"Conflict occurred while saving."}`, notion.ErrorCodeConflict)
+	return &http.Response{
+		StatusCode: http.StatusConflict,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+// addPageTree adds a root page with the child pages a and b, where a has the child page a1, and returns their ids by name.
+func addPageTree(t *testing.T, s *gotiontest.Server) map[string]string {
+	t.Helper()
+	root, err := s.AddPage(&notion.Page{Parent: notion.Parent{Type: notion.ParentTypeEnumWorkspace}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := map[string]string{"root": root.ID.String()}
+	for _, p := range []struct{ name, parent string }{{"a", "root"}, {"a1", "a"}, {"b", "root"}} {
+		page, err := s.NewClient().CreatePage(context.Background(), &notion.Page{Parent: notion.Parent{Type: notion.ParentTypeEnumPage, ID: ids[p.parent]}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[p.name] = page.ID.String()
+	}
+	return ids
+}
+
+func TestArchivePageTree(t *testing.T) {
+	tests := []struct {
+		name string
+		fail string
+		want []string
+	}{
+		{name: "children first", want: []string{"a1", "a", "b", "root"}},
+		{name: "archive fails", fail: "b", want: []string{"a1", "a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := gotiontest.NewServer()
+			defer s.Close()
+			ctx := context.Background()
+			ids := addPageTree(t, s)
+
+			var options []gotion.Option
+			if tt.fail != "" {
+				options = append(options, gotion.WithTransport(&archiveFailingTransport{failID: ids[tt.fail]}))
+			}
+			archived, err := s.NewClient(options...).ArchivePageTree(ctx, ids["root"])
+			if (err != nil) != (tt.fail != "") {
+				t.Fatalf("got error %v, want one only when an archive fails", err)
+			}
+			want := make([]string, 0, len(tt.want))
+			for _, name := range tt.want {
+				want = append(want, ids[name])
+			}
+			if !reflect.DeepEqual(archived, want) {
+				t.Errorf("got archived %v, want %v", archived, want)
+			}
+
+			// The root is archived last, so that it is not archived when one of its child pages failed to be.
+			root, err := s.NewClient().GetPage(ctx, ids["root"])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if root.Archived != (tt.fail == "") {
+				t.Errorf("got the root archived %v, want it archived only when its child pages are", root.Archived)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package gotion
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// defaultBurst is the number of requests that can be sent at once before the default rate of one request per second applies.
+	defaultBurst = 3
+	// rampUpSteps is the number of successful requests it takes to ramp back up to the full rate after being rate limited.
+	rampUpSteps = 10
+	// minRateDivisor limits how far the rate is lowered after repeatedly being rate limited.
+	minRateDivisor = 16
+)
+
+// RateLimitStats are statistics about the rate limiting of a gotion client.
+type RateLimitStats struct {
+	// Requests is the number of requests sent to the Notion API, including retries.
+	Requests int64
+	// RateLimited is the number of responses from the Notion API that were rate limited.
+	RateLimited int64
+	// WaitTime is the total time requests have spent waiting, either on the rate limiter or for a Retry-After pause.
+	WaitTime time.Duration
+	// PauseTime is the total time the client has been paused by Retry-After responses.
+	PauseTime time.Duration
+	// Limit is the current rate of requests allowed, in requests per second.
+	Limit rate.Limit
+}
+
+// adaptiveLimiter wraps a rate.Limiter so that a rate limited response from the Notion API pauses every request
+// of the client for the time given by Retry-After. The rate is then lowered and ramped back up as requests succeed.
+type adaptiveLimiter struct {
+	mu          sync.Mutex
+	limiter     *rate.Limiter
+	base        rate.Limit
+	pausedUntil time.Time
+	stats       RateLimitStats
+}
+
+func newAdaptiveLimiter(l *rate.Limiter) *adaptiveLimiter {
+	return &adaptiveLimiter{limiter: l, base: l.Limit()}
+}
+
+// wait blocks until the client is no longer paused and the rate limiter allows another request.
+func (al *adaptiveLimiter) wait(ctx context.Context) error {
+	start := time.Now()
+	defer func() {
+		al.mu.Lock()
+		al.stats.Requests++
+		al.stats.WaitTime += time.Since(start)
+		al.mu.Unlock()
+	}()
+
+	// The pause is checked again after waiting on the rate limiter, because the client may have been paused in the meantime.
+	for {
+		if err := al.waitForPause(ctx); err != nil {
+			return err
+		}
+		if err := al.limiter.Wait(ctx); err != nil {
+			return err
+		}
+
+		al.mu.Lock()
+		paused := time.Now().Before(al.pausedUntil)
+		al.mu.Unlock()
+		if !paused {
+			return nil
+		}
+	}
+}
+
+// waitForPause blocks until the client is no longer paused.
+func (al *adaptiveLimiter) waitForPause(ctx context.Context) error {
+	for {
+		al.mu.Lock()
+		d := time.Until(al.pausedUntil)
+		al.mu.Unlock()
+		if d <= 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// pause stops all requests for the given duration and halves the rate at which requests are allowed.
+func (al *adaptiveLimiter) pause(d time.Duration) {
+	al.mu.Lock()
+	defer al.mu.Unlock()
+
+	al.stats.RateLimited++
+	until := time.Now().Add(d)
+	if until.After(al.pausedUntil) {
+		if al.pausedUntil.After(time.Now()) {
+			al.stats.PauseTime += until.Sub(al.pausedUntil)
+		} else {
+			al.stats.PauseTime += d
+		}
+		al.pausedUntil = until
+	}
+
+	if limit := al.limiter.Limit() / 2; limit >= al.base/minRateDivisor {
+		al.limiter.SetLimit(limit)
+	}
+}
+
+// succeed ramps the rate at which requests are allowed back up after the client has been rate limited.
+func (al *adaptiveLimiter) succeed() {
+	al.mu.Lock()
+	defer al.mu.Unlock()
+
+	if limit := al.limiter.Limit(); limit < al.base {
+		limit += al.base / rampUpSteps
+		if limit > al.base {
+			limit = al.base
+		}
+		al.limiter.SetLimit(limit)
+	}
+}
+
+func (al *adaptiveLimiter) getStats() RateLimitStats {
+	al.mu.Lock()
+	defer al.mu.Unlock()
+
+	stats := al.stats
+	stats.Limit = al.limiter.Limit()
+	return stats
+}
+
+// RateLimitStats returns statistics about the requests the client has sent and the time it has spent waiting on rate limits.
+func (c *Client) RateLimitStats() RateLimitStats {
+	return c.limiter.getStats()
+}
@@ -0,0 +1,96 @@
+package gotion_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/thedadams/gotion"
+	"github.com/thedadams/gotion/gotiontest"
+	"github.com/thedadams/gotion/notion"
+	"golang.org/x/time/rate"
+)
+
+func TestRateLimitPause(t *testing.T) {
+	tests := []struct {
+		name        string
+		rateLimited int
+		options     []gotion.Option
+		wantErr     bool
+		// wantLimit is the limit after the request, starting from 10 requests per second:
+		// it is halved for each rate limited response, then raised by a tenth of 10 for the successful one.
+		wantLimit rate.Limit
+	}{
+		{name: "once", rateLimited: 1, wantLimit: 6},
+		{name: "twice", rateLimited: 2, wantLimit: 3.5},
+		{name: "without retrying", rateLimited: 1, options: []gotion.Option{gotion.WithoutRetryOnHTTP429()}, wantErr: true, wantLimit: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := gotiontest.NewServer()
+			defer s.Close()
+			page, err := s.AddPage(&notion.Page{Parent: notion.Parent{Type: notion.ParentTypeEnumWorkspace}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			c := s.NewClient(append([]gotion.Option{gotion.WithRateLimiter(rate.NewLimiter(10, 10))}, tt.options...)...)
+
+			s.RateLimitNext(tt.rateLimited, time.Second)
+			start := time.Now()
+			_, err = c.GetPage(context.Background(), page.ID.String())
+			if tt.wantErr {
+				if !errors.Is(err, notion.ErrRateLimited) {
+					t.Fatalf("got error %v, want a rate limited error", err)
+				}
+				var apiErr *notion.APIError
+				if errors.As(err, &apiErr) && apiErr.RetryAfter != time.Second {
+					t.Errorf("got Retry-After %v, want %v", apiErr.RetryAfter, time.Second)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			// Every request of the client waits for the pause, including the next one when it isn't retried.
+			if _, err := c.GetPage(context.Background(), page.ID.String()); err != nil {
+				t.Fatal(err)
+			}
+			if elapsed, want := time.Since(start), time.Duration(tt.rateLimited)*time.Second; elapsed < want {
+				t.Errorf("the requests took %v, want at least %v", elapsed, want)
+			}
+
+			stats := c.RateLimitStats()
+			if stats.RateLimited != int64(tt.rateLimited) {
+				t.Errorf("got %d rate limited responses, want %d", stats.RateLimited, tt.rateLimited)
+			}
+			if want := time.Duration(tt.rateLimited) * time.Second; stats.PauseTime < want {
+				t.Errorf("got pause time %v, want at least %v", stats.PauseTime, want)
+			}
+			if want := tt.wantLimit + 1; stats.Limit != want {
+				t.Errorf("got limit %v after another request, want %v", stats.Limit, want)
+			}
+		})
+	}
+}
+
+func TestRateLimitRampUp(t *testing.T) {
+	s := gotiontest.NewServer()
+	defer s.Close()
+	page, err := s.AddPage(&notion.Page{Parent: notion.Parent{Type: notion.ParentTypeEnumWorkspace}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := s.NewClient(gotion.WithRateLimiter(rate.NewLimiter(10, 10)))
+
+	s.RateLimitNext(1, time.Second)
+	want := []rate.Limit{6, 7, 8, 9, 10, 10}
+	for i, limit := range want {
+		if _, err := c.GetPage(context.Background(), page.ID.String()); err != nil {
+			t.Fatal(err)
+		}
+		if got := c.RateLimitStats().Limit; got != limit {
+			t.Errorf("got limit %v after %d successful requests, want %v", got, i+1, limit)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+// Package richtext builds the rich text of blocks and properties in the Notion API, and has utilities to
+// get the plain text of rich text, split text over the length limit of the Notion API, and merge runs of rich text.
+//
+//	text := richtext.New().Text("See ").Link("the docs", docsURL).Text(", ").Bold("now").Build()
+package richtext
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/thedadams/gotion/notion"
+)
+
+// MaxTextLength is the maximum length of the content of a text object in the Notion API.
+// Like in Javascript, the length is the number of UTF-16 code units.
+const MaxTextLength = notion.MaxTextLength
+
+// A Builder builds rich text one run at a time.
+type Builder struct {
+	runs []*notion.RichText
+}
+
+// New returns an empty Builder.
+func New() *Builder {
+	return &Builder{}
+}
+
+// Text adds plain text.
+func (b *Builder) Text(s string) *Builder {
+	return b.Styled(s, notion.Annotations{})
+}
+
+// Bold adds bold text.
+func (b *Builder) Bold(s string) *Builder {
+	return b.Styled(s, notion.Annotations{Bold: true})
+}
+
+// Italic adds italic text.
+func (b *Builder) Italic(s string) *Builder {
+	return b.Styled(s, notion.Annotations{Italic: true})
+}
+
+// Strikethrough adds text that is struck through.
+func (b *Builder) Strikethrough(s string) *Builder {
+	return b.Styled(s, notion.Annotations{Strikethrough: true})
+}
+
+// Underline adds underlined text.
+func (b *Builder) Underline(s string) *Builder {
+	return b.Styled(s, notion.Annotations{Underline: true})
+}
+
+// Code adds inline code.
+func (b *Builder) Code(s string) *Builder {
+	return b.Styled(s, notion.Annotations{Code: true})
+}
+
+// Color adds text in the given color, or background color.
+func (b *Builder) Color(s string, color notion.AnnotationColorEnum) *Builder {
+	return b.Styled(s, notion.Annotations{Color: color})
+}
+
+// Styled adds text with the given annotations.
+func (b *Builder) Styled(s string, annotations notion.Annotations) *Builder {
+	return b.add(newText(s, annotations, nil))
+}
+
+// Link adds text that links to the given URL.
+func (b *Builder) Link(s string, u *url.URL) *Builder {
+	return b.StyledLink(s, u, notion.Annotations{})
+}
+
+// StyledLink adds text with the given annotations that links to the given URL.
+func (b *Builder) StyledLink(s string, u *url.URL, annotations notion.Annotations) *Builder {
+	return b.add(newText(s, annotations, u))
+}
+
+// Mention adds a mention of the user.
+func (b *Builder) Mention(u *notion.User) *Builder {
+	if u == nil {
+		return b
+	}
+	return b.add(&notion.RichText{
+		Type:      notion.RichTextTypeEnumMention,
+		PlainText: "@" + u.Name,
+		Mention:   &notion.Mention{Type: notion.MentionTypeEnumUser, User: u},
+	})
+}
+
+// MentionPage adds a mention of the page with the given id. The title is only used as the plain text of the mention.
+func (b *Builder) MentionPage(id notion.UUID4, title string) *Builder {
+	return b.add(&notion.RichText{
+		Type:      notion.RichTextTypeEnumMention,
+		PlainText: title,
+		Mention:   &notion.Mention{Type: notion.MentionTypeEnumPage, Ref: &id},
+	})
+}
+
+// MentionDatabase adds a mention of the database with the given id. The title is only used as the plain text of the mention.
+func (b *Builder) MentionDatabase(id notion.UUID4, title string) *Builder {
+	return b.add(&notion.RichText{
+		Type:      notion.RichTextTypeEnumMention,
+		PlainText: title,
+		Mention:   &notion.Mention{Type: notion.MentionTypeEnumDatabase, Ref: &id},
+	})
+}
+
+// MentionDate adds a mention of the date.
+func (b *Builder) MentionDate(d notion.Date) *Builder {
+	layout := "2006-01-02"
+	if d.HasTime {
+		layout = "2006-01-02T15:04:05Z07:00"
+	}
+	plain := d.Start.Format(layout)
+	if !d.End.IsZero() {
+		plain += " → " + d.End.Format(layout)
+	}
+	return b.add(&notion.RichText{
+		Type:      notion.RichTextTypeEnumMention,
+		PlainText: plain,
+		Mention:   &notion.Mention{Type: notion.MentionTypeEnumData, Date: &d},
+	})
+}
+
+// Equation adds an inline equation with the given KaTeX expression.
+func (b *Builder) Equation(expression string) *Builder {
+	return b.add(&notion.RichText{
+		Type:      notion.RichTextTypeEnumEquation,
+		PlainText: expression,
+		Equation:  &notion.Equation{Expression: expression},
+	})
+}
+
+func (b *Builder) add(rt *notion.RichText) *Builder {
+	b.runs = append(b.runs, rt)
+	return b
+}
+
+// Build returns the rich text. Adjacent runs with the same annotations and link are merged,
+// and text that is longer than MaxTextLength is split, so that the rich text is valid in the Notion API.
+func (b *Builder) Build() []*notion.RichText {
+	return Split(Merge(b.runs))
+}
+
+// String returns the plain text of the rich text built so far.
+func (b *Builder) String() string {
+	return PlainText(b.runs)
+}
+
+// PlainText returns the concatenated plain text of the rich text.
+func PlainText(rts []*notion.RichText) string {
+	var sb strings.Builder
+	for _, rt := range rts {
+		if rt != nil {
+			sb.WriteString(rt.PlainText)
+		}
+	}
+	return sb.String()
+}
+
+// FromPlainText returns the rich text for the plain text, split so that each run is at most MaxTextLength long.
+func FromPlainText(s string) []*notion.RichText {
+	return Split([]*notion.RichText{newText(s, notion.Annotations{}, nil)})
+}
+
+// Split returns the rich text with every text run that is longer than MaxTextLength split into several runs,
+// each with the same annotations and link. The rich text that is passed is not changed.
+func Split(rts []*notion.RichText) []*notion.RichText {
+	result := make([]*notion.RichText, 0, len(rts))
+	for _, rt := range rts {
+		if rt == nil || rt.Type != notion.RichTextTypeEnumText || rt.Text == nil || notion.TextLength(rt.Text.Content) <= MaxTextLength {
+			result = append(result, rt)
+			continue
+		}
+
+		for _, part := range notion.SplitText(rt.Text.Content) {
+			result = append(result, withContent(rt, part))
+		}
+	}
+	return result
+}
+
+// Merge returns the rich text with adjacent text runs merged if they have the same annotations and link,
+// as long as the merged run is no longer than MaxTextLength. The rich text that is passed is not changed.
+func Merge(rts []*notion.RichText) []*notion.RichText {
+	result := make([]*notion.RichText, 0, len(rts))
+	for _, rt := range rts {
+		if rt == nil || (rt.Type == notion.RichTextTypeEnumText && rt.PlainText == "" && (rt.Text == nil || rt.Text.Content == "")) {
+			continue
+		}
+		if len(result) != 0 {
+			if last := result[len(result)-1]; mergeable(last, rt) {
+				result[len(result)-1] = withContent(last, last.Text.Content+rt.Text.Content)
+				continue
+			}
+		}
+		result = append(result, rt)
+	}
+	return result
+}
+
+// mergeable returns true if the two runs are text with the same annotations and link, and fit in one run.
+func mergeable(a, b *notion.RichText) bool {
+	if a.Type != notion.RichTextTypeEnumText || b.Type != notion.RichTextTypeEnumText || a.Text == nil || b.Text == nil {
+		return false
+	}
+	if a.Annotations != b.Annotations || a.HRef != b.HRef || linkURL(a.Text) != linkURL(b.Text) {
+		return false
+	}
+	return notion.TextLength(a.Text.Content)+notion.TextLength(b.Text.Content) <= MaxTextLength
+}
+
+func linkURL(t *notion.Text) string {
+	if u := t.GetURL(); u != nil {
+		return u.String()
+	}
+	return ""
+}
+
+// withContent returns a copy of the text run with the given content.
+func withContent(rt *notion.RichText, content string) *notion.RichText {
+	c := *rt
+	c.PlainText = content
+	c.Text = &notion.Text{Content: content, Link: rt.Text.Link}
+	return &c
+}
+
+func newText(s string, annotations notion.Annotations, link *url.URL) *notion.RichText {
+	rt := &notion.RichText{
+		Type:        notion.RichTextTypeEnumText,
+		Annotations: annotations,
+		PlainText:   s,
+		Text:        &notion.Text{Content: s, Link: notion.NewLink(link)},
+	}
+	if link != nil {
+		rt.HRef = link.String()
+	}
+	return rt
+}
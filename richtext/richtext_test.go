@@ -0,0 +1,112 @@
+package richtext_test
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/thedadams/gotion/notion"
+	"github.com/thedadams/gotion/richtext"
+)
+
+// utf16Length returns the length of the string in the Notion API.
+func utf16Length(s string) int {
+	return len(utf16.Encode([]rune(s)))
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		wantParts int
+	}{
+		{name: "empty", text: "", wantParts: 0},
+		{name: "short", text: "hello", wantParts: 1},
+		{name: "exactly the limit", text: strings.Repeat("a", richtext.MaxTextLength), wantParts: 1},
+		{name: "one over the limit", text: strings.Repeat("a", richtext.MaxTextLength+1), wantParts: 2},
+		{name: "several times the limit", text: strings.Repeat("a", 3*richtext.MaxTextLength+5), wantParts: 4},
+		// Each emoji is two UTF-16 code units, so 1000 of them fill a run.
+		{name: "surrogate pairs at the limit", text: strings.Repeat("😀", richtext.MaxTextLength/2), wantParts: 1},
+		{name: "surrogate pairs over the limit", text: strings.Repeat("😀", richtext.MaxTextLength/2+1), wantParts: 2},
+		// A surrogate pair that would straddle the limit is not split.
+		{name: "surrogate pair across the limit", text: strings.Repeat("a", richtext.MaxTextLength-1) + "😀", wantParts: 2},
+		{name: "words", text: strings.Repeat("word ", richtext.MaxTextLength/5+1), wantParts: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bold := notion.Annotations{Bold: true}
+			rts := richtext.Split(richtext.New().Styled(tt.text, bold).Build())
+			if len(rts) != tt.wantParts {
+				t.Fatalf("got %d runs, want %d", len(rts), tt.wantParts)
+			}
+
+			var sb strings.Builder
+			for i, rt := range rts {
+				if n := utf16Length(rt.Text.Content); n > richtext.MaxTextLength {
+					t.Errorf("run %d is %d UTF-16 code units long", i, n)
+				}
+				if rt.PlainText != rt.Text.Content {
+					t.Errorf("run %d has plain text %q and content %q", i, rt.PlainText, rt.Text.Content)
+				}
+				if rt.Annotations != bold {
+					t.Errorf("run %d lost its annotations", i)
+				}
+				sb.WriteString(rt.Text.Content)
+			}
+			if sb.String() != tt.text {
+				t.Error("the runs don't add up to the text")
+			}
+
+			merged := richtext.Merge(rts)
+			if got := richtext.PlainText(merged); got != tt.text {
+				t.Error("the merged runs don't add up to the text")
+			}
+			if len(merged) > tt.wantParts {
+				t.Errorf("got %d merged runs, want at most %d", len(merged), tt.wantParts)
+			}
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	long := strings.Repeat("a", richtext.MaxTextLength-1)
+
+	tests := []struct {
+		name string
+		rts  []*notion.RichText
+		want []string
+	}{
+		{name: "same style", rts: richtext.New().Text("a").Text("b").Text("c").Build(), want: []string{"abc"}},
+		{name: "different styles", rts: richtext.New().Text("a").Bold("b").Bold("c").Build(), want: []string{"a", "bc"}},
+		{name: "empty runs", rts: richtext.New().Text("a").Text("").Text("b").Build(), want: []string{"ab"}},
+		{name: "at the limit", rts: richtext.New().Text(long).Text("b").Build(), want: []string{long + "b"}},
+		{name: "over the limit", rts: richtext.New().Text(long).Text("bc").Build(), want: []string{long, "bc"}},
+		{name: "over the limit in UTF-16", rts: richtext.New().Text(long).Text("😀").Build(), want: []string{long, "😀"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged := richtext.Merge(tt.rts)
+			var got []string
+			for _, rt := range merged {
+				got = append(got, rt.Text.Content)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") || len(got) != len(tt.want) {
+				t.Errorf("got runs %q, want %q", shorten(got), shorten(tt.want))
+			}
+		})
+	}
+}
+
+// shorten shortens long runs, to keep failures readable.
+func shorten(runs []string) []string {
+	short := make([]string, 0, len(runs))
+	for _, r := range runs {
+		if len(r) > 10 {
+			r = r[:4] + "..." + r[len(r)-4:]
+		}
+		short = append(short, r)
+	}
+	return short
+}
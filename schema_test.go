@@ -0,0 +1,94 @@
+package gotion_test
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/thedadams/gotion"
+	"github.com/thedadams/gotion/gotiontest"
+	"github.com/thedadams/gotion/notion"
+)
+
+func TestApplySchema(t *testing.T) {
+	source := notion.DatabaseProperties{
+		{Name: "Name", Type: notion.DatabasePropertyTypeEnumTitle},
+		{Name: "Points", Type: notion.DatabasePropertyTypeEnumNumber},
+		{Name: "Due", Type: notion.DatabasePropertyTypeEnumDate},
+	}
+
+	tests := []struct {
+		name    string
+		options []gotion.ApplyOption
+		wantErr string
+		want    []string
+	}{
+		{name: "removal and retype", wantErr: `removes the properties "Notes"`},
+		{name: "retype", options: []gotion.ApplyOption{gotion.AllowRemovals()}, wantErr: `retypes the properties "Points" from rich_text to number`},
+		{name: "removal", options: []gotion.ApplyOption{gotion.AllowRetypes()}, wantErr: `removes the properties "Notes"`},
+		{
+			name:    "allowed",
+			options: []gotion.ApplyOption{gotion.AllowRemovals(), gotion.AllowRetypes()},
+			want:    []string{"Due date", "Name title", "Points number"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := gotiontest.NewServer()
+			defer s.Close()
+			db, err := s.AddDatabase(&notion.Database{Properties: notion.DatabaseProperties{
+				{Name: "Name", Type: notion.DatabasePropertyTypeEnumTitle},
+				{Name: "Points", Type: notion.DatabasePropertyTypeEnumRichText},
+				{Name: "Notes", Type: notion.DatabasePropertyTypeEnumRichText},
+			}})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := context.Background()
+			c := s.NewClient()
+			diff := notion.DiffSchema(db.Properties, source)
+			for _, dryRun := range []bool{true, false} {
+				before := len(s.Requests())
+				updates, err := c.ApplySchema(ctx, db.ID.String(), diff, dryRun, tt.options...)
+				if tt.wantErr != "" {
+					if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+						t.Errorf("got error %v, want %q", err, tt.wantErr)
+					}
+				} else if err != nil {
+					t.Fatal(err)
+				}
+				if len(updates) != 2 {
+					t.Errorf("got %d updates, want 2 even with an error", len(updates))
+				}
+
+				// Each update is one request, and none are made for a dry run or a diff that is not allowed.
+				wantSent := len(updates)
+				if dryRun || tt.wantErr != "" {
+					wantSent = 0
+				}
+				if sent := len(s.Requests()) - before; sent != wantSent {
+					t.Errorf("got %d requests, want %d", sent, wantSent)
+				}
+			}
+			if tt.wantErr != "" {
+				return
+			}
+
+			db, err = c.GetDatabase(ctx, db.ID.String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, p := range db.Properties {
+				got = append(got, p.Name+" "+string(p.Type))
+			}
+			sort.Strings(got)
+			if strings.Join(got, ", ") != strings.Join(tt.want, ", ") {
+				t.Errorf("got properties %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -15,7 +15,7 @@ type SearchQuery struct {
 	Query      string         `json:"query"`
 	Filter     *notion.Filter `json:"filter,omitempty"`
 	Sort       *notion.Sort   `json:"sort,omitempty"`
-	Cursor     *string        `json:"cursor,omitempty"`
+	Cursor     *string        `json:"start_cursor,omitempty"`
 	MaxResults *int           `json:"page_size,omitempty"`
 }
 
@@ -80,6 +80,9 @@ func (sq *SearchQuery) getCursor() *string {
 }
 
 func (sq *SearchQuery) getMaxResults() int {
+	if sq.MaxResults == nil {
+		return -1
+	}
 	return *sq.MaxResults
 }
 
@@ -89,7 +92,7 @@ func (sq *SearchQuery) getMaxResults() int {
 // filter -- can only filter by object type: pages or databases.
 func (c *Client) Search(ctx context.Context, query *SearchQuery) (*SearchResults, error) {
 	results := SearchResults{}
-	if err := c.queryForList(ctx, fmt.Sprintf("%s/v1/search", apiBaseURL), query, &results); err != nil {
+	if err := c.queryForList(ctx, fmt.Sprintf("%s/v1/search", c.baseURL), query, &results); err != nil {
 		return nil, err
 	}
 	return &results, nil
@@ -11,7 +11,7 @@ import (
 // GetUser gets a user with the given id from the Notion API.
 func (c *Client) GetUser(ctx context.Context, id string) (*notion.User, error) {
 	u := &notion.User{}
-	err := c.makeRequest(ctx, http.MethodGet, fmt.Sprintf("%s/v1/users/%s", apiBaseURL, id), nil, u)
+	err := c.makeRequest(ctx, http.MethodGet, fmt.Sprintf("%s/v1/users/%s", c.baseURL, id), nil, u)
 	if err != nil {
 		u = nil
 	}
@@ -23,7 +23,7 @@ func (c *Client) GetUser(ctx context.Context, id string) (*notion.User, error) {
 // If `maxResults < 0`, then this will get all users.
 func (c *Client) GetUsers(ctx context.Context, cursor *string, maxResults int) ([]*notion.User, error) {
 	var results notion.Users
-	if err := c.getList(ctx, fmt.Sprintf("%s/v1/users", apiBaseURL), cursor, maxResults, &results); err != nil {
+	if err := c.getList(ctx, fmt.Sprintf("%s/v1/users", c.baseURL), cursor, maxResults, &results); err != nil {
 		return nil, err
 	}
 	return results, nil
@@ -0,0 +1,104 @@
+package watch
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// State is what a Watcher knows about the pages it watches, which is persisted in a Store between polls.
+type State struct {
+	// HighWaterMark is the last edited time of the newest page that has been seen. It is zero before the first poll.
+	HighWaterMark time.Time `json:"high_water_mark"`
+	// Pages are the pages that have been seen, by id.
+	Pages map[string]*PageState `json:"pages"`
+}
+
+// PageState is what a Watcher knows about a page: enough to know what changed when it is edited.
+type PageState struct {
+	LastEditedTime time.Time `json:"last_edited_time"`
+	// Properties are the JSON of the properties of the page, as in the Notion API, by name.
+	Properties map[string]json.RawMessage `json:"properties"`
+}
+
+// A Store persists the State of a Watcher, so that a Watcher that is restarted continues where it left off.
+type Store interface {
+	// Load returns the saved state, or nil if there is none.
+	Load() (*State, error)
+	Save(*State) error
+}
+
+// NewMemoryStore returns a Store that keeps the state in memory, which is lost when the program exits.
+func NewMemoryStore() Store {
+	return new(memoryStore)
+}
+
+type memoryStore struct {
+	mu    sync.Mutex
+	state []byte
+}
+
+func (ms *memoryStore) Load() (*State, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if ms.state == nil {
+		return nil, nil
+	}
+	s := new(State)
+	return s, json.Unmarshal(ms.state, s)
+}
+
+func (ms *memoryStore) Save(s *State) error {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.state = b
+	return nil
+}
+
+// NewFileStore returns a Store that keeps the state as JSON in the file at the given path.
+// The file is replaced atomically, so that a program that exits while saving doesn't lose the state.
+func NewFileStore(path string) Store {
+	return fileStore(path)
+}
+
+type fileStore string
+
+func (fs fileStore) Load() (*State, error) {
+	b, err := ioutil.ReadFile(string(fs))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	s := new(State)
+	return s, json.Unmarshal(b, s)
+}
+
+func (fs fileStore) Save(s *State) error {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(string(fs)), filepath.Base(string(fs))+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), string(fs))
+}
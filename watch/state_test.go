@@ -0,0 +1,79 @@
+package watch_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/thedadams/gotion/gotiontest"
+	"github.com/thedadams/gotion/watch"
+)
+
+func TestFileStore(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "tasks.watch")
+	store := watch.NewFileStore(path)
+
+	if state, err := store.Load(); err != nil || state != nil {
+		t.Fatalf("got %+v and error %v before the first save, want no state", state, err)
+	}
+
+	edited := time.Date(2022, 1, 2, 3, 4, 0, 0, time.UTC)
+	for _, id := range []string{"first", "second"} {
+		state := &watch.State{HighWaterMark: edited, Pages: map[string]*watch.PageState{
+			id: {LastEditedTime: edited, Properties: map[string]json.RawMessage{"Points": json.RawMessage(`{"type":"number","number":1}`)}},
+		}}
+		if err := store.Save(state); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The file is replaced by a rename, so that only the file is left, without temporary files.
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Name() != "tasks.watch" {
+		t.Errorf("got %d files in the directory, want only the state file", len(files))
+	}
+
+	// Another store of the same file loads the last state that was saved.
+	state, err := watch.NewFileStore(path).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !state.HighWaterMark.Equal(edited) || len(state.Pages) != 1 || state.Pages["second"] == nil {
+		t.Fatalf("got %+v, want the second state", state)
+	}
+	if got := string(state.Pages["second"].Properties["Points"]); got != `{"type":"number","number":1}` {
+		t.Errorf("got property %s, want it as it was saved", got)
+	}
+
+	if err := ioutil.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Load(); err == nil {
+		t.Error("got no error for a file that is not JSON")
+	}
+}
+
+func TestWatcherReloadsState(t *testing.T) {
+	s := gotiontest.NewServer()
+	defer s.Close()
+	databaseID := addTasks(t, s)
+	addTask(t, s, databaseID, "Launch", 1)
+
+	path := filepath.Join(t.TempDir(), "tasks.watch")
+	if events := poll(t, watch.NewDatabaseWatcher(s.NewClient(), databaseID, watch.WithStore(watch.NewFileStore(path)))); len(events) != 0 {
+		t.Fatalf("got %+v from the first poll, want no events", events)
+	}
+
+	// A restarted watcher continues from the saved state, so the page it has seen is not created again.
+	created := addTask(t, s, databaseID, "Review", 2).ID.String()
+	events := poll(t, watch.NewDatabaseWatcher(s.NewClient(), databaseID, watch.WithStore(watch.NewFileStore(path))))
+	if len(events) != 1 || events[0].Type != watch.EventTypeEnumPageCreated || events[0].Page.ID.String() != created {
+		t.Errorf("got %+v, want only the new page to be created", events)
+	}
+}
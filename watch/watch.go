@@ -0,0 +1,391 @@
+// Package watch polls a database, or the search results, of the Notion API for changes to pages,
+// since the Notion API has no webhooks.
+//
+// A Watcher lists the pages that were edited since the last poll, newest first, and compares them to what it saw before.
+// It emits an Event when a page is created, for every property of a page that changed, and when a page is archived.
+// Because the Notion API doesn't return archived pages when querying or searching, archived pages are found
+// by listing all pages every few polls.
+//
+//	w := watch.NewDatabaseWatcher(client, databaseID, watch.WithStore(watch.NewFileStore("tasks.watch")))
+//	err := w.Run(ctx, func(e watch.Event) error {
+//		log.Println(e.Type, e.Page.ID.String(), e.Property)
+//		return nil
+//	})
+package watch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thedadams/gotion"
+	"github.com/thedadams/gotion/filter"
+	"github.com/thedadams/gotion/notion"
+)
+
+// These are the types of events.
+const (
+	EventTypeEnumPageCreated     = "page_created"
+	EventTypeEnumPropertyChanged = "property_changed"
+	EventTypeEnumPageArchived    = "page_archived"
+)
+
+// DefaultInterval is the default time between polls. The Notion API rounds the last edited times of pages to the minute,
+// so polling more often finds the same pages again, and only costs requests.
+const DefaultInterval = time.Minute
+
+// DefaultFullScanEvery is the default number of polls between listing all pages, to find the ones that were archived.
+const DefaultFullScanEvery = 10
+
+// EventTypeEnum is the type of an Event.
+type EventTypeEnum string
+
+// An Event is a change to a page.
+type Event struct {
+	Type EventTypeEnum
+	// Page is the page as it is now. For an archived page that could not be found, only the ID is set.
+	Page *notion.Page
+	// Property is the name of the property that changed. Old is the property before the change, and New after.
+	// Old is nil for a property that was added to the page, and New is nil for a property that was removed.
+	Property string
+	Old      *notion.PageProperty
+	New      *notion.PageProperty
+}
+
+// An Option is a way of customizing a Watcher.
+type Option func(*Watcher)
+
+// WithInterval sets the time between polls for Run and Events. The default is DefaultInterval.
+func WithInterval(d time.Duration) Option {
+	return func(w *Watcher) {
+		if d > 0 {
+			w.interval = d
+		}
+	}
+}
+
+// WithStore persists the state of the Watcher in the store after every poll. By default, the state is only kept in memory.
+func WithStore(s Store) Option {
+	return func(w *Watcher) {
+		if s != nil {
+			w.store = s
+		}
+	}
+}
+
+// WithFullScanEvery sets the number of polls between listing all pages, to find the ones that were archived.
+// A number less than one turns this off, and archived pages are never found. The default is DefaultFullScanEvery.
+func WithFullScanEvery(n int) Option {
+	return func(w *Watcher) {
+		w.fullScanEvery = n
+	}
+}
+
+// WithInitialEvents emits a page_created event for every page that is found by the first poll.
+// By default, the first poll only records the pages, so that only changes after the Watcher starts are emitted.
+func WithInitialEvents() Option {
+	return func(w *Watcher) {
+		w.initialEvents = true
+	}
+}
+
+// A Watcher polls the Notion API for changes to pages.
+type Watcher struct {
+	client        *gotion.Client
+	list          func(ctx context.Context, since time.Time, visit func(*notion.Page)) error
+	interval      time.Duration
+	store         Store
+	fullScanEvery int
+	initialEvents bool
+
+	state *State
+	polls int
+	err   error
+}
+
+// NewDatabaseWatcher returns a Watcher of the pages in the database with the given id.
+func NewDatabaseWatcher(c *gotion.Client, databaseID string, options ...Option) *Watcher {
+	w := newWatcher(c, options)
+	w.list = func(ctx context.Context, since time.Time, visit func(*notion.Page)) error {
+		sorts, err := notion.NewSortBuilder().
+			Timestamp(notion.SortTimestampEnumLastEditedTime, notion.SortDirectionEnumDescending).
+			Build()
+		if err != nil {
+			return err
+		}
+
+		it := c.IterateDatabase(databaseID, &gotion.DBQuery{Sorts: sorts})
+		for it.Next(ctx) && !it.Value().LastEditedTime.Before(since) {
+			visit(it.Value())
+		}
+		return it.Err()
+	}
+	return w
+}
+
+// NewSearchWatcher returns a Watcher of the pages whose titles match the query when searching the Notion API.
+// An empty query watches every page that the integration can see.
+func NewSearchWatcher(c *gotion.Client, query string, options ...Option) *Watcher {
+	w := newWatcher(c, options)
+	w.list = func(ctx context.Context, since time.Time, visit func(*notion.Page)) error {
+		sort, err := notion.NewTimestampSort(notion.SortTimestampEnumLastEditedTime, notion.SortDirectionEnumDescending)
+		if err != nil {
+			return err
+		}
+
+		it := c.IterateSearch(&gotion.SearchQuery{Query: query, Filter: filter.Object(notion.FilterObjectConditionEnumPage), Sort: sort})
+		for it.Next(ctx) {
+			page := it.Value().Page
+			if page == nil {
+				continue
+			}
+			if page.LastEditedTime.Before(since) {
+				break
+			}
+			visit(page)
+		}
+		return it.Err()
+	}
+	return w
+}
+
+func newWatcher(c *gotion.Client, options []Option) *Watcher {
+	w := &Watcher{
+		client:        c,
+		interval:      DefaultInterval,
+		store:         NewMemoryStore(),
+		fullScanEvery: DefaultFullScanEvery,
+	}
+	for _, opt := range options {
+		opt(w)
+	}
+	return w
+}
+
+// Poll polls the Notion API once, saves the state, and returns the events, ordered by the last edited time of the pages.
+// Since the state is saved before the events are handled, an event can be lost if the program exits while handling it.
+// Run saves the state after the events are handled instead.
+func (w *Watcher) Poll(ctx context.Context) ([]Event, error) {
+	events, next, err := w.poll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return events, w.commit(next)
+}
+
+// Run polls the Notion API until the context is done, or an error occurs, and calls handle with each event.
+// The state is saved after the events of a poll have been handled. If handle returns an error, then Run returns it,
+// and the events of that poll are emitted again by the next poll.
+func (w *Watcher) Run(ctx context.Context, handle func(Event) error) error {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		events, next, err := w.poll(ctx)
+		if err != nil {
+			return err
+		}
+		for _, e := range events {
+			if err := handle(e); err != nil {
+				return err
+			}
+		}
+		if err := w.commit(next); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Events runs the Watcher in a goroutine and returns a channel of its events, which is closed when Run returns.
+// After the channel is closed, Err returns the error that Run returned.
+func (w *Watcher) Events(ctx context.Context) <-chan Event {
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+		w.err = w.Run(ctx, func(e Event) error {
+			select {
+			case events <- e:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+	return events
+}
+
+// Err returns the error that ended the Watcher, after the channel returned by Events is closed.
+func (w *Watcher) Err() error {
+	return w.err
+}
+
+// poll returns the events since the current state, and the state after them, without saving it.
+func (w *Watcher) poll(ctx context.Context) ([]Event, *State, error) {
+	if w.state == nil {
+		state, err := w.store.Load()
+		if err != nil {
+			return nil, nil, err
+		}
+		if state == nil {
+			state = new(State)
+		}
+		if state.Pages == nil {
+			state.Pages = make(map[string]*PageState)
+		}
+		w.state = state
+	}
+
+	current := w.state
+	first := current.HighWaterMark.IsZero() && len(current.Pages) == 0
+	full := first || (w.fullScanEvery > 0 && w.polls%w.fullScanEvery == 0)
+	since := current.HighWaterMark
+	if full {
+		since = time.Time{}
+	}
+
+	next := &State{HighWaterMark: current.HighWaterMark, Pages: make(map[string]*PageState, len(current.Pages))}
+	for id, ps := range current.Pages {
+		next.Pages[id] = ps
+	}
+
+	var events []Event
+	seen := make(map[string]bool)
+	err := w.list(ctx, since, func(page *notion.Page) {
+		id := page.ID.String()
+		seen[id] = true
+		if page.LastEditedTime.After(next.HighWaterMark) {
+			next.HighWaterMark = page.LastEditedTime
+		}
+
+		ps := newPageState(page)
+		if old := current.Pages[id]; old != nil {
+			events = append(events, propertyEvents(page, old, ps)...)
+		} else if !first || w.initialEvents {
+			events = append(events, Event{Type: EventTypeEnumPageCreated, Page: page})
+		}
+		next.Pages[id] = ps
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if full && !first {
+		archived, err := w.archived(ctx, current, seen)
+		if err != nil {
+			return nil, nil, err
+		}
+		for id := range current.Pages {
+			if !seen[id] {
+				delete(next.Pages, id)
+			}
+		}
+		events = append(events, archived...)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Page.LastEditedTime.Before(events[j].Page.LastEditedTime)
+	})
+	return events, next, nil
+}
+
+// archived returns the events for the pages in the state that were not seen by a full scan, and are archived.
+// A page that is no longer found is treated as archived. Pages that exist, but no longer match, are dropped without an event.
+func (w *Watcher) archived(ctx context.Context, current *State, seen map[string]bool) ([]Event, error) {
+	ids := make([]string, 0)
+	for id := range current.Pages {
+		if !seen[id] {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	var events []Event
+	for _, id := range ids {
+		page, err := w.client.GetPage(ctx, id)
+		switch {
+		case notion.IsNotFound(err):
+			page = &notion.Page{Archived: true}
+			if u, err := uuid.Parse(id); err == nil {
+				page.ID = notion.UUID4(u)
+			}
+		case err != nil:
+			return nil, err
+		}
+		if page.Archived {
+			events = append(events, Event{Type: EventTypeEnumPageArchived, Page: page})
+		}
+	}
+	return events, nil
+}
+
+// commit saves the state after a poll.
+func (w *Watcher) commit(next *State) error {
+	if err := w.store.Save(next); err != nil {
+		return err
+	}
+	w.state = next
+	w.polls++
+	return nil
+}
+
+func newPageState(page *notion.Page) *PageState {
+	ps := &PageState{LastEditedTime: page.LastEditedTime, Properties: make(map[string]json.RawMessage, len(page.Properties))}
+	for _, prop := range page.Properties {
+		if prop == nil {
+			continue
+		}
+		if b, err := json.Marshal(prop); err == nil {
+			ps.Properties[prop.Name] = b
+		}
+	}
+	return ps
+}
+
+// propertyEvents returns an event for each property that is different in the page states, by name.
+func propertyEvents(page *notion.Page, old, current *PageState) []Event {
+	names := make([]string, 0, len(current.Properties))
+	for name, raw := range current.Properties {
+		if !bytes.Equal(raw, old.Properties[name]) {
+			names = append(names, name)
+		}
+	}
+	for name := range old.Properties {
+		if _, ok := current.Properties[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	events := make([]Event, 0, len(names))
+	for _, name := range names {
+		events = append(events, Event{
+			Type:     EventTypeEnumPropertyChanged,
+			Page:     page,
+			Property: name,
+			Old:      decodeProperty(name, old.Properties[name]),
+			New:      page.Get(name),
+		})
+	}
+	return events
+}
+
+func decodeProperty(name string, raw json.RawMessage) *notion.PageProperty {
+	if raw == nil {
+		return nil
+	}
+	prop := new(notion.PageProperty)
+	if err := json.Unmarshal(raw, prop); err != nil {
+		return nil
+	}
+	prop.Name = name
+	return prop
+}
@@ -0,0 +1,159 @@
+package watch_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/thedadams/gotion/gotiontest"
+	"github.com/thedadams/gotion/notion"
+	"github.com/thedadams/gotion/watch"
+)
+
+// addTasks adds a database of tasks to the server, and returns its id.
+func addTasks(t *testing.T, s *gotiontest.Server) string {
+	t.Helper()
+	db, err := s.AddDatabase(&notion.Database{Properties: notion.DatabaseProperties{
+		{Name: "Task", Type: notion.DatabasePropertyTypeEnumTitle},
+		{Name: "Points", Type: notion.DatabasePropertyTypeEnumNumber},
+		{Name: "Notes", Type: notion.DatabasePropertyTypeEnumRichText},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db.ID.String()
+}
+
+// addTask adds a task with the title and points to the database.
+func addTask(t *testing.T, s *gotiontest.Server, databaseID, title string, points float64) *notion.Page {
+	t.Helper()
+	props := notion.PageProperties{{Name: "Task", Type: notion.DatabasePropertyTypeEnumTitle}}
+	for _, err := range []error{props.SetTitle(title), props.SetNumber("Points", points)} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	page, err := s.AddPage(&notion.Page{Parent: notion.Parent{Type: notion.ParentTypeEnumDatabase, ID: databaseID}, Properties: props})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return page
+}
+
+// poll polls the watcher once and returns the events.
+func poll(t *testing.T, w *watch.Watcher) []watch.Event {
+	t.Helper()
+	events, err := w.Poll(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return events
+}
+
+func TestPollPropertyChanged(t *testing.T) {
+	s := gotiontest.NewServer()
+	defer s.Close()
+	databaseID := addTasks(t, s)
+	page := addTask(t, s, databaseID, "Launch", 1)
+
+	ctx := context.Background()
+	c := s.NewClient()
+	w := watch.NewDatabaseWatcher(c, databaseID, watch.WithFullScanEvery(0))
+	if events := poll(t, w); len(events) != 0 {
+		t.Fatalf("got %d events from the first poll, want none", len(events))
+	}
+
+	page.Properties = notion.PageProperties{}
+	for _, err := range []error{page.Properties.SetNumber("Points", 2), page.Properties.SetText("Notes", "Soon")} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := c.UpdatePageProperties(ctx, page); err != nil {
+		t.Fatal(err)
+	}
+	created := addTask(t, s, databaseID, "Review", 3).ID.String()
+
+	events := poll(t, w)
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want 2 changed properties and a new page: %+v", len(events), events)
+	}
+
+	// The properties are in order of name, and the new page, which was edited last, comes after them.
+	notes, points := events[0], events[1]
+	if notes.Type != watch.EventTypeEnumPropertyChanged || notes.Property != "Notes" || notes.Old != nil || notes.New == nil {
+		t.Errorf("got %+v, want the added Notes property", notes)
+	}
+	if points.Type != watch.EventTypeEnumPropertyChanged || points.Property != "Points" || points.Page.ID != page.ID {
+		t.Errorf("got %+v, want the changed Points property", points)
+	} else if points.Old.Number == nil || *points.Old.Number != 1 || points.New.Number == nil || *points.New.Number != 2 {
+		t.Errorf("got points %v and %v, want 1 and 2", points.Old.Number, points.New.Number)
+	}
+	if e := events[2]; e.Type != watch.EventTypeEnumPageCreated || e.Page.ID.String() != created {
+		t.Errorf("got %+v, want the new page to be created", e)
+	}
+
+	// Nothing changed since the last poll.
+	if events := poll(t, w); len(events) != 0 {
+		t.Errorf("got %+v, want no events", events)
+	}
+}
+
+func TestPollArchived(t *testing.T) {
+	s := gotiontest.NewServer()
+	defer s.Close()
+	databaseID := addTasks(t, s)
+	archived := addTask(t, s, databaseID, "Launch", 1).ID.String()
+	addTask(t, s, databaseID, "Review", 2)
+
+	ctx := context.Background()
+	c := s.NewClient()
+	w := watch.NewDatabaseWatcher(c, databaseID, watch.WithFullScanEvery(2))
+	poll(t, w)
+	if _, err := c.ArchivePage(ctx, archived); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the full scan of every second poll finds the archived page, once.
+	if events := poll(t, w); len(events) != 0 {
+		t.Errorf("got %+v, want no events before the full scan", events)
+	}
+	events := poll(t, w)
+	if len(events) != 1 || events[0].Type != watch.EventTypeEnumPageArchived || events[0].Page.ID.String() != archived {
+		t.Errorf("got %+v, want the page to be archived", events)
+	}
+	poll(t, w)
+	if events := poll(t, w); len(events) != 0 {
+		t.Errorf("got %+v, want no events from the next full scan", events)
+	}
+}
+
+func TestPollGone(t *testing.T) {
+	s := gotiontest.NewServer()
+	defer s.Close()
+	databaseID := addTasks(t, s)
+	addTask(t, s, databaseID, "Launch", 1)
+
+	// The state has a page that the integration can no longer see.
+	gone := "6f1c2d3e-4b5a-4978-8a6b-5c4d3e2f1a0b"
+	store := watch.NewMemoryStore()
+	if err := store.Save(&watch.State{Pages: map[string]*watch.PageState{gone: {}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The page that is gone has no last edited time, so its event comes first.
+	events := poll(t, watch.NewDatabaseWatcher(s.NewClient(), databaseID, watch.WithStore(store)))
+	if len(events) != 2 || events[0].Type != watch.EventTypeEnumPageArchived || events[1].Type != watch.EventTypeEnumPageCreated {
+		t.Fatalf("got %+v, want the page that is gone to be archived, and the other page to be created", events)
+	}
+	if gotID := events[0].Page.ID.String(); gotID != gone || !events[0].Page.Archived {
+		t.Errorf("got page %s archived %v, want %s archived", gotID, events[0].Page.Archived, gone)
+	}
+
+	state, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := state.Pages[gone]; ok || len(state.Pages) != 1 {
+		t.Errorf("got %d pages in the state, want only the page that exists", len(state.Pages))
+	}
+}